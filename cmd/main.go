@@ -6,20 +6,111 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/zde37/Hive/internal/config"
+	"github.com/zde37/Hive/internal/denylist"
+	"github.com/zde37/Hive/internal/encryption"
+	"github.com/zde37/Hive/internal/gc"
 	"github.com/zde37/Hive/internal/handler"
 	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/metadata"
+	"github.com/zde37/Hive/internal/pinhealth"
+	"github.com/zde37/Hive/internal/pinjob"
+	"github.com/zde37/Hive/internal/psa"
+	"github.com/zde37/Hive/internal/scanner"
+	"github.com/zde37/Hive/internal/search"
+	"github.com/zde37/Hive/internal/share"
+	"github.com/zde37/Hive/internal/store"
+	"github.com/zde37/Hive/internal/thumbnail"
+	"github.com/zde37/Hive/internal/versions"
 )
 
 func main() {
-	config := config.Load(os.Getenv("IPFS_RPC_ADDR"), os.Getenv("IPFS_WEB_UI_ADDR"),
+	var err error
+	cfg := config.Load(os.Getenv("IPFS_RPC_ADDR"), os.Getenv("IPFS_WEB_UI_ADDR"),
 		os.Getenv("IPFS_GATEWAY_ADDR"), os.Getenv("SERVER_ADDR"))
+	cfg.PUBSUB_TOPICS = config.ParseList(os.Getenv("PUBSUB_TOPICS"))
+	cfg.ADMIN_TOKEN = os.Getenv("ADMIN_TOKEN")
+	cfg.SHARE_SECRET = os.Getenv("SHARE_SECRET")
+	cfg.GATEWAY_SERVER_ADDR = os.Getenv("GATEWAY_SERVER_ADDR")
+	if cfg.USER_TOKENS, err = config.ParseUserTokens(os.Getenv("USER_TOKENS")); err != nil {
+		log.Fatalf("invalid USER_TOKENS: %v", err)
+	}
+	cfg.GC_SCHEDULE = os.Getenv("GC_SCHEDULE")
+	cfg.GC_CHECK_INTERVAL = time.Minute
+	if v := os.Getenv("GC_MIN_FREE"); v != "" {
+		if cfg.GC_MIN_FREE, err = humanize.ParseBytes(v); err != nil {
+			log.Fatalf("invalid GC_MIN_FREE: %v", err)
+		}
+	}
+	if v := os.Getenv("GC_CHECK_INTERVAL"); v != "" {
+		if cfg.GC_CHECK_INTERVAL, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid GC_CHECK_INTERVAL: %v", err)
+		}
+	}
+	if v := os.Getenv("PIN_VERIFY_INTERVAL"); v != "" {
+		if cfg.PIN_VERIFY_INTERVAL, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("invalid PIN_VERIFY_INTERVAL: %v", err)
+		}
+	}
+	if v := os.Getenv("PIN_REPAIR"); v != "" {
+		if cfg.PIN_REPAIR, err = strconv.ParseBool(v); err != nil {
+			log.Fatalf("invalid PIN_REPAIR: %v", err)
+		}
+	}
 
-	rpc, err := ipfs.NewClient(config.RPC_ADDR)
+	cfg.DENYLIST_PATH = os.Getenv("DENYLIST_PATH")
+	cfg.DENYLIST_RELOAD_INTERVAL = time.Minute
+	if v := os.Getenv("DENYLIST_RELOAD_INTERVAL"); v != "" {
+		if cfg.DENYLIST_RELOAD_INTERVAL, err = time.ParseDuration(v); err != nil || cfg.DENYLIST_RELOAD_INTERVAL <= 0 {
+			log.Fatalf("invalid DENYLIST_RELOAD_INTERVAL: %q", v)
+		}
+	}
+
+	cfg.CLAMD_ADDR = os.Getenv("CLAMD_ADDR")
+	cfg.SCANNER_COMMAND = strings.Fields(os.Getenv("SCANNER_COMMAND"))
+	if cfg.CLAMD_ADDR != "" && len(cfg.SCANNER_COMMAND) > 0 {
+		log.Fatal("only one of CLAMD_ADDR and SCANNER_COMMAND may be set")
+	}
+	cfg.SCAN_TIMEOUT = scanner.DefaultTimeout
+	if v := os.Getenv("SCAN_TIMEOUT"); v != "" {
+		if cfg.SCAN_TIMEOUT, err = time.ParseDuration(v); err != nil || cfg.SCAN_TIMEOUT <= 0 {
+			log.Fatalf("invalid SCAN_TIMEOUT: %q", v)
+		}
+	}
+	cfg.QUARANTINE_PATH = os.Getenv("QUARANTINE_PATH")
+	if cfg.QUARANTINE_PATH == "" {
+		cfg.QUARANTINE_PATH = "quarantine"
+	}
+
+	if v := os.Getenv("ENCRYPTION_KEY"); v != "" {
+		if cfg.ENCRYPTION_KEY, err = encryption.ParseMasterKey(v); err != nil {
+			log.Fatalf("invalid ENCRYPTION_KEY: %v", err)
+		}
+	}
+
+	cfg.STORE_PATH = os.Getenv("STORE_PATH")
+	if cfg.STORE_PATH == "" {
+		cfg.STORE_PATH = "hive.db"
+	}
+	cfg.THUMBNAIL_PATH = os.Getenv("THUMBNAIL_PATH")
+	if cfg.THUMBNAIL_PATH == "" {
+		cfg.THUMBNAIL_PATH = "thumbnails"
+	}
+	cfg.PIN_WORKERS = 2
+	if v := os.Getenv("PIN_WORKERS"); v != "" {
+		if cfg.PIN_WORKERS, err = strconv.Atoi(v); err != nil || cfg.PIN_WORKERS < 1 {
+			log.Fatalf("invalid PIN_WORKERS: %q", v)
+		}
+	}
+
+	rpc, err := ipfs.NewClient(cfg.RPC_ADDR)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -28,10 +119,60 @@ func main() {
 	defer cancel()
 
 	client := ipfs.NewClientImpl(rpc)
-	hndl := handler.NewHandlerImpl(client)
+
+	// "rebuild-index" reconciles the file metadata index with the pins of the node instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-index" {
+		rebuildIndex(ctx, client, cfg.STORE_PATH)
+		return
+	}
+
+	checker := pinhealth.NewChecker(client)
+	checker.Start(ctx, cfg.PIN_VERIFY_INTERVAL, cfg.PIN_REPAIR)
+
+	st, err := store.Open(cfg.STORE_PATH)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer st.Close()
+
+	collector := gc.NewCollector(client, st)
+	if err := collector.Start(ctx, cfg.GC_SCHEDULE, cfg.GC_MIN_FREE, cfg.GC_CHECK_INTERVAL); err != nil {
+		log.Fatal(err)
+	}
+
+	pinJobs := pinjob.NewManager(client, st, cfg.PIN_WORKERS)
+	if err := pinJobs.Start(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	thumbs, err := thumbnail.NewCache(cfg.THUMBNAIL_PATH)
+	if err != nil {
+		log.Fatal(err)
+	}
+	shares, err := share.NewManager(st, []byte(cfg.SHARE_SECRET))
+	if err != nil {
+		log.Fatal(err)
+	}
+	denied, err := denylist.NewManager(st, cfg.DENYLIST_PATH)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go denied.Watch(ctx, cfg.DENYLIST_RELOAD_INTERVAL)
+	uploadScanner, quarantine, err := newScanner(cfg, st)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	files := metadata.NewIndex(st)
+	hndl := handler.NewHandlerImpl(client, cfg, handler.WithCollector(collector), handler.WithPinChecker(checker),
+		handler.WithPinJobs(pinJobs), handler.WithPinService(psa.NewService(client, st, pinJobs, files)),
+		handler.WithVersions(versions.NewHistory(st)), handler.WithFileIndex(files),
+		handler.WithSearchIndex(search.NewIndex(st)), handler.WithThumbnails(thumbs),
+		handler.WithShares(shares), handler.WithDenylist(denied), handler.WithScanner(uploadScanner, quarantine),
+		handler.WithMasterKey(cfg.ENCRYPTION_KEY))
 
 	srv := &http.Server{
-		Addr:    config.SERVER_ADDR,
+		Addr:    cfg.SERVER_ADDR,
 		Handler: hndl.Mux(),
 		// ReadTimeout:  30 * time.Second,
 		// WriteTimeout: 30 * time.Second,
@@ -46,6 +187,22 @@ func main() {
 		}
 	}()
 
+	// the gateway listens on its own address so that websites served from IPFS get an origin apart from Hive's
+	var gatewaySrv *http.Server
+	if cfg.GATEWAY_SERVER_ADDR != "" {
+		gatewaySrv = &http.Server{
+			Addr:    cfg.GATEWAY_SERVER_ADDR,
+			Handler: hndl.GatewayMux(),
+		}
+		go func() {
+			log.Printf("gateway started on %s", gatewaySrv.Addr)
+			if err := gatewaySrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("failed to start gateway: %v", err)
+				cancel()
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -59,6 +216,56 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("could not gracefully shutdown the server: %v", err)
 	}
+	if gatewaySrv != nil {
+		gatewaySrv.SetKeepAlivesEnabled(false)
+		if err := gatewaySrv.Shutdown(ctx); err != nil {
+			log.Fatalf("could not gracefully shutdown the gateway: %v", err)
+		}
+	}
 
 	log.Println("server gracefully stopped")
 }
+
+// newScanner creates the scanner uploads are scanned with, clamd or a command, and the quarantine infected
+// uploads are kept in. Both are nil if no scanner is configured.
+func newScanner(cfg *config.Config, st *store.Store) (scanner.Scanner, *scanner.Quarantine, error) {
+	var s scanner.Scanner
+	switch {
+	case cfg.CLAMD_ADDR != "":
+		clamd, err := scanner.NewClamd(cfg.CLAMD_ADDR, cfg.SCAN_TIMEOUT)
+		if err != nil {
+			return nil, nil, err
+		}
+		s = clamd
+	case len(cfg.SCANNER_COMMAND) > 0:
+		cmd, err := scanner.NewExec(cfg.SCANNER_COMMAND, cfg.SCAN_TIMEOUT)
+		if err != nil {
+			return nil, nil, err
+		}
+		s = cmd
+	default:
+		return nil, nil, nil
+	}
+
+	quarantine, err := scanner.NewQuarantine(st, cfg.QUARANTINE_PATH)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, quarantine, nil
+}
+
+// rebuildIndex reconciles the file metadata index in the store at storePath with the recursive pins of the
+// IPFS node. The store is locked by a running server, which must be stopped first.
+func rebuildIndex(ctx context.Context, client ipfs.Client, storePath string) {
+	st, err := store.Open(storePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer st.Close()
+
+	result, err := metadata.NewIndex(st).Rebuild(ctx, client)
+	if err != nil {
+		log.Fatalf("failed to rebuild the file index: %v", err)
+	}
+	log.Printf("file index rebuilt: %d added, %d removed, %d kept", result.Added, result.Removed, result.Kept)
+}
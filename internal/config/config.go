@@ -1,14 +1,47 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // Config holds the configuration for the application.
 type Config struct {
-	RPC_ADDR     string
-	WEB_UI_ADDR  string
-	GATEWAY_ADDR string
-	SERVER_ADDR  string
+	RPC_ADDR      string
+	WEB_UI_ADDR   string
+	GATEWAY_ADDR  string
+	SERVER_ADDR   string
+	PUBSUB_TOPICS []string          // the pubsub topics that may be bridged over WebSockets, "*" allows every topic.
+	USER_TOKENS   map[string]string // maps the bearer tokens of Hive users to their user names.
+
+	GATEWAY_SERVER_ADDR string // where Hive's gateway also listens in an origin of its own, unsandboxed, if it is set.
+
+	ADMIN_TOKEN       string        // the bearer token of the admin API, which is disabled if it is empty.
+	SHARE_SECRET      string        // signs the tokens of share links, a generated secret is kept in the store if it is empty.
+	GC_SCHEDULE       string        // the cron schedule of garbage collections, none are scheduled if it is empty.
+	GC_MIN_FREE       uint64        // garbage collect when fewer bytes are left before the repository reaches its StorageMax.
+	GC_CHECK_INTERVAL time.Duration // how often the free space of the repository is checked.
+
+	PIN_VERIFY_INTERVAL time.Duration // how often pins are verified in the background, never if it is zero.
+	PIN_REPAIR          bool          // whether background verifications fetch bad blocks again.
+
+	DENYLIST_PATH            string        // the denylist file of blocked content, none if it is empty.
+	DENYLIST_RELOAD_INTERVAL time.Duration // how often the denylist file is checked for changes.
+
+	CLAMD_ADDR      string        // the address of the clamd uploads are scanned with, if it is set.
+	SCANNER_COMMAND []string      // the command uploads are scanned with, if it is set, instead of clamd.
+	SCAN_TIMEOUT    time.Duration // bounds the scan of an upload.
+	QUARANTINE_PATH string        // the directory infected uploads are kept in.
+
+	ENCRYPTION_KEY []byte // the master key of encrypted uploads, which need a passphrase if it is empty.
+
+	STORE_PATH     string // the path of Hive's own database.
+	THUMBNAIL_PATH string // the directory the thumbnails of images are cached in.
+	PIN_WORKERS    int    // how many pin jobs run at the same time.
 }
 
-// Load creates a new Config struct with the provided configuration values. 
+// Load creates a new Config struct with the provided configuration values.
 func Load(rpcAddr, webUIAddr, gatewayAddr, serverAddr string) *Config {
 	return &Config{
 		RPC_ADDR:     rpcAddr,
@@ -17,3 +50,41 @@ func Load(rpcAddr, webUIAddr, gatewayAddr, serverAddr string) *Config {
 		SERVER_ADDR:  serverAddr,
 	}
 }
+
+// ParseList splits a comma separated configuration value into its trimmed, non-empty items.
+func ParseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseUserTokens parses a comma separated list of "user:token" pairs into a map from token to user.
+func ParseUserTokens(value string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, item := range ParseList(value) {
+		user, token, ok := strings.Cut(item, ":")
+		user, token = strings.TrimSpace(user), strings.TrimSpace(token)
+		if !ok || user == "" || token == "" {
+			return nil, fmt.Errorf("%q is not a user:token pair", item)
+		}
+		if other, ok := tokens[token]; ok && other != user {
+			return nil, fmt.Errorf("users %q and %q share a token", other, user)
+		}
+		tokens[token] = user
+	}
+	return tokens, nil
+}
+
+// PubsubTopicAllowed reports whether the given pubsub topic is in the PUBSUB_TOPICS allow-list.
+func (c *Config) PubsubTopicAllowed(topic string) bool {
+	for _, allowed := range c.PUBSUB_TOPICS {
+		if allowed == "*" || allowed == topic {
+			return true
+		}
+	}
+	return false
+}
@@ -76,3 +76,127 @@ func TestLoad(t *testing.T) {
 		})
 	}
 }
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name:  "Single item",
+			value: "hive",
+			want:  []string{"hive"},
+		},
+		{
+			name:  "Multiple items with spaces",
+			value: " hive, sites ,backups",
+			want:  []string{"hive", "sites", "backups"},
+		},
+		{
+			name:  "Empty items",
+			value: "hive,,",
+			want:  []string{"hive"},
+		},
+		{
+			name:  "Empty value",
+			value: "",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ParseList(tt.value))
+		})
+	}
+}
+
+func TestParseUserTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "Multiple users",
+			value: "alice:a-token, bob : b-token",
+			want:  map[string]string{"a-token": "alice", "b-token": "bob"},
+		},
+		{
+			name:  "Token containing a colon",
+			value: "alice:a:token",
+			want:  map[string]string{"a:token": "alice"},
+		},
+		{
+			name:  "Empty value",
+			value: "",
+			want:  map[string]string{},
+		},
+		{
+			name:    "Missing token",
+			value:   "alice",
+			wantErr: true,
+		},
+		{
+			name:    "Empty user",
+			value:   ":a-token",
+			wantErr: true,
+		},
+		{
+			name:    "Shared token",
+			value:   "alice:token,bob:token",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUserTokens(tt.value)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPubsubTopicAllowed(t *testing.T) {
+	tests := []struct {
+		name   string
+		topics []string
+		topic  string
+		want   bool
+	}{
+		{
+			name:   "Listed topic",
+			topics: []string{"hive", "sites"},
+			topic:  "sites",
+			want:   true,
+		},
+		{
+			name:   "Unlisted topic",
+			topics: []string{"hive"},
+			topic:  "sites",
+			want:   false,
+		},
+		{
+			name:   "Wildcard",
+			topics: []string{"*"},
+			topic:  "sites",
+			want:   true,
+		},
+		{
+			name:   "Empty allow-list",
+			topics: nil,
+			topic:  "hive",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{PUBSUB_TOPICS: tt.topics}
+			require.Equal(t, tt.want, cfg.PubsubTopicAllowed(tt.topic))
+		})
+	}
+}
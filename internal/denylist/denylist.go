@@ -0,0 +1,282 @@
+package denylist
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+)
+
+const (
+	SourceFile  = "file"  // the rule comes from the denylist file.
+	SourceAdmin = "admin" // the rule was added through the admin API.
+
+	maxLineSize = 64 << 10 // bounds a line of a denylist file, 64KB.
+)
+
+var (
+	// ErrBlocked is returned when content is blocked by the denylist.
+	ErrBlocked = errors.New("content is blocked")
+
+	// ErrInvalidRule is returned when a denylist rule cannot be parsed.
+	ErrInvalidRule = errors.New("invalid denylist rule")
+
+	// ErrNotFound is returned when a denylist rule added through the admin API does not exist.
+	ErrNotFound = errors.New("denylist rule not found")
+)
+
+// Entry is a rule of the denylist.
+type Entry struct {
+	Rule    string     `json:"rule"`               // the rule, in the compact denylist format.
+	Reason  string     `json:"reason,omitempty"`   // why the content is blocked, from the "reason" hint of the rule.
+	Source  string     `json:"source"`             // where the rule comes from, SourceFile or SourceAdmin.
+	AddedAt *time.Time `json:"added_at,omitempty"` // when a rule of the admin API was added.
+}
+
+// Parse parses a denylist in the compact format of IPFS gateways. An optional header ends with a "---" line.
+// Every other line is a rule, optionally followed by "key=value" hints, and blank lines and lines starting with
+// # are ignored. Rules are:
+//
+//   - /ipfs/{cid} blocks the CID, in any version, and every path under it; /ipfs/{cid}/* does the same.
+//   - /ipfs/{cid}/{path} blocks a path, and /ipfs/{cid}/{path}* every path starting with it.
+//   - /ipns/{name}, /ipns/{name}/{path} and /ipns/{name}/{path}* do the same for IPNS names and DNSLink domains.
+//   - //{multihash} blocks the content paths whose base58 multihash of CID, optionally followed by /{path}, or
+//     IPNS name, has that SHA2-256 multihash, so that lists do not spell out what they block.
+//   - //{hex} blocks the content paths whose "{cidv1}/{path}" has that hex SHA2-256, as in the legacy badbits list.
+//   - a rule starting with ! allows what it matches, even if other rules block it.
+func Parse(r io.Reader, source string) ([]Entry, error) {
+	var lines []string
+	header := true
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "---" && header {
+			// what came before is the header
+			lines, header = lines[:0], false
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, line := range lines {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		entry := Entry{Rule: fields[0], Source: source}
+		for _, hint := range fields[1:] {
+			if key, value, ok := strings.Cut(hint, "="); ok && key == "reason" {
+				entry.Reason = value
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if _, err := NewList(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// List is a compiled set of denylist rules.
+type List struct {
+	block ruleSet
+	allow ruleSet
+}
+
+// ruleSet is a set of rules, indexed by what they match.
+type ruleSet struct {
+	roots    map[string]Entry // "/ipfs/{multihash}" or "/ipns/{name}", which block everything under them.
+	paths    map[string]Entry // "/ipfs/{multihash}/{path}" or "/ipns/{name}/{path}".
+	prefixes []prefixRule     // the rules blocking the paths starting with a prefix.
+	hashes   map[string]Entry // the double-hashes of content paths.
+}
+
+// prefixRule is a rule matching the paths starting with a prefix.
+type prefixRule struct {
+	prefix string
+	entry  Entry
+}
+
+// NewList compiles the given rules.
+func NewList(entries []Entry) (*List, error) {
+	l := &List{block: newRuleSet(), allow: newRuleSet()}
+	for _, e := range entries {
+		set, rule := &l.block, e.Rule
+		if allowed, ok := strings.CutPrefix(rule, "!"); ok {
+			set, rule = &l.allow, allowed
+		}
+		if err := set.add(rule, e); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Check returns the rule blocking the content path, such as /ipfs/{cid}/{path} or /ipns/{name}/{path}, and
+// whether it is blocked. Only the root of the path is checked by its CID, not the CIDs it resolves through: the
+// CID a path resolves to is checked on its own by the caller.
+func (l *List) Check(contentPath string) (Entry, bool) {
+	t, ok := newTarget(contentPath)
+	if !ok {
+		return Entry{}, false
+	}
+	if _, ok := l.allow.match(t); ok {
+		return Entry{}, false
+	}
+	return l.block.match(t)
+}
+
+// Empty reports whether the list blocks nothing.
+func (l *List) Empty() bool {
+	return l.block.empty()
+}
+
+func newRuleSet() ruleSet {
+	return ruleSet{
+		roots:  map[string]Entry{},
+		paths:  map[string]Entry{},
+		hashes: map[string]Entry{},
+	}
+}
+
+// empty reports whether the set has no rules.
+func (s *ruleSet) empty() bool {
+	return len(s.roots) == 0 && len(s.paths) == 0 && len(s.prefixes) == 0 && len(s.hashes) == 0
+}
+
+// add adds a rule, without its ! prefix, to the set.
+func (s *ruleSet) add(rule string, e Entry) error {
+	if hash, ok := strings.CutPrefix(rule, "//"); ok {
+		if _, err := mh.FromB58String(hash); err == nil {
+			s.hashes[hash] = e
+			return nil
+		}
+		if b, err := hex.DecodeString(hash); err == nil && len(b) == sha256.Size {
+			s.hashes[strings.ToLower(hash)] = e
+			return nil
+		}
+		return fmt.Errorf("%w: %q is not a multihash or a hex SHA2-256", ErrInvalidRule, rule)
+	}
+
+	namespace, rest, ok := cutNamespace(rule)
+	if !ok {
+		return fmt.Errorf("%w: %q must start with /ipfs/, /ipns/ or //", ErrInvalidRule, rule)
+	}
+	root, sub, _ := strings.Cut(rest, "/")
+	if namespace == "ipfs" {
+		c, err := cid.Decode(root)
+		if err != nil {
+			return fmt.Errorf("%w: %q has an invalid cid", ErrInvalidRule, rule)
+		}
+		root = c.Hash().B58String()
+	} else if root == "" || strings.Contains(root, "*") {
+		return fmt.Errorf("%w: %q has an invalid name", ErrInvalidRule, rule)
+	}
+
+	key := "/" + namespace + "/" + root
+	sub = strings.TrimSuffix(sub, "/")
+	switch {
+	case sub == "" || sub == "*":
+		s.roots[key] = e
+	case strings.HasSuffix(sub, "*"):
+		s.prefixes = append(s.prefixes, prefixRule{prefix: key + "/" + strings.TrimSuffix(sub, "*"), entry: e})
+	default:
+		s.paths[key+"/"+sub] = e
+	}
+	return nil
+}
+
+// match returns the first rule of the set matching the target.
+func (s *ruleSet) match(t target) (Entry, bool) {
+	if e, ok := s.roots[t.root]; ok {
+		return e, true
+	}
+	if e, ok := s.paths[t.path]; ok {
+		return e, true
+	}
+	for _, p := range s.prefixes {
+		if strings.HasPrefix(t.path, p.prefix) {
+			return p.entry, true
+		}
+	}
+	for _, hash := range t.hashes {
+		if e, ok := s.hashes[hash]; ok {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// target is a content path as the rules match it.
+type target struct {
+	root   string   // "/ipfs/{multihash}" or "/ipns/{name}".
+	path   string   // the root followed by the path, without a trailing slash.
+	hashes []string // the double-hashes the path may be listed under.
+}
+
+// newTarget normalizes a content path, reporting false if it is not one.
+func newTarget(contentPath string) (target, bool) {
+	namespace, rest, ok := cutNamespace(contentPath)
+	if !ok {
+		return target{}, false
+	}
+	root, sub, _ := strings.Cut(rest, "/")
+	sub = strings.Trim(sub, "/")
+
+	// the legacy badbits list hashes the CIDv1 of a CID, the compact format its multihash
+	name, legacyName := root, root
+	if namespace == "ipfs" {
+		c, err := cid.Decode(root)
+		if err != nil {
+			return target{}, false
+		}
+		name, legacyName = c.Hash().B58String(), cid.NewCidV1(c.Type(), c.Hash()).String()
+	} else if root == "" {
+		return target{}, false
+	}
+
+	t := target{root: "/" + namespace + "/" + name}
+	t.path = t.root
+	if sub != "" {
+		t.path += "/" + sub
+	}
+	t.hashes = append(t.hashes, doubleHash(name), legacyHash(legacyName+"/"))
+	if sub != "" {
+		t.hashes = append(t.hashes, doubleHash(name+"/"+sub), legacyHash(legacyName+"/"+sub))
+	}
+	return t, true
+}
+
+// cutNamespace splits a content path into its namespace, ipfs or ipns, and the rest of the path.
+func cutNamespace(contentPath string) (string, string, bool) {
+	for _, namespace := range []string{"ipfs", "ipns"} {
+		if rest, ok := strings.CutPrefix(contentPath, "/"+namespace+"/"); ok {
+			return namespace, rest, true
+		}
+	}
+	return "", "", false
+}
+
+// doubleHash returns the base58 SHA2-256 multihash of s.
+func doubleHash(s string) string {
+	hash, _ := mh.Sum([]byte(s), mh.SHA2_256, -1)
+	return hash.B58String()
+}
+
+// legacyHash returns the hex SHA2-256 of s.
+func legacyHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
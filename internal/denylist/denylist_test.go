@@ -0,0 +1,120 @@
+package denylist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// the same content as a CIDv0, a dag-pb CIDv1 and a raw CIDv1
+const (
+	cidV0  = "QmRN6wdp1S2A5EtjW9A3M1vKSBuQQGcgvuhoMUoEz4iiT5"
+	cidV1  = "bafybeibm6jg3ux5qumhcn2b3flc3tyu6dmlb4xa7u5bf44yegnrjhc4yeq"
+	cidRaw = "bafkreibm6jg3ux5qumhcn2b3flc3tyu6dmlb4xa7u5bf44yegnrjhc4yeq"
+
+	otherCid   = "QmTDPv6TFivv9nGX3oiReXBiRcwtvDxkpARZUZC9Fwysre"
+	otherCidV1 = "bafybeicin2sgejgrxnh3nahtj56jvwlkr4sozcf6opvi4wtmmuta5hfyu4"
+)
+
+func TestParse(t *testing.T) {
+	entries, err := Parse(strings.NewReader(`version: 1
+name: test list
+---
+# blocked content
+/ipfs/`+cidV1+` reason=abuse
+/ipns/bad.example
+
+!/ipfs/`+otherCid+`/public
+`), SourceFile)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{
+		{Rule: "/ipfs/" + cidV1, Reason: "abuse", Source: SourceFile},
+		{Rule: "/ipns/bad.example", Source: SourceFile},
+		{Rule: "!/ipfs/" + otherCid + "/public", Source: SourceFile},
+	}, entries)
+
+	// without a header
+	entries, err = Parse(strings.NewReader("/ipfs/"+cidV0+"\n"), SourceAdmin)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{{Rule: "/ipfs/" + cidV0, Source: SourceAdmin}}, entries)
+
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{name: "Unknown namespace", rule: "/ipld/" + cidV1},
+		{name: "Bare CID", rule: cidV1},
+		{name: "Invalid CID", rule: "/ipfs/nonsense"},
+		{name: "Empty name", rule: "/ipns/"},
+		{name: "Invalid hash", rule: "//nonsense"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.rule), SourceFile)
+			require.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	list, err := NewList([]Entry{
+		{Rule: "/ipfs/" + cidV0, Reason: "abuse"},
+		{Rule: "/ipfs/" + otherCid + "/private/report.pdf"},
+		{Rule: "/ipfs/" + otherCid + "/secret*"},
+		{Rule: "!/ipfs/" + otherCid + "/secret/public.txt"},
+		{Rule: "/ipns/bad.example"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path    string
+		blocked bool
+		rule    string
+	}{
+		{path: "/ipfs/" + cidV0, blocked: true, rule: "/ipfs/" + cidV0},
+		{path: "/ipfs/" + cidV1, blocked: true, rule: "/ipfs/" + cidV0},
+		{path: "/ipfs/" + cidRaw + "/any/path", blocked: true, rule: "/ipfs/" + cidV0},
+		{path: "/ipfs/" + otherCid},
+		{path: "/ipfs/" + otherCid + "/private/report.pdf", blocked: true, rule: "/ipfs/" + otherCid + "/private/report.pdf"},
+		{path: "/ipfs/" + otherCid + "/private/report.pdf/", blocked: true, rule: "/ipfs/" + otherCid + "/private/report.pdf"},
+		{path: "/ipfs/" + otherCid + "/private/other.pdf"},
+		{path: "/ipfs/" + otherCid + "/secrets/a.txt", blocked: true, rule: "/ipfs/" + otherCid + "/secret*"},
+		{path: "/ipfs/" + otherCid + "/secret/public.txt"},
+		{path: "/ipns/bad.example/index.html", blocked: true, rule: "/ipns/bad.example"},
+		{path: "/ipns/good.example"},
+		{path: "/ipfs/nonsense"},
+		{path: "nonsense"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			e, blocked := list.Check(tt.path)
+			require.Equal(t, tt.blocked, blocked)
+			require.Equal(t, tt.rule, e.Rule)
+		})
+	}
+}
+
+func TestCheckDoubleHash(t *testing.T) {
+	target, ok := newTarget("/ipfs/" + cidV0)
+	require.True(t, ok)
+	mhash := strings.TrimPrefix(target.root, "/ipfs/")
+
+	list, err := NewList([]Entry{
+		// the compact format hashes the multihash of the CID, so every version of it is blocked
+		{Rule: "//" + doubleHash(mhash)},
+		{Rule: "//" + doubleHash("bad.example/page")},
+		// the legacy format hashes the CIDv1 with its path
+		{Rule: "//" + strings.ToUpper(legacyHash(otherCidV1+"/docs"))},
+	})
+	require.NoError(t, err)
+
+	for _, path := range []string{"/ipfs/" + cidV1, "/ipfs/" + cidRaw, "/ipns/bad.example/page", "/ipfs/" + otherCid + "/docs"} {
+		_, blocked := list.Check(path)
+		require.True(t, blocked, path)
+	}
+	for _, path := range []string{"/ipns/bad.example", "/ipfs/" + otherCid, "/ipfs/" + otherCid + "/docs/a"} {
+		_, blocked := list.Check(path)
+		require.False(t, blocked, path)
+	}
+}
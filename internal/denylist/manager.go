@@ -0,0 +1,303 @@
+package denylist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zde37/Hive/internal/store"
+)
+
+const (
+	bucket      = "denylist"       // the store bucket holding the rules of the admin API, keyed by rule.
+	auditBucket = "denylist_audit" // the store bucket holding the blocked attempts, keyed in time order.
+
+	maxAudit = 1000 // the number of blocked attempts kept in the audit.
+)
+
+// Attempt is an attempt to reach blocked content.
+type Attempt struct {
+	Time       time.Time `json:"time"`             // when the attempt was made.
+	Operation  string    `json:"operation"`        // what was attempted, such as "download" or "pin".
+	Path       string    `json:"path"`             // the content path that was asked for.
+	Rule       string    `json:"rule"`             // the rule blocking it.
+	Reason     string    `json:"reason,omitempty"` // why the rule blocks it.
+	User       string    `json:"user,omitempty"`   // the Hive user who made the attempt, empty if anonymous.
+	RemoteAddr string    `json:"remote_addr"`      // the address the attempt came from.
+}
+
+// FileStatus is the status of the denylist file.
+type FileStatus struct {
+	Path     string    `json:"path"`      // the path of the file.
+	Rules    int       `json:"rules"`     // the number of rules it holds.
+	LoadedAt time.Time `json:"loaded_at"` // when it was last loaded.
+}
+
+// Manager checks content paths against the rules of a denylist file, which is reloaded when it changes, and
+// the rules added through the admin API, which are kept in the store. It keeps an audit of the blocked attempts.
+type Manager struct {
+	store *store.Store
+	path  string // the denylist file, none if empty.
+
+	mu       sync.RWMutex // guards the fields below.
+	list     *List        // the compiled rules of the file and of the admin API.
+	file     []Entry      // the rules of the file.
+	modTime  time.Time    // the modification time of the file when it was loaded.
+	size     int64        // the size of the file when it was loaded.
+	loadedAt time.Time    // when the file was loaded.
+
+	auditMu   sync.Mutex // serializes the writes of the audit.
+	lastAudit int64      // the key of the last attempt recorded, in Unix nanoseconds.
+}
+
+// NewManager creates a new Manager with the rules of the denylist file at path, if it is not empty, and of the
+// admin API kept in store.
+func NewManager(st *store.Store, path string) (*Manager, error) {
+	m := &Manager{
+		store: st,
+		path:  path,
+	}
+	if path != "" {
+		if err := m.Reload(); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	if err := m.compile(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Watch reloads the denylist file whenever its modification time or size changes, checking every interval until
+// ctx is done. A file that fails to load is logged and the rules loaded before are kept.
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) {
+	if m.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(m.path)
+		if err != nil {
+			log.Printf("denylist: failed to check %s: %v", m.path, err)
+			continue
+		}
+		m.mu.RLock()
+		changed := !info.ModTime().Equal(m.modTime) || info.Size() != m.size
+		m.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		if err := m.Reload(); err != nil {
+			log.Printf("denylist: failed to reload %s: %v", m.path, err)
+			continue
+		}
+		log.Printf("denylist: reloaded %s", m.path)
+	}
+}
+
+// Reload loads the denylist file again. The rules loaded before are kept if it fails.
+func (m *Manager) Reload() error {
+	if m.path == "" {
+		return fmt.Errorf("no denylist file configured")
+	}
+
+	f, err := os.Open(m.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	entries, err := Parse(f, SourceFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", m.path, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.file, m.modTime, m.size, m.loadedAt = entries, info.ModTime(), info.Size(), time.Now().UTC()
+	return m.compileLocked()
+}
+
+// File returns the status of the denylist file, or nil if there is none.
+func (m *Manager) File() *FileStatus {
+	if m.path == "" {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return &FileStatus{Path: m.path, Rules: len(m.file), LoadedAt: m.loadedAt}
+}
+
+// Add adds a rule through the admin API.
+func (m *Manager) Add(rule, reason string) (Entry, error) {
+	rule = strings.TrimSpace(rule)
+	now := time.Now().UTC()
+	e := Entry{Rule: rule, Reason: reason, Source: SourceAdmin, AddedAt: &now}
+	if _, err := NewList([]Entry{e}); err != nil {
+		return Entry{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.store.Put(bucket, rule, e); err != nil {
+		return Entry{}, err
+	}
+	return e, m.compileLocked()
+}
+
+// Remove removes a rule added through the admin API. The rules of the denylist file are only removed from the
+// file.
+func (m *Manager) Remove(rule string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	err := m.store.Update(func(tx *store.Tx) error {
+		var e Entry
+		if err := tx.Get(bucket, rule, &e); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("%w: %s", ErrNotFound, rule)
+			}
+			return err
+		}
+		return tx.Delete(bucket, rule)
+	})
+	if err != nil {
+		return err
+	}
+	return m.compileLocked()
+}
+
+// Entries returns the rules added through the admin API, oldest first.
+func (m *Manager) Entries() ([]Entry, error) {
+	entries := []Entry{}
+	err := m.store.ForEach(bucket, func(_ string, value []byte) error {
+		var e Entry
+		if err := json.Unmarshal(value, &e); err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].AddedAt.Before(*entries[j].AddedAt)
+	})
+	return entries, nil
+}
+
+// Check returns the rule blocking the content path, such as /ipfs/{cid}/{path} or /ipns/{name}/{path}, and
+// whether it is blocked.
+func (m *Manager) Check(contentPath string) (Entry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.list.Check(contentPath)
+}
+
+// Empty reports whether the denylist blocks nothing, so that callers can skip resolving paths to check them.
+func (m *Manager) Empty() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.list.Empty()
+}
+
+// Record records a blocked attempt in the audit, dropping the oldest attempts beyond the last 1000.
+func (m *Manager) Record(a Attempt) error {
+	m.auditMu.Lock()
+	defer m.auditMu.Unlock()
+
+	// the keys sort in time order, and attempts made in the same nanosecond are kept apart
+	key := a.Time.UnixNano()
+	if key <= m.lastAudit {
+		key = m.lastAudit + 1
+	}
+	m.lastAudit = key
+	if err := m.store.Put(auditBucket, fmt.Sprintf("%020d", key), a); err != nil {
+		return err
+	}
+
+	var keys []string
+	err := m.store.ForEach(auditBucket, func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for len(keys) > maxAudit {
+		if err := m.store.Delete(auditBucket, keys[0]); err != nil {
+			return err
+		}
+		keys = keys[1:]
+	}
+	return nil
+}
+
+// Audit returns up to limit of the last blocked attempts, newest first.
+func (m *Manager) Audit(limit int) ([]Attempt, error) {
+	attempts := []Attempt{}
+	err := m.store.ForEach(auditBucket, func(_ string, value []byte) error {
+		var a Attempt
+		if err := json.Unmarshal(value, &a); err != nil {
+			return err
+		}
+		attempts = append(attempts, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(attempts)-1; i < j; i, j = i+1, j-1 {
+		attempts[i], attempts[j] = attempts[j], attempts[i]
+	}
+	if len(attempts) > limit {
+		attempts = attempts[:limit]
+	}
+	return attempts, nil
+}
+
+// compile compiles the rules of the file and of the admin API.
+func (m *Manager) compile() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.compileLocked()
+}
+
+// compileLocked is compile with mu held.
+func (m *Manager) compileLocked() error {
+	admin, err := m.Entries()
+	if err != nil {
+		return err
+	}
+	list, err := NewList(append(append([]Entry{}, m.file...), admin...))
+	if err != nil {
+		return err
+	}
+	m.list = list
+	return nil
+}
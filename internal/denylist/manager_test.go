@@ -0,0 +1,123 @@
+package denylist
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func TestManagerAdmin(t *testing.T) {
+	st := newTestStore(t)
+	m, err := NewManager(st, "")
+	require.NoError(t, err)
+	require.Nil(t, m.File())
+	require.True(t, m.Empty())
+
+	_, blocked := m.Check("/ipfs/" + cidV1)
+	require.False(t, blocked)
+
+	e, err := m.Add(" /ipfs/"+cidV0+" ", "abuse")
+	require.NoError(t, err)
+	require.Equal(t, "/ipfs/"+cidV0, e.Rule)
+	require.Equal(t, SourceAdmin, e.Source)
+	require.NotNil(t, e.AddedAt)
+
+	blockedBy, blocked := m.Check("/ipfs/" + cidV1)
+	require.True(t, blocked)
+	require.Equal(t, "abuse", blockedBy.Reason)
+	require.False(t, m.Empty())
+
+	_, err = m.Add("/ipfs/nonsense", "")
+	require.ErrorIs(t, err, ErrInvalidRule)
+
+	// the rules of the admin API are kept across restarts
+	m, err = NewManager(st, "")
+	require.NoError(t, err)
+	_, blocked = m.Check("/ipfs/" + cidV1)
+	require.True(t, blocked)
+	entries, err := m.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.NoError(t, m.Remove("/ipfs/"+cidV0))
+	_, blocked = m.Check("/ipfs/" + cidV1)
+	require.False(t, blocked)
+	require.True(t, m.Empty())
+	require.ErrorIs(t, m.Remove("/ipfs/"+cidV0), ErrNotFound)
+	require.Error(t, m.Reload())
+}
+
+func TestManagerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("/ipfs/"+cidV0+"\n"), 0o644))
+
+	m, err := NewManager(newTestStore(t), path)
+	require.NoError(t, err)
+	require.Equal(t, 1, m.File().Rules)
+	_, blocked := m.Check("/ipfs/" + cidV1)
+	require.True(t, blocked)
+
+	// an admin rule cannot remove a rule of the file, but can allow what it blocks
+	require.ErrorIs(t, m.Remove("/ipfs/"+cidV0), ErrNotFound)
+	_, err = m.Add("!/ipfs/"+cidV0+"/public", "")
+	require.NoError(t, err)
+	_, blocked = m.Check("/ipfs/" + cidV0 + "/public")
+	require.False(t, blocked)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Watch(ctx, 10*time.Millisecond)
+
+	// the file is reloaded when it changes
+	require.NoError(t, os.WriteFile(path, []byte("/ipfs/"+otherCid+"\n/ipns/bad.example\n"), 0o644))
+	require.Eventually(t, func() bool {
+		_, blocked := m.Check("/ipfs/" + otherCid)
+		return blocked
+	}, 5*time.Second, 10*time.Millisecond)
+	_, blocked = m.Check("/ipfs/" + cidV1)
+	require.False(t, blocked)
+	require.Equal(t, 2, m.File().Rules)
+
+	// a broken file keeps the rules loaded before
+	require.NoError(t, os.WriteFile(path, []byte("nonsense\n"), 0o644))
+	require.ErrorIs(t, m.Reload(), ErrInvalidRule)
+	_, blocked = m.Check("/ipfs/" + otherCid)
+	require.True(t, blocked)
+
+	_, err = NewManager(newTestStore(t), filepath.Join(t.TempDir(), "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestAudit(t *testing.T) {
+	m, err := NewManager(newTestStore(t), "")
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	for i := 0; i < maxAudit+5; i++ {
+		// attempts made at the same time are all kept
+		require.NoError(t, m.Record(Attempt{Time: now, Operation: "download", Path: fmt.Sprintf("/ipfs/%d", i)}))
+	}
+
+	attempts, err := m.Audit(maxAudit + 10)
+	require.NoError(t, err)
+	require.Len(t, attempts, maxAudit)
+	require.Equal(t, fmt.Sprintf("/ipfs/%d", maxAudit+4), attempts[0].Path)
+	require.Equal(t, "/ipfs/5", attempts[maxAudit-1].Path)
+
+	attempts, err = m.Audit(2)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+}
@@ -0,0 +1,243 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Files are encrypted with AES-256-GCM in a streaming format that clients can decrypt themselves. An encrypted
+// file starts with a 16 byte header: the magic "HIVE", the format version 1, the chunk size as a
+// big-endian uint32 and a random 7 byte nonce prefix. The plaintext follows in chunks of the chunk size, the
+// last one possibly shorter or empty, each sealed with the data key of the file and its 16 byte tag appended.
+// The nonce of a chunk is the nonce prefix, the index of the chunk as a big-endian uint32 and a byte set to 1
+// for the last chunk and 0 otherwise, and the header is the additional data of every chunk, so that chunks
+// cannot be reordered, dropped or truncated unnoticed.
+const (
+	KeySize          = 32       // the size of data and master keys, for AES-256.
+	DefaultChunkSize = 64 << 10 // the size of the plaintext chunks, 64KB.
+
+	magic        = "HIVE"
+	version      = 1
+	prefixSize   = 7
+	headerSize   = len(magic) + 1 + 4 + prefixSize
+	maxChunkSize = 16 << 20 // bounds the chunk size of a header, 16MB.
+)
+
+var (
+	// ErrInvalidFormat is returned when content is not in the encrypted format.
+	ErrInvalidFormat = errors.New("not an encrypted file")
+
+	// ErrCorrupt is returned when encrypted content fails to authenticate, because it was altered or
+	// truncated or the key is not its key.
+	ErrCorrupt = errors.New("encrypted file is corrupt")
+)
+
+// NewDataKey returns a random data key.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return key, nil
+}
+
+// newGCM returns AES-256-GCM with the given key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writer encrypts what is written to it in chunks.
+type writer struct {
+	w         io.Writer
+	aead      cipher.AEAD
+	header    []byte
+	chunkSize int
+	buf       []byte // the plaintext of the chunk being filled.
+	out       []byte // the sealed chunk.
+	index     uint32 // the index of the chunk being filled.
+	err       error
+}
+
+// NewWriter returns a writer encrypting what is written to it with key into w, in chunks of DefaultChunkSize.
+// The header is written at once, and the last chunk when the writer is closed; closing it does not close w.
+func NewWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[len(magic)] = version
+	binary.BigEndian.PutUint32(header[len(magic)+1:], DefaultChunkSize)
+	if _, err := rand.Read(header[len(magic)+5:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &writer{
+		w:         w,
+		aead:      aead,
+		header:    header,
+		chunkSize: DefaultChunkSize,
+		buf:       make([]byte, 0, DefaultChunkSize),
+	}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// a full chunk is only sealed once more follows, as the last chunk is sealed apart
+		if len(w.buf) == w.chunkSize {
+			if w.err = w.seal(false); w.err != nil {
+				return written, w.err
+			}
+		}
+		n := min(len(p), w.chunkSize-len(w.buf))
+		w.buf = append(w.buf, p[:n]...)
+		p, written = p[n:], written+n
+	}
+	return written, nil
+}
+
+// Close writes the last chunk.
+func (w *writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.err = w.seal(true); w.err != nil {
+		return w.err
+	}
+	w.err = errors.New("write to closed encryption writer")
+	return nil
+}
+
+// seal writes the chunk being filled.
+func (w *writer) seal(last bool) error {
+	if w.index == ^uint32(0) {
+		return errors.New("file is too large to encrypt")
+	}
+	w.out = w.aead.Seal(w.out[:0], nonce(w.header, w.index, last), w.buf, w.header)
+	if _, err := w.w.Write(w.out); err != nil {
+		return err
+	}
+	w.buf, w.index = w.buf[:0], w.index+1
+	return nil
+}
+
+// reader decrypts the chunks read from an encrypted file.
+type reader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	header []byte
+	chunk  []byte // the sealed chunk being read.
+	plain  []byte // what is left of the plaintext of the last chunk read.
+	index  uint32 // the index of the next chunk.
+	done   bool   // whether the last chunk was read.
+	err    error
+}
+
+// NewReader returns a reader decrypting the encrypted file read from r with key. Reads fail with ErrCorrupt as
+// soon as a chunk fails to authenticate, and nothing of a chunk is returned before it is authenticated.
+func NewReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrInvalidFormat
+		}
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(magic)], []byte(magic)) || header[len(magic)] != version {
+		return nil, ErrInvalidFormat
+	}
+	chunkSize := binary.BigEndian.Uint32(header[len(magic)+1:])
+	if chunkSize == 0 || chunkSize > maxChunkSize {
+		return nil, fmt.Errorf("%w: invalid chunk size %d", ErrInvalidFormat, chunkSize)
+	}
+
+	return &reader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		chunk:  make([]byte, int(chunkSize)+aead.Overhead()),
+	}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next reads and opens the next chunk. The last chunk is the one followed by the end of the file, so that a
+// file cut after any chunk fails to authenticate.
+func (r *reader) next() error {
+	n, err := io.ReadFull(r.r, r.chunk)
+	last := false
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: missing last chunk", ErrCorrupt)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := r.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := r.aead.Open(r.chunk[:0], nonce(r.header, r.index, last), r.chunk[:n], r.header)
+	if err != nil {
+		return ErrCorrupt
+	}
+	r.plain, r.index, r.done = plain, r.index+1, last
+	return nil
+}
+
+// nonce returns the nonce of the chunk with the given index of the file with the given header.
+func nonce(header []byte, index uint32, last bool) []byte {
+	n := make([]byte, 12)
+	copy(n, header[len(magic)+5:])
+	binary.BigEndian.PutUint32(n[prefixSize:], index)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
@@ -0,0 +1,105 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// encrypt encrypts plaintext with key, writing it in writes of the given size.
+func encrypt(t *testing.T, key, plaintext []byte, writeSize int) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key)
+	require.NoError(t, err)
+	for p := plaintext; len(p) > 0; {
+		n := min(writeSize, len(p))
+		_, err := w.Write(p[:n])
+		require.NoError(t, err)
+		p = p[n:]
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// decrypt decrypts ciphertext with key.
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(ciphertext), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	key, err := NewDataKey()
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 1, DefaultChunkSize - 1, DefaultChunkSize, DefaultChunkSize + 1, 3*DefaultChunkSize + 100} {
+		plaintext := make([]byte, size)
+		_, err := rand.Read(plaintext)
+		require.NoError(t, err)
+
+		for _, writeSize := range []int{1000, DefaultChunkSize, 5 * DefaultChunkSize} {
+			ciphertext := encrypt(t, key, plaintext, writeSize)
+			chunks := size/DefaultChunkSize + 1
+			if size > 0 && size%DefaultChunkSize == 0 {
+				chunks--
+			}
+			require.Len(t, ciphertext, headerSize+size+chunks*16, "size %d", size)
+
+			decrypted, err := decrypt(key, ciphertext)
+			require.NoError(t, err, "size %d", size)
+			require.Equal(t, plaintext, append([]byte{}, decrypted...), "size %d", size)
+		}
+	}
+
+	// the same plaintext never encrypts the same way
+	plaintext := []byte("hello world")
+	require.NotEqual(t, encrypt(t, key, plaintext, 100), encrypt(t, key, plaintext, 100))
+}
+
+func TestTampering(t *testing.T) {
+	key, err := NewDataKey()
+	require.NoError(t, err)
+	plaintext := bytes.Repeat([]byte("hive"), DefaultChunkSize) // 4 chunks
+	ciphertext := encrypt(t, key, plaintext, DefaultChunkSize)
+	sealed := DefaultChunkSize + 16
+
+	flipped := append([]byte{}, ciphertext...)
+	flipped[headerSize+sealed+10] ^= 1
+	reordered := append(append(append([]byte{}, ciphertext[:headerSize]...), ciphertext[headerSize+sealed:headerSize+2*sealed]...), ciphertext[headerSize:headerSize+sealed]...)
+	otherHeader := append([]byte{}, ciphertext...)
+	otherHeader[headerSize-1] ^= 1
+	otherKey, err := NewDataKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		key        []byte
+		ciphertext []byte
+	}{
+		{name: "Altered chunk", key: key, ciphertext: flipped},
+		{name: "Reordered chunks", key: key, ciphertext: reordered},
+		{name: "Truncated at a chunk", key: key, ciphertext: ciphertext[:headerSize+2*sealed]},
+		{name: "Truncated in a chunk", key: key, ciphertext: ciphertext[:headerSize+sealed+100]},
+		{name: "Without chunks", key: key, ciphertext: ciphertext[:headerSize]},
+		{name: "Altered header", key: key, ciphertext: otherHeader},
+		{name: "Wrong key", key: otherKey, ciphertext: ciphertext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decrypt(tt.key, tt.ciphertext)
+			require.ErrorIs(t, err, ErrCorrupt)
+		})
+	}
+
+	_, err = decrypt(key, []byte("hello world, not encrypted"))
+	require.ErrorIs(t, err, ErrInvalidFormat)
+	_, err = decrypt(key, []byte("HIVE"))
+	require.ErrorIs(t, err, ErrInvalidFormat)
+	_, err = NewWriter(io.Discard, []byte("short key"))
+	require.Error(t, err)
+}
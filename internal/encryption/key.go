@@ -0,0 +1,147 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	ModeMaster     = "master"     // the data key is wrapped by the master key of Hive.
+	ModePassphrase = "passphrase" // the data key is wrapped by a key derived from the passphrase of the uploader.
+
+	// the argon2id parameters passphrases are derived with, the second recommendation of RFC 9106
+	kdfTime    = 3
+	kdfMemory  = 64 << 10 // in KiB, 64MiB.
+	kdfThreads = 4
+	saltSize   = 16
+
+	maxKDFMemory = 1 << 20 // bounds the memory of the parameters of an envelope, in KiB, 1GiB.
+	maxKDFTime   = 16      // bounds the passes of the parameters of an envelope.
+)
+
+var (
+	// ErrWrongKey is returned when a data key cannot be unwrapped with the given passphrase or master key.
+	ErrWrongKey = errors.New("wrong passphrase or key")
+
+	// ErrInvalidEnvelope is returned when the wrapped data key of a file cannot be used.
+	ErrInvalidEnvelope = errors.New("invalid key envelope")
+)
+
+// Envelope holds the data key of a file, wrapped with AES-256-GCM by a master key or by a key derived from a
+// passphrase. The wrapped key is a random 12 byte nonce followed by the sealed data key.
+type Envelope struct {
+	Mode       string `json:"mode"`             // ModeMaster or ModePassphrase.
+	WrappedKey []byte `json:"wrapped_key"`      // the wrapped data key, base64 in JSON.
+	KeyID      string `json:"key_id,omitempty"` // identifies the master key, the hex of the first 4 bytes of its SHA-256.
+	KDF        *KDF   `json:"kdf,omitempty"`    // how the key of a passphrase is derived.
+}
+
+// KDF holds the argon2id parameters the key wrapping a data key is derived from a passphrase with.
+type KDF struct {
+	Algorithm string `json:"algorithm"` // always "argon2id".
+	Salt      []byte `json:"salt"`      // base64 in JSON.
+	Time      uint32 `json:"time"`
+	Memory    uint32 `json:"memory"` // in KiB.
+	Threads   uint8  `json:"threads"`
+}
+
+// ParseMasterKey decodes a base64 master key of KeySize bytes, as generated by "openssl rand -base64 32".
+func ParseMasterKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes encoded in base64", KeySize)
+	}
+	return key, nil
+}
+
+// WrapWithMasterKey wraps the data key with the master key.
+func WrapWithMasterKey(dataKey, master []byte) (Envelope, error) {
+	wrapped, err := wrap(master, dataKey)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{Mode: ModeMaster, WrappedKey: wrapped, KeyID: keyID(master)}, nil
+}
+
+// WrapWithPassphrase wraps the data key with a key derived from the passphrase and a random salt.
+func WrapWithPassphrase(dataKey []byte, passphrase string) (Envelope, error) {
+	kdf := &KDF{Algorithm: "argon2id", Salt: make([]byte, saltSize), Time: kdfTime, Memory: kdfMemory, Threads: kdfThreads}
+	if _, err := rand.Read(kdf.Salt); err != nil {
+		return Envelope{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	wrapped, err := wrap(kdf.derive(passphrase), dataKey)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{Mode: ModePassphrase, WrappedKey: wrapped, KDF: kdf}, nil
+}
+
+// UnwrapWithMasterKey unwraps the data key of an envelope of ModeMaster.
+func (e Envelope) UnwrapWithMasterKey(master []byte) ([]byte, error) {
+	if e.Mode != ModeMaster {
+		return nil, fmt.Errorf("%w: the data key is not wrapped by a master key", ErrInvalidEnvelope)
+	}
+	if e.KeyID != keyID(master) {
+		return nil, fmt.Errorf("%w: the data key is wrapped by master key %s", ErrWrongKey, e.KeyID)
+	}
+	return unwrap(master, e.WrappedKey)
+}
+
+// UnwrapWithPassphrase unwraps the data key of an envelope of ModePassphrase.
+func (e Envelope) UnwrapWithPassphrase(passphrase string) ([]byte, error) {
+	if e.Mode != ModePassphrase || e.KDF == nil {
+		return nil, fmt.Errorf("%w: the data key is not wrapped by a passphrase", ErrInvalidEnvelope)
+	}
+	k := e.KDF
+	if k.Algorithm != "argon2id" || k.Time == 0 || k.Time > maxKDFTime || k.Memory == 0 || k.Memory > maxKDFMemory || k.Threads == 0 {
+		return nil, fmt.Errorf("%w: unsupported key derivation", ErrInvalidEnvelope)
+	}
+	return unwrap(k.derive(passphrase), e.WrappedKey)
+}
+
+// derive derives the key wrapping a data key from the passphrase.
+func (k *KDF) derive(passphrase string) []byte {
+	return argon2.IDKey([]byte(passphrase), k.Salt, k.Time, k.Memory, k.Threads, KeySize)
+}
+
+// wrap seals the data key with kek under a random nonce, which it is prefixed with.
+func wrap(kek, dataKey []byte) ([]byte, error) {
+	aead, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+// unwrap opens a data key sealed by wrap.
+func unwrap(kek, wrapped []byte) ([]byte, error) {
+	aead, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: wrapped key is too short", ErrInvalidEnvelope)
+	}
+	dataKey, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return dataKey, nil
+}
+
+// keyID identifies a master key without revealing it.
+func keyID(master []byte) string {
+	sum := sha256.Sum256(master)
+	return hex.EncodeToString(sum[:4])
+}
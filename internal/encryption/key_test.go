@@ -0,0 +1,71 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterKey(t *testing.T) {
+	master, err := ParseMasterKey(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", KeySize))))
+	require.NoError(t, err)
+	dataKey, err := NewDataKey()
+	require.NoError(t, err)
+
+	e, err := WrapWithMasterKey(dataKey, master)
+	require.NoError(t, err)
+	require.Equal(t, ModeMaster, e.Mode)
+	require.Len(t, e.KeyID, 8)
+	require.Nil(t, e.KDF)
+
+	unwrapped, err := e.UnwrapWithMasterKey(master)
+	require.NoError(t, err)
+	require.Equal(t, dataKey, unwrapped)
+
+	other, err := NewDataKey()
+	require.NoError(t, err)
+	_, err = e.UnwrapWithMasterKey(other)
+	require.ErrorIs(t, err, ErrWrongKey)
+	_, err = e.UnwrapWithPassphrase("hunter2")
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	for _, s := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		_, err := ParseMasterKey(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	dataKey, err := NewDataKey()
+	require.NoError(t, err)
+
+	e, err := WrapWithPassphrase(dataKey, "correct horse battery staple")
+	require.NoError(t, err)
+	require.Equal(t, ModePassphrase, e.Mode)
+	require.Equal(t, &KDF{Algorithm: "argon2id", Salt: e.KDF.Salt, Time: 3, Memory: 64 << 10, Threads: 4}, e.KDF)
+	require.Len(t, e.KDF.Salt, saltSize)
+
+	// envelopes are kept as JSON
+	b, err := json.Marshal(e)
+	require.NoError(t, err)
+	var decoded Envelope
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, e, decoded)
+
+	unwrapped, err := decoded.UnwrapWithPassphrase("correct horse battery staple")
+	require.NoError(t, err)
+	require.Equal(t, dataKey, unwrapped)
+
+	_, err = decoded.UnwrapWithPassphrase("hunter2")
+	require.ErrorIs(t, err, ErrWrongKey)
+	_, err = decoded.UnwrapWithMasterKey(dataKey)
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	// the parameters of an envelope are bounded
+	decoded.KDF.Memory = 4 << 20
+	_, err = decoded.UnwrapWithPassphrase("correct horse battery staple")
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+}
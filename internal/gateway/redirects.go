@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// RedirectsFile is the name of the file holding the redirect rules of a website, at the root of its CID.
+	RedirectsFile = "_redirects"
+
+	// MaxRedirectsSize bounds the size of a _redirects file, 64KiB as the gateway specification requires.
+	MaxRedirectsSize = 64 << 10
+)
+
+// ErrInvalidRedirects is returned when a _redirects file cannot be parsed.
+var ErrInvalidRedirects = errors.New("invalid _redirects file")
+
+// redirectStatuses are the status codes a redirect rule may have.
+var redirectStatuses = map[int]bool{
+	200: true, // a rewrite, serving the target in place of the missing path.
+	301: true,
+	302: true,
+	303: true,
+	307: true,
+	308: true,
+	404: true, // serves the target, a custom 404 page.
+	410: true,
+	451: true,
+}
+
+// Rule is a rule of a _redirects file, redirecting the requests for a missing path that match From to To.
+type Rule struct {
+	From   string // the path matched, with :placeholder segments and an optional trailing * splat.
+	To     string // the path or URL redirected to, where :placeholder and :splat are substituted.
+	Status int    // the status code of the response, 301 by default.
+}
+
+// ParseRedirects parses a _redirects file, one "from to [status]" rule per line. Blank lines and lines starting
+// with # are ignored.
+func ParseRedirects(data []byte) ([]Rule, error) {
+	if len(data) > MaxRedirectsSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidRedirects, MaxRedirectsSize)
+	}
+
+	var rules []Rule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("%w: line %d: expected \"from to [status]\"", ErrInvalidRedirects, line)
+		}
+		rule := Rule{From: fields[0], To: fields[1], Status: 301}
+		if !strings.HasPrefix(rule.From, "/") {
+			return nil, fmt.Errorf("%w: line %d: %q must start with /", ErrInvalidRedirects, line, rule.From)
+		}
+		if i := strings.IndexByte(rule.From, '*'); i >= 0 && i != len(rule.From)-1 {
+			return nil, fmt.Errorf("%w: line %d: * must be at the end of %q", ErrInvalidRedirects, line, rule.From)
+		}
+		if !strings.HasPrefix(rule.To, "/") {
+			if u, err := url.Parse(rule.To); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return nil, fmt.Errorf("%w: line %d: %q must be a path or an http(s) URL", ErrInvalidRedirects, line, rule.To)
+			}
+		}
+		if len(fields) == 3 {
+			status, err := strconv.Atoi(fields[2])
+			if err != nil || !redirectStatuses[status] {
+				return nil, fmt.Errorf("%w: line %d: unsupported status %q", ErrInvalidRedirects, line, fields[2])
+			}
+			rule.Status = status
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRedirects, err)
+	}
+	return rules, nil
+}
+
+// Match returns the target and status of the first rule matching the given path, with its placeholders
+// substituted, or false if no rule matches.
+func Match(rules []Rule, path string) (string, int, bool) {
+	for _, rule := range rules {
+		values, ok := match(rule.From, path)
+		if !ok {
+			continue
+		}
+
+		// longer names first, so that :splat is not taken for a placeholder named :s
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+		oldnew := make([]string, 0, 2*len(names))
+		for _, name := range names {
+			oldnew = append(oldnew, ":"+name, values[name])
+		}
+		return strings.NewReplacer(oldnew...).Replace(rule.To), rule.Status, true
+	}
+	return "", 0, false
+}
+
+// match matches a path against the From of a rule, returning the values of its placeholders and splat.
+func match(from, path string) (map[string]string, bool) {
+	prefix, splat := strings.CutSuffix(from, "*")
+	patterns, parts := segments(prefix), segments(path)
+	values := map[string]string{}
+	if splat {
+		// "/a/*" matches "/a" and anything below it
+		if len(parts) < len(patterns) {
+			return nil, false
+		}
+		values["splat"] = strings.Join(parts[len(patterns):], "/")
+		parts = parts[:len(patterns)]
+	}
+	if len(parts) != len(patterns) {
+		return nil, false
+	}
+
+	for i, pattern := range patterns {
+		if name, ok := strings.CutPrefix(pattern, ":"); ok && name != "" {
+			values[name] = parts[i]
+		} else if pattern != parts[i] {
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// segments splits a path into its segments, ignoring leading and trailing slashes.
+func segments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRedirects(t *testing.T) {
+	rules, err := ParseRedirects([]byte(`
+# comments and blank lines are ignored
+
+/home              /index.html        200
+/blog/:year/:slug  /posts/:year-:slug.html
+/docs/*            https://docs.example.com/:splat  302
+/*                 /404.html          404
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Rule{
+		{From: "/home", To: "/index.html", Status: 200},
+		{From: "/blog/:year/:slug", To: "/posts/:year-:slug.html", Status: 301},
+		{From: "/docs/*", To: "https://docs.example.com/:splat", Status: 302},
+		{From: "/*", To: "/404.html", Status: 404},
+	}, rules)
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "Missing target", data: "/home"},
+		{name: "Too many fields", data: "/home /index.html 200 extra"},
+		{name: "Relative source", data: "home /index.html"},
+		{name: "Splat in the middle", data: "/a/*/b /b"},
+		{name: "Relative target", data: "/home index.html"},
+		{name: "Unsafe target", data: "/home javascript:alert(1)"},
+		{name: "Unsupported status", data: "/home /index.html 418"},
+		{name: "Invalid status", data: "/home /index.html moved"},
+		{name: "Too large", data: strings.Repeat("#", MaxRedirectsSize+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRedirects([]byte(tt.data))
+			require.ErrorIs(t, err, ErrInvalidRedirects)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules := []Rule{
+		{From: "/home", To: "/index.html", Status: 200},
+		{From: "/blog/:year/:slug", To: "/posts/:year-:slug.html", Status: 301},
+		{From: "/docs/*", To: "https://docs.example.com/:splat", Status: 302},
+		{From: "/s/:s/*", To: "/:s/:splat", Status: 301},
+		{From: "/*", To: "/404.html", Status: 404},
+	}
+
+	tests := []struct {
+		path   string
+		to     string
+		status int
+	}{
+		{path: "/home", to: "/index.html", status: 200},
+		{path: "/home/", to: "/index.html", status: 200},
+		{path: "/blog/2024/hello", to: "/posts/2024-hello.html", status: 301},
+		{path: "/docs", to: "https://docs.example.com/", status: 302},
+		{path: "/docs/api/v1", to: "https://docs.example.com/api/v1", status: 302},
+		{path: "/s/x/y/z", to: "/x/y/z", status: 301},
+		{path: "/blog/2024", to: "/404.html", status: 404},
+		{path: "/homepage", to: "/404.html", status: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			to, status, ok := Match(rules, tt.path)
+			require.True(t, ok)
+			require.Equal(t, tt.to, to)
+			require.Equal(t, tt.status, status)
+		})
+	}
+
+	_, _, ok := Match(rules[:2], "/about")
+	require.False(t, ok)
+}
@@ -0,0 +1,230 @@
+package gc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/store"
+)
+
+const (
+	bucket     = "gc_runs" // the store bucket holding the garbage collection runs, keyed in time order.
+	maxHistory = 100       // the number of garbage collection runs kept in the history.
+)
+
+// ErrRunning is returned when a garbage collection is requested while another one is in progress.
+var ErrRunning = errors.New("garbage collection already running")
+
+// Trigger is what started a garbage collection run.
+type Trigger string
+
+const (
+	TriggerManual    Trigger = "manual"     // requested through the API.
+	TriggerSchedule  Trigger = "schedule"   // started by the GC schedule.
+	TriggerFreeSpace Trigger = "free-space" // started because the repository ran low on free space.
+)
+
+// Run is the report of a garbage collection run.
+type Run struct {
+	Trigger        Trigger   `json:"trigger"`         // what started the run.
+	StartedAt      time.Time `json:"started_at"`      // when the run started.
+	FinishedAt     time.Time `json:"finished_at"`     // when the run finished.
+	Removed        int       `json:"removed"`         // the number of blocks removed.
+	BytesReclaimed uint64    `json:"bytes_reclaimed"` // the decrease of the repository size.
+	Error          string    `json:"error,omitempty"` // why the run failed.
+}
+
+// Collector runs garbage collections on the IPFS repository, one at a time, and keeps a history of them in the
+// store.
+type Collector struct {
+	ipfs    ipfs.Client
+	store   *store.Store
+	running sync.Mutex // held while a garbage collection runs.
+	lastRun int64      // the key of the last run recorded, in Unix nanoseconds. Guarded by running.
+}
+
+// NewCollector creates a new Collector for the repository of the given IPFS node, keeping its history in store.
+func NewCollector(client ipfs.Client, store *store.Store) *Collector {
+	return &Collector{
+		ipfs:  client,
+		store: store,
+	}
+}
+
+// Run performs a garbage collection, calling fn with the CID of every block it removes, and records it in the
+// history. It returns ErrRunning without collecting if another garbage collection is in progress.
+func (c *Collector) Run(ctx context.Context, trigger Trigger, fn func(cid string) error) (Run, error) {
+	if !c.running.TryLock() {
+		return Run{}, ErrRunning
+	}
+	defer c.running.Unlock()
+
+	run := Run{Trigger: trigger, StartedAt: time.Now()}
+	err := c.collect(ctx, &run, fn)
+	run.FinishedAt = time.Now()
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	if err := c.record(run); err != nil {
+		log.Printf("gc: failed to record the %s garbage collection: %v", trigger, err)
+	}
+	return run, err
+}
+
+// record records a run in the history, dropping the oldest runs beyond the last 100. The running lock must be
+// held.
+func (c *Collector) record(run Run) error {
+	// the keys sort in time order, and runs started in the same nanosecond are kept apart
+	key := run.StartedAt.UnixNano()
+	if key <= c.lastRun {
+		key = c.lastRun + 1
+	}
+	c.lastRun = key
+	if err := c.store.Put(bucket, fmt.Sprintf("%020d", key), run); err != nil {
+		return err
+	}
+
+	var keys []string
+	err := c.store.ForEach(bucket, func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for len(keys) > maxHistory {
+		if err := c.store.Delete(bucket, keys[0]); err != nil {
+			return err
+		}
+		keys = keys[1:]
+	}
+	return nil
+}
+
+// collect performs the garbage collection of a run and measures the space it reclaimed.
+func (c *Collector) collect(ctx context.Context, run *Run, fn func(cid string) error) error {
+	before, err := c.ipfs.RepoStat(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.ipfs.GarbageCollect(ctx, func(cid string) error {
+		run.Removed++
+		if fn == nil {
+			return nil
+		}
+		return fn(cid)
+	})
+	if err != nil {
+		return err
+	}
+
+	after, err := c.ipfs.RepoStat(ctx)
+	if err != nil {
+		return err
+	}
+	if after.RepoSize < before.RepoSize {
+		run.BytesReclaimed = before.RepoSize - after.RepoSize
+	}
+	return nil
+}
+
+// History returns the most recent garbage collection runs, newest first.
+func (c *Collector) History() ([]Run, error) {
+	runs := []Run{}
+	err := c.store.ForEach(bucket, func(_ string, value []byte) error {
+		var run Run
+		if err := json.Unmarshal(value, &run); err != nil {
+			return err
+		}
+		runs = append(runs, run)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	return runs, nil
+}
+
+// Start runs garbage collections in the background until ctx is done: on the given cron schedule if it is
+// not empty, and whenever a check every interval finds less than minFree bytes left before the repository
+// reaches its StorageMax, if minFree is not zero.
+func (c *Collector) Start(ctx context.Context, schedule string, minFree uint64, interval time.Duration) error {
+	if schedule != "" {
+		scheduler := cron.New()
+		_, err := scheduler.AddFunc(schedule, func() {
+			c.runInBackground(ctx, TriggerSchedule)
+		})
+		if err != nil {
+			return fmt.Errorf("invalid gc schedule %q: %w", schedule, err)
+		}
+
+		scheduler.Start()
+		go func() {
+			<-ctx.Done()
+			scheduler.Stop()
+		}()
+	}
+
+	if minFree > 0 {
+		if interval <= 0 {
+			return fmt.Errorf("gc check interval must be positive, was %s", interval)
+		}
+		go c.watchFreeSpace(ctx, minFree, interval)
+	}
+	return nil
+}
+
+// watchFreeSpace runs a garbage collection whenever the repository has less than minFree bytes left before
+// it reaches its StorageMax, checking every interval until ctx is done.
+func (c *Collector) watchFreeSpace(ctx context.Context, minFree uint64, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stat, err := c.ipfs.RepoStat(ctx)
+		if err != nil {
+			log.Printf("gc: failed to check repository free space: %v", err)
+			continue
+		}
+
+		var free uint64
+		if stat.StorageMax > stat.RepoSize {
+			free = stat.StorageMax - stat.RepoSize
+		}
+		if free < minFree {
+			c.runInBackground(ctx, TriggerFreeSpace)
+		}
+	}
+}
+
+// runInBackground performs a garbage collection started by the collector itself, logging its outcome.
+func (c *Collector) runInBackground(ctx context.Context, trigger Trigger) {
+	run, err := c.Run(ctx, trigger, nil)
+	switch {
+	case errors.Is(err, ErrRunning):
+		return
+	case err != nil:
+		log.Printf("gc: %s garbage collection failed: %v", trigger, err)
+	default:
+		log.Printf("gc: %s garbage collection removed %d blocks, reclaimed %d bytes", trigger, run.Removed, run.BytesReclaimed)
+	}
+}
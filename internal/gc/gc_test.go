@@ -0,0 +1,188 @@
+package gc
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"github.com/zde37/Hive/internal/store"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestCollector(t *testing.T, client ipfs.Client) *Collector {
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return NewCollector(client, st)
+}
+
+// removeBlocks returns a mock GarbageCollect implementation removing the given blocks.
+func removeBlocks(cids ...string) func(context.Context, func(string) error) error {
+	return func(_ context.Context, fn func(string) error) error {
+		for _, cid := range cids {
+			if err := fn(cid); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name            string
+		setupMock       func(mockClient *mocked.MockClient)
+		expectedRemoved []string
+		expectedRun     Run
+		wantErr         bool
+	}{
+		{
+			name: "Blocks removed",
+			setupMock: func(mockClient *mocked.MockClient) {
+				gomock.InOrder(
+					mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 1000}, nil),
+					mockClient.EXPECT().GarbageCollect(gomock.Any(), gomock.Any()).DoAndReturn(removeBlocks("bafya", "bafyb")),
+					mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 400}, nil),
+				)
+			},
+			expectedRemoved: []string{"bafya", "bafyb"},
+			expectedRun:     Run{Trigger: TriggerManual, Removed: 2, BytesReclaimed: 600},
+		},
+		{
+			name: "Repository grew during the run",
+			setupMock: func(mockClient *mocked.MockClient) {
+				gomock.InOrder(
+					mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 1000}, nil),
+					mockClient.EXPECT().GarbageCollect(gomock.Any(), gomock.Any()).DoAndReturn(removeBlocks()),
+					mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 1200}, nil),
+				)
+			},
+			expectedRun: Run{Trigger: TriggerManual},
+		},
+		{
+			name: "Garbage collection fails",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 1000}, nil)
+				mockClient.EXPECT().GarbageCollect(gomock.Any(), gomock.Any()).Return(errors.New("repo locked"))
+			},
+			expectedRun: Run{Trigger: TriggerManual, Error: "repo locked"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocked.NewMockClient(gomock.NewController(t))
+			tt.setupMock(mockClient)
+			collector := newTestCollector(t, mockClient)
+
+			var removed []string
+			run, err := collector.Run(context.Background(), TriggerManual, func(cid string) error {
+				removed = append(removed, cid)
+				return nil
+			})
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.expectedRemoved, removed)
+
+			require.False(t, run.StartedAt.IsZero())
+			require.False(t, run.FinishedAt.Before(run.StartedAt))
+			run.StartedAt, run.FinishedAt = time.Time{}, time.Time{}
+			require.Equal(t, tt.expectedRun, run)
+
+			history, err := collector.History()
+			require.NoError(t, err)
+			require.Len(t, history, 1)
+			require.Equal(t, tt.expectedRun.Error, history[0].Error)
+		})
+	}
+}
+
+func TestRunWhileRunning(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	collector := newTestCollector(t, mockClient)
+
+	collector.running.Lock()
+	_, err := collector.Run(context.Background(), TriggerManual, nil)
+	collector.running.Unlock()
+
+	require.ErrorIs(t, err, ErrRunning)
+	history, err := collector.History()
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
+
+func TestHistory(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{}, nil).AnyTimes()
+	mockClient.EXPECT().GarbageCollect(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	collector := newTestCollector(t, mockClient)
+
+	for i := 0; i < maxHistory+5; i++ {
+		_, err := collector.Run(context.Background(), TriggerSchedule, nil)
+		require.NoError(t, err)
+	}
+	_, err := collector.Run(context.Background(), TriggerManual, nil)
+	require.NoError(t, err)
+
+	history, err := collector.History()
+	require.NoError(t, err)
+	require.Len(t, history, maxHistory)
+	require.Equal(t, TriggerManual, history[0].Trigger)
+	require.Equal(t, TriggerSchedule, history[1].Trigger)
+
+	// the history is kept in the store across restarts
+	history, err = NewCollector(mockClient, collector.store).History()
+	require.NoError(t, err)
+	require.Len(t, history, maxHistory)
+	require.Equal(t, TriggerManual, history[0].Trigger)
+}
+
+func TestStartFreeSpaceTrigger(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	collected := make(chan struct{})
+
+	// the first check finds enough space, the second one triggers a collection
+	gomock.InOrder(
+		mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 100, StorageMax: 1000}, nil),
+		mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 950, StorageMax: 1000}, nil),
+		mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 950, StorageMax: 1000}, nil),
+		mockClient.EXPECT().GarbageCollect(gomock.Any(), gomock.Any()).DoAndReturn(removeBlocks("bafya")),
+		mockClient.EXPECT().RepoStat(gomock.Any()).DoAndReturn(func(context.Context) (ipfs.RepoStat, error) {
+			close(collected)
+			return ipfs.RepoStat{RepoSize: 500, StorageMax: 1000}, nil
+		}),
+	)
+	mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 500, StorageMax: 1000}, nil).AnyTimes()
+
+	collector := newTestCollector(t, mockClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, collector.Start(ctx, "", 100, 10*time.Millisecond))
+
+	select {
+	case <-collected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("free space trigger did not run a garbage collection")
+	}
+
+	require.Eventually(t, func() bool {
+		history, err := collector.History()
+		return err == nil && len(history) == 1 && history[0].Trigger == TriggerFreeSpace && history[0].BytesReclaimed == 450
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStartInvalidConfig(t *testing.T) {
+	collector := newTestCollector(t, mocked.NewMockClient(gomock.NewController(t)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.Error(t, collector.Start(ctx, "not a schedule", 0, 0))
+	require.Error(t, collector.Start(ctx, "", 100, 0))
+	require.NoError(t, collector.Start(ctx, "@daily", 0, 0))
+}
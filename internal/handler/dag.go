@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+const maxDagNodeSize = 1024 * 1024 // 1MB in bytes, the largest block the IPFS node accepts by default
+
+// dagCodecs maps the IPLD codecs accepted by the DAG endpoints to the content type they are served with.
+var dagCodecs = map[string]string{
+	"dag-json": "application/json",
+	"dag-cbor": "application/cbor",
+	"json":     "application/json",
+	"cbor":     "application/cbor",
+}
+
+// dagCodec returns the named query parameter of the request, falling back to def when it is not set,
+// and an error if it is not a supported codec.
+func dagCodec(r *http.Request, name, def string) (string, error) {
+	codec := r.URL.Query().Get(name)
+	if codec == "" {
+		codec = def
+	}
+	if _, ok := dagCodecs[codec]; !ok {
+		return "", NewErrorStatus(fmt.Errorf("unsupported %s %q", name, codec), http.StatusBadRequest, 0)
+	}
+	return codec, nil
+}
+
+// dagErrorStatus maps an error returned by a DAG operation to an ErrorStatus.
+func dagErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, ipfs.ErrInvalidPath):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	case errors.Is(err, ipfs.ErrPathNotFound):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
+
+// dagPut handles a request to store the IPLD node in the request body. The body is decoded with the
+// "input-codec" query parameter (dag-json by default) and stored with "store-codec" (dag-cbor by default).
+func (h *handlerImpl) DagPut(w http.ResponseWriter, r *http.Request) error {
+	inputCodec, err := dagCodec(r, "input-codec", "dag-json")
+	if err != nil {
+		return err
+	}
+	storeCodec, err := dagCodec(r, "store-codec", "dag-cbor")
+	if err != nil {
+		return err
+	}
+
+	pin := false
+	if v := r.URL.Query().Get("pin"); v != "" {
+		if pin, err = strconv.ParseBool(v); err != nil {
+			return NewErrorStatus(fmt.Errorf("pin must be a boolean"), http.StatusBadRequest, 0)
+		}
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDagNodeSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return NewErrorStatus(fmt.Errorf("node size exceeds the maximum limit of 1MB"), http.StatusRequestEntityTooLarge, 0)
+		}
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	}
+	if len(data) == 0 {
+		return NewErrorStatus(fmt.Errorf("request body is required"), http.StatusBadRequest, 0)
+	}
+
+	cid, err := h.ipfs.DagPut(r.Context(), bytes.NewReader(data), inputCodec, storeCodec, pin)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Cid string `json:"cid"`
+	}{
+		Cid: cid,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// dagGet handles a request for the IPLD node at the given path, encoded with the "output-codec" query
+// parameter (dag-json by default).
+func (h *handlerImpl) DagGet(w http.ResponseWriter, r *http.Request) error {
+	dagPath := r.PathValue("path")
+	if dagPath == "" {
+		return NewErrorStatus(fmt.Errorf("path is required"), http.StatusBadRequest, 0)
+	}
+
+	outputCodec, err := dagCodec(r, "output-codec", "dag-json")
+	if err != nil {
+		return err
+	}
+	if err := h.checkResolvedDenylist(r, "download", "/ipfs/"+dagPath, dagErrorStatus); err != nil {
+		return err
+	}
+
+	data, err := h.ipfs.DagGet(r.Context(), dagPath, outputCodec)
+	if err != nil {
+		return dagErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", dagCodecs[outputCodec])
+	_, err = w.Write(data)
+	return err
+}
+
+// dagStat handles a request for the size and block count of the DAG rooted at the given CID.
+func (h *handlerImpl) DagStat(w http.ResponseWriter, r *http.Request) error {
+	cid := r.PathValue("cid")
+	if cid == "" {
+		return NewErrorStatus(fmt.Errorf("cid is required"), http.StatusBadRequest, 0)
+	}
+
+	stat, err := h.ipfs.DagStat(r.Context(), cid)
+	if err != nil {
+		return dagErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(stat)
+}
+
+// dagResolve handles a request to resolve an IPLD path to the block it ends in.
+func (h *handlerImpl) DagResolve(w http.ResponseWriter, r *http.Request) error {
+	dagPath := r.PathValue("path")
+	if dagPath == "" {
+		return NewErrorStatus(fmt.Errorf("path is required"), http.StatusBadRequest, 0)
+	}
+
+	if err := h.checkDenylist(r, "download", "/ipfs/"+dagPath); err != nil {
+		return err
+	}
+	res, err := h.ipfs.DagResolve(r.Context(), dagPath)
+	if err != nil {
+		return dagErrorStatus(err)
+	}
+	if err := h.checkDenylist(r, "download", "/ipfs/"+res.Cid); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(res)
+}
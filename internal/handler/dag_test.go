@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDagRoutes(t *testing.T) {
+	tests := []struct {
+		name                string
+		method              string
+		target              string
+		body                string
+		setupMock           func(mockClient *mocked.MockClient)
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:   "Put with default codecs",
+			method: http.MethodPost,
+			target: "/v1/dag",
+			body:   `{"name":"hive"}`,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().DagPut(gomock.Any(), gomock.Any(), "dag-json", "dag-cbor", false).Return("bafynode", nil)
+			},
+			expectedStatus:      http.StatusCreated,
+			expectedContentType: "application/json",
+			expectedBody:        `{"cid":"bafynode"}`,
+		},
+		{
+			name:   "Put with selected codecs and pin",
+			method: http.MethodPost,
+			target: "/v1/dag?input-codec=json&store-codec=dag-json&pin=true",
+			body:   `{"name":"hive"}`,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().DagPut(gomock.Any(), gomock.Any(), "json", "dag-json", true).Return("bafynode", nil)
+			},
+			expectedStatus:      http.StatusCreated,
+			expectedContentType: "application/json",
+			expectedBody:        `{"cid":"bafynode"}`,
+		},
+		{
+			name:                "Put with unsupported codec",
+			method:              http.MethodPost,
+			target:              "/v1/dag?store-codec=git-raw",
+			body:                `{}`,
+			setupMock:           func(mockClient *mocked.MockClient) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"unsupported store-codec \"git-raw\""}`,
+		},
+		{
+			name:                "Put with empty body",
+			method:              http.MethodPost,
+			target:              "/v1/dag",
+			setupMock:           func(mockClient *mocked.MockClient) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"request body is required"}`,
+		},
+		{
+			name:   "Get with path traversal",
+			method: http.MethodGet,
+			target: "/v1/dag/bafynode/author/name",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().DagGet(gomock.Any(), "bafynode/author/name", "dag-json").Return([]byte(`"zde"`), nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `"zde"`,
+		},
+		{
+			name:   "Get as dag-cbor",
+			method: http.MethodGet,
+			target: "/v1/dag/bafynode?output-codec=dag-cbor",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().DagGet(gomock.Any(), "bafynode", "dag-cbor").Return([]byte{0xa0}, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/cbor",
+			expectedBody:        "\xa0",
+		},
+		{
+			name:   "Get with invalid path",
+			method: http.MethodGet,
+			target: "/v1/dag/not-a-cid",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().DagGet(gomock.Any(), "not-a-cid", "dag-json").Return(nil, fmt.Errorf("%w: bad cid", ipfs.ErrInvalidPath))
+			},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"invalid path: bad cid"}`,
+		},
+		{
+			name:   "Stat",
+			method: http.MethodGet,
+			target: "/v1/dag/stat/bafynode",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().DagStat(gomock.Any(), "bafynode").Return(ipfs.DagStat{Cid: "bafynode", Size: 42, NumBlocks: 1}, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `{"cid":"bafynode","size":42,"num_blocks":1}`,
+		},
+		{
+			name:   "Resolve",
+			method: http.MethodGet,
+			target: "/v1/dag/resolve/bafynode/author",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().DagResolve(gomock.Any(), "bafynode/author").Return(ipfs.DagResolveResult{Cid: "bafyauthor"}, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `{"cid":"bafyauthor","rem_path":""}`,
+		},
+		{
+			name:   "Resolve error",
+			method: http.MethodGet,
+			target: "/v1/dag/resolve/bafynode/missing",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().DagResolve(gomock.Any(), "bafynode/missing").Return(ipfs.DagResolveResult{}, errors.New("no such link"))
+			},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"no such link"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
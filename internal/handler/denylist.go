@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zde37/Hive/internal/denylist"
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+const (
+	defaultAuditLimit = 100  // the number of blocked attempts listed by default.
+	maxAuditLimit     = 1000 // the largest number of blocked attempts listed at once.
+)
+
+// denylistErrorStatus maps an error from the denylist to its HTTP error status.
+func denylistErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, denylist.ErrInvalidRule):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	case errors.Is(err, denylist.ErrNotFound):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
+
+// checkDenylist fails with a 451 status if the content path is blocked by the denylist, recording the attempt
+// in its audit. Nothing is blocked without a denylist.
+func (h *handlerImpl) checkDenylist(r *http.Request, operation, contentPath string) error {
+	if h.denylist == nil {
+		return nil
+	}
+	e, blocked := h.denylist.Check(contentPath)
+	if !blocked {
+		return nil
+	}
+
+	log.Printf("denylist: blocked %s of %s by %q from %s", operation, contentPath, e.Rule, r.RemoteAddr)
+	err := h.denylist.Record(denylist.Attempt{
+		Time:       time.Now().UTC(),
+		Operation:  operation,
+		Path:       contentPath,
+		Rule:       e.Rule,
+		Reason:     e.Reason,
+		User:       h.user(r),
+		RemoteAddr: r.RemoteAddr,
+	})
+	if err != nil {
+		log.Printf("denylist: failed to record a blocked attempt: %v", err)
+	}
+	return NewErrorStatus(fmt.Errorf("%w: %s", denylist.ErrBlocked, contentPath), http.StatusUnavailableForLegalReasons, 0)
+}
+
+// checkResolvedDenylist is checkDenylist for a content path and for the CID it resolves to, so that blocked
+// content cannot be reached under a parent path. Paths below their root are only resolved while the denylist
+// blocks something, and those that do not resolve are left to the caller, which cannot serve them either.
+// errorStatus maps the errors of the resolution.
+func (h *handlerImpl) checkResolvedDenylist(r *http.Request, operation, contentPath string, errorStatus func(error) error) error {
+	if err := h.checkDenylist(r, operation, contentPath); err != nil {
+		return err
+	}
+	if h.denylist == nil || h.denylist.Empty() {
+		return nil
+	}
+	_, rest, _ := strings.Cut(strings.TrimPrefix(contentPath, "/ipfs/"), "/")
+	if strings.Trim(rest, "/") == "" {
+		return nil
+	}
+
+	res, err := h.ipfs.DagResolve(r.Context(), contentPath)
+	if errors.Is(err, ipfs.ErrPathNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errorStatus(err)
+	}
+	return h.checkDenylist(r, operation, "/ipfs/"+res.Cid)
+}
+
+// unpinBlocked unpins blocked content that was pinned before its CID was known.
+func (h *handlerImpl) unpinBlocked(r *http.Request, cid string) {
+	if err := h.ipfs.DeleteFile(r.Context(), "/ipfs/"+cid); err != nil {
+		log.Printf("denylist: failed to unpin blocked %s: %v", cid, err)
+	}
+}
+
+// listDenylist handles a request to list the denylist rules added through the API, oldest first, and the status
+// of the denylist file.
+func (h *handlerImpl) ListDenylist(w http.ResponseWriter, r *http.Request) error {
+	entries, err := h.denylist.Entries()
+	if err != nil {
+		return denylistErrorStatus(err)
+	}
+
+	resp := struct {
+		Entries []denylist.Entry     `json:"entries"`
+		File    *denylist.FileStatus `json:"file,omitempty"`
+	}{
+		Entries: entries,
+		File:    h.denylist.File(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// addDenylistRule handles a request to add the "rule" form value to the denylist, blocking what it matches for
+// the "reason" form value.
+func (h *handlerImpl) AddDenylistRule(w http.ResponseWriter, r *http.Request) error {
+	rule := r.FormValue("rule")
+	if rule == "" {
+		return NewErrorStatus(fmt.Errorf("rule is required"), http.StatusBadRequest, 0)
+	}
+
+	e, err := h.denylist.Add(rule, r.FormValue("reason"))
+	if err != nil {
+		return denylistErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(e)
+}
+
+// removeDenylistRule handles a request to remove the "rule" query parameter from the rules added through the
+// API.
+func (h *handlerImpl) RemoveDenylistRule(w http.ResponseWriter, r *http.Request) error {
+	rule := r.URL.Query().Get("rule")
+	if rule == "" {
+		return NewErrorStatus(fmt.Errorf("rule is required"), http.StatusBadRequest, 0)
+	}
+	if err := h.denylist.Remove(rule); err != nil {
+		return denylistErrorStatus(err)
+	}
+	return writeSuccess(w, http.StatusOK)
+}
+
+// reloadDenylist handles a request to load the denylist file again, without waiting for its change to be
+// noticed.
+func (h *handlerImpl) ReloadDenylist(w http.ResponseWriter, r *http.Request) error {
+	if h.denylist.File() == nil {
+		return NewErrorStatus(fmt.Errorf("no denylist file configured"), http.StatusBadRequest, 0)
+	}
+	if err := h.denylist.Reload(); err != nil {
+		return denylistErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(h.denylist.File())
+}
+
+// getDenylistAudit handles a request to list the last attempts blocked by the denylist, newest first, up to the
+// "limit" query parameter.
+func (h *handlerImpl) GetDenylistAudit(w http.ResponseWriter, r *http.Request) error {
+	limit := defaultAuditLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxAuditLimit {
+			return NewErrorStatus(fmt.Errorf("limit must be between 1 and %d", maxAuditLimit), http.StatusBadRequest, 0)
+		}
+	}
+
+	attempts, err := h.denylist.Audit(limit)
+	if err != nil {
+		return denylistErrorStatus(err)
+	}
+
+	resp := struct {
+		Attempts []denylist.Attempt `json:"attempts"`
+	}{
+		Attempts: attempts,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/denylist"
+	"github.com/zde37/Hive/internal/ipfs"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDenylist(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	serve := func(r *http.Request, token string) *httptest.ResponseRecorder {
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	postForm := func(target string, form url.Values, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return serve(r, token)
+	}
+
+	// a share link created before its content is blocked
+	w := postForm("/v1/shares", url.Values{"cid": {testCid}}, testUserToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var s sharedFile
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+
+	w = postForm("/v1/denylist", url.Values{"rule": {"/ipfs/" + testCid}, "reason": {"abuse"}}, testAdminToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var e denylist.Entry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+	require.Equal(t, "/ipfs/"+testCid, e.Rule)
+	require.Equal(t, "abuse", e.Reason)
+	require.Equal(t, denylist.SourceAdmin, e.Source)
+
+	t.Run("Blocked", func(t *testing.T) {
+		blocked := `{"error":"content is blocked: /ipfs/` + testCid + `"}`
+		tests := []struct {
+			name     string
+			request  func() *httptest.ResponseRecorder
+			expected string
+		}{
+			{name: "Download", request: func() *httptest.ResponseRecorder {
+				return serve(httptest.NewRequest(http.MethodGet, "/v1/file?cid="+testCid, nil), testUserToken)
+			}, expected: blocked},
+			{name: "Preview", request: func() *httptest.ResponseRecorder {
+				return serve(httptest.NewRequest(http.MethodGet, "/v1/preview/"+testCid, nil), "")
+			}, expected: blocked},
+			{name: "Thumbnail", request: func() *httptest.ResponseRecorder {
+				return serve(httptest.NewRequest(http.MethodGet, "/v1/thumb/"+testCid, nil), "")
+			}, expected: blocked},
+			{name: "Pin", request: func() *httptest.ResponseRecorder {
+				return postForm("/v1/pin", url.Values{"cid": {testCid}, "name": {"report"}}, "")
+			}, expected: blocked},
+			{name: "Remote pin", request: func() *httptest.ResponseRecorder {
+				return postForm("/v1/remote/services/pinata/pins", url.Values{"cid": {testCid}}, "")
+			}, expected: blocked},
+			{name: "Pinning Service API", request: func() *httptest.ResponseRecorder {
+				r := httptest.NewRequest(http.MethodPost, "/v1/psa/pins", strings.NewReader(`{"cid":"`+testCid+`"}`))
+				return serve(r, testUserToken)
+			}, expected: `{"error":{"reason":"UNAVAILABLE_FOR_LEGAL_REASONS","details":"content is blocked: /ipfs/` + testCid + `"}}`},
+			{name: "Create share", request: func() *httptest.ResponseRecorder {
+				return postForm("/v1/shares", url.Values{"cid": {testCid}}, testUserToken)
+			}, expected: blocked},
+			{name: "Share link", request: func() *httptest.ResponseRecorder {
+				return serve(httptest.NewRequest(http.MethodGet, s.URL, nil), "")
+			}, expected: blocked},
+			{name: "DAG", request: func() *httptest.ResponseRecorder {
+				mockClient.EXPECT().DagResolve(gomock.Any(), "/ipfs/"+testNewCid+"/link").Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+				return serve(httptest.NewRequest(http.MethodGet, "/v1/dag/"+testNewCid+"/link", nil), "")
+			}, expected: blocked},
+			{name: "DAG resolve", request: func() *httptest.ResponseRecorder {
+				mockClient.EXPECT().DagResolve(gomock.Any(), testNewCid+"/link").Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+				return serve(httptest.NewRequest(http.MethodGet, "/v1/dag/resolve/"+testNewCid+"/link", nil), "")
+			}, expected: blocked},
+			{name: "CAR import", request: func() *httptest.ResponseRecorder {
+				roots := []ipfs.CarRoot{{Cid: testNewCid}, {Cid: testCid}}
+				mockClient.EXPECT().ImportCar(gomock.Any(), "backup", gomock.Any()).Return(ipfs.CarImportResult{Roots: roots}, nil)
+				mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+testCid).Return(nil)
+				return serve(httptest.NewRequest(http.MethodPost, "/v1/car?name=backup", strings.NewReader("car data")), "")
+			}, expected: blocked},
+			{name: "Upload", request: func() *httptest.ResponseRecorder {
+				var body bytes.Buffer
+				mw := multipart.NewWriter(&body)
+				require.NoError(t, mw.WriteField("name", "report"))
+				part, err := mw.CreateFormFile("file", "report.txt")
+				require.NoError(t, err)
+				_, err = part.Write([]byte("hello"))
+				require.NoError(t, err)
+				require.NoError(t, mw.Close())
+
+				mockClient.EXPECT().Add(gomock.Any(), "report", gomock.Any()).Return("/ipfs/"+testCid, testCid, nil)
+				mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+testCid).Return(nil)
+				r := httptest.NewRequest(http.MethodPost, "/v1/file", &body)
+				r.Header.Set("Content-Type", mw.FormDataContentType())
+				return serve(r, testUserToken)
+			}, expected: blocked},
+			{name: "Download under a parent", request: func() *httptest.ResponseRecorder {
+				mockClient.EXPECT().DagResolve(gomock.Any(), "/ipfs/"+testNewCid+"/report.pdf").Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+				return serve(httptest.NewRequest(http.MethodGet, "/v1/file?cid="+testNewCid+"/report.pdf", nil), testUserToken)
+			}, expected: blocked},
+			{name: "Gateway under a parent", request: func() *httptest.ResponseRecorder {
+				mockClient.EXPECT().DagResolve(gomock.Any(), "/ipfs/"+testNewCid+"/report.pdf").Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+				return serve(httptest.NewRequest(http.MethodGet, "/ipfs/"+testNewCid+"/report.pdf", nil), "")
+			}, expected: blocked},
+			{name: "Raw block under a parent", request: func() *httptest.ResponseRecorder {
+				mockClient.EXPECT().DagResolve(gomock.Any(), "/ipfs/"+testNewCid+"/report.pdf").Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+				return serve(httptest.NewRequest(http.MethodGet, "/ipfs/"+testNewCid+"/report.pdf?format=raw", nil), "")
+			}, expected: blocked},
+			{name: "CAR under a parent", request: func() *httptest.ResponseRecorder {
+				mockClient.EXPECT().DagResolve(gomock.Any(), "/ipfs/"+testNewCid+"/report.pdf").Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+				return serve(httptest.NewRequest(http.MethodGet, "/ipfs/"+testNewCid+"/report.pdf?format=car", nil), "")
+			}, expected: blocked},
+			{name: "Gateway", request: func() *httptest.ResponseRecorder {
+				return serve(httptest.NewRequest(http.MethodGet, "/ipfs/"+testCid+"/a.txt", nil), "")
+			}, expected: `{"error":"content is blocked: /ipfs/` + testCid + `/a.txt"}`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := tt.request()
+				require.Equal(t, http.StatusUnavailableForLegalReasons, w.Code)
+				require.Equal(t, tt.expected, strings.TrimSpace(w.Body.String()))
+			})
+		}
+
+		// an IPNS name pointing to blocked content
+		mockClient.EXPECT().ResolveName(gomock.Any(), "example.com").Return("/ipfs/"+testCid, nil)
+		w := serve(httptest.NewRequest(http.MethodGet, "/ipns/example.com/", nil), "")
+		require.Equal(t, http.StatusUnavailableForLegalReasons, w.Code)
+
+		// other content is not blocked
+		mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testNewCid).Return(newTestFile("hello"), nil)
+		w = serve(httptest.NewRequest(http.MethodGet, "/v1/preview/"+testNewCid, nil), "")
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Audit", func(t *testing.T) {
+		w := serve(httptest.NewRequest(http.MethodGet, "/v1/denylist/audit?limit=2", nil), testAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Attempts []denylist.Attempt `json:"attempts"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Attempts, 2)
+		require.Equal(t, "gateway", resp.Attempts[0].Operation)
+		require.Equal(t, "/ipfs/"+testCid, resp.Attempts[0].Path)
+		require.Equal(t, "/ipfs/"+testCid, resp.Attempts[0].Rule)
+		require.Equal(t, "abuse", resp.Attempts[0].Reason)
+		require.NotEmpty(t, resp.Attempts[0].RemoteAddr)
+
+		w = serve(httptest.NewRequest(http.MethodGet, "/v1/denylist/audit", nil), testAdminToken)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Attempts, 18)
+		require.Equal(t, "download", resp.Attempts[len(resp.Attempts)-1].Operation)
+		require.Equal(t, "alice", resp.Attempts[len(resp.Attempts)-1].User)
+	})
+
+	t.Run("List and remove", func(t *testing.T) {
+		w := serve(httptest.NewRequest(http.MethodGet, "/v1/denylist", nil), testAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Entries []denylist.Entry     `json:"entries"`
+			File    *denylist.FileStatus `json:"file"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Entries, 1)
+		require.Equal(t, e.Rule, resp.Entries[0].Rule)
+		require.Nil(t, resp.File)
+
+		target := "/v1/denylist?rule=" + url.QueryEscape("/ipfs/"+testCid)
+		w = serve(httptest.NewRequest(http.MethodDelete, target, nil), testAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		w = serve(httptest.NewRequest(http.MethodDelete, target, nil), testAdminToken)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testCid).Return(newTestFile("hello"), nil)
+		w = serve(httptest.NewRequest(http.MethodGet, "/v1/preview/"+testCid, nil), "")
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		w := postForm("/v1/denylist", url.Values{"rule": {"/ipfs/nonsense"}}, testAdminToken)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, `{"error":"invalid denylist rule: \"/ipfs/nonsense\" has an invalid cid"}`, strings.TrimSpace(w.Body.String()))
+
+		w = postForm("/v1/denylist", url.Values{}, testAdminToken)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = postForm("/v1/denylist/reload", url.Values{}, testAdminToken)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, `{"error":"no denylist file configured"}`, strings.TrimSpace(w.Body.String()))
+
+		w = serve(httptest.NewRequest(http.MethodGet, "/v1/denylist/audit?limit=0", nil), testAdminToken)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		// the denylist is administered with the admin token only
+		w = postForm("/v1/denylist", url.Values{"rule": {"/ipfs/" + testNewCid}}, testUserToken)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		w = serve(httptest.NewRequest(http.MethodGet, "/v1/denylist/audit", nil), "")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Served paths", func(t *testing.T) {
+		root := "/ipfs/" + testNewCid
+		for _, rule := range []string{root + "/bad.html", root + "/dir/index.html"} {
+			w := postForm("/v1/denylist", url.Values{"rule": {rule}}, testAdminToken)
+			require.Equal(t, http.StatusCreated, w.Code)
+		}
+
+		// a path rewritten to a blocked file by the _redirects file
+		mockClient.EXPECT().DagResolve(gomock.Any(), root+"/missing").Return(ipfs.DagResolveResult{}, ipfs.ErrPathNotFound)
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/missing").Return(nil, ipfs.ErrPathNotFound)
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/_redirects").Return(newTestFile("/missing /bad.html 200"), nil)
+		w := serve(httptest.NewRequest(http.MethodGet, root+"/missing", nil), "")
+		require.Equal(t, http.StatusUnavailableForLegalReasons, w.Code)
+		require.Equal(t, `{"error":"content is blocked: `+root+`/bad.html"}`, strings.TrimSpace(w.Body.String()))
+
+		// a directory whose index.html is blocked
+		mockClient.EXPECT().DagResolve(gomock.Any(), root+"/dir").Return(ipfs.DagResolveResult{Cid: testNewCid}, nil)
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/dir").Return(nil, ipfs.ErrNotFile)
+		w = serve(httptest.NewRequest(http.MethodGet, root+"/dir/", nil), "")
+		require.Equal(t, http.StatusUnavailableForLegalReasons, w.Code)
+		require.Equal(t, `{"error":"content is blocked: `+root+`/dir/index.html"}`, strings.TrimSpace(w.Body.String()))
+	})
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/zde37/Hive/internal/encryption"
+	"github.com/zde37/Hive/internal/metadata"
+)
+
+// passphraseHeader is the request header carrying the passphrase a file was encrypted with.
+const passphraseHeader = "X-Hive-Passphrase"
+
+// encryptUpload encrypts the upload read from src with a new data key, wrapped by the passphrase if it is not
+// empty and by the master key otherwise, into a temporary file. It returns the path of the file, which the
+// caller removes, and the envelope of the data key.
+func (h *handlerImpl) encryptUpload(src io.Reader, passphrase string) (string, *encryption.Envelope, error) {
+	dataKey, err := encryption.NewDataKey()
+	if err != nil {
+		return "", nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	var envelope encryption.Envelope
+	if passphrase != "" {
+		envelope, err = encryption.WrapWithPassphrase(dataKey, passphrase)
+	} else {
+		envelope, err = encryption.WrapWithMasterKey(dataKey, h.masterKey)
+	}
+	if err != nil {
+		return "", nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	f, err := os.CreateTemp("", "upload-*.enc")
+	if err != nil {
+		return "", nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	defer f.Close()
+
+	err = func() error {
+		w, err := encryption.NewWriter(f, dataKey)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, src); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		return f.Close()
+	}()
+	if err != nil {
+		os.Remove(f.Name())
+		return "", nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	return f.Name(), &envelope, nil
+}
+
+// decryptDownload decrypts the content of the CID if it was encrypted at upload, and the request is allowed to
+// decrypt it: with the passphrase it was encrypted with in the X-Hive-Passphrase header, or, for a file
+// encrypted with the master key, with the token of its uploader or the admin token. Other content is returned
+// as it is.
+func (h *handlerImpl) decryptDownload(r *http.Request, cid string, content []byte) ([]byte, error) {
+	if h.files == nil {
+		return content, nil
+	}
+	file, err := h.files.Get(cid)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return content, nil
+	}
+	if err != nil {
+		return nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	if file.Encryption == nil {
+		return content, nil
+	}
+
+	dataKey, err := h.unwrapDataKey(r, file)
+	if err != nil {
+		return nil, err
+	}
+	// the whole file is authenticated before any of it is served
+	dr, err := encryption.NewReader(bytes.NewReader(content), dataKey)
+	if err != nil {
+		return nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	plaintext, err := io.ReadAll(dr)
+	if err != nil {
+		return nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	return plaintext, nil
+}
+
+// isEncrypted reports whether the content of the CID was encrypted at upload.
+func (h *handlerImpl) isEncrypted(cid string) (bool, error) {
+	if h.files == nil {
+		return false, nil
+	}
+	file, err := h.files.Get(cid)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	return file.Encryption != nil, nil
+}
+
+// unwrapDataKey unwraps the data key of an encrypted file for the request.
+func (h *handlerImpl) unwrapDataKey(r *http.Request, file metadata.File) ([]byte, error) {
+	envelope := file.Encryption
+	switch envelope.Mode {
+	case encryption.ModePassphrase:
+		passphrase := r.Header.Get(passphraseHeader)
+		if passphrase == "" {
+			return nil, NewErrorStatus(fmt.Errorf("file is encrypted, its passphrase is required in the %s header", passphraseHeader), http.StatusUnauthorized, 0)
+		}
+		dataKey, err := envelope.UnwrapWithPassphrase(passphrase)
+		if errors.Is(err, encryption.ErrWrongKey) {
+			return nil, NewErrorStatus(err, http.StatusForbidden, 0)
+		}
+		if err != nil {
+			return nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+		return dataKey, nil
+	case encryption.ModeMaster:
+		if !h.isAdmin(r) && (file.Uploader == "" || h.user(r) != file.Uploader) {
+			return nil, NewErrorStatus(fmt.Errorf("file is encrypted, only its uploader may download it"), http.StatusForbidden, 0)
+		}
+		if h.masterKey == nil {
+			return nil, NewErrorStatus(fmt.Errorf("file is encrypted with a master key, but none is configured"), http.StatusInternalServerError, 1)
+		}
+		dataKey, err := envelope.UnwrapWithMasterKey(h.masterKey)
+		if err != nil {
+			return nil, NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+		return dataKey, nil
+	default:
+		return nil, NewErrorStatus(fmt.Errorf("%w: unknown mode %q", encryption.ErrInvalidEnvelope, envelope.Mode), http.StatusInternalServerError, 1)
+	}
+}
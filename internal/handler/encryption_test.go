@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/metadata"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEncryption(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	serve := func(r *http.Request, token string) *httptest.ResponseRecorder {
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	// upload uploads content with the given form values, returning the response and what was added to IPFS
+	upload := func(rootCid, content string, fields map[string]string, token string) (*httptest.ResponseRecorder, []byte) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("name", "notes"))
+		for k, v := range fields {
+			require.NoError(t, mw.WriteField(k, v))
+		}
+		part, err := mw.CreateFormFile("file", "notes.txt")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		var added []byte
+		mockClient.EXPECT().Add(gomock.Any(), "notes", gomock.Any()).DoAndReturn(func(_ context.Context, _, path string) (string, string, error) {
+			added, err = os.ReadFile(path)
+			return "/ipfs/" + rootCid, rootCid, err
+		}).MaxTimes(1)
+		r := httptest.NewRequest(http.MethodPost, "/v1/file", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		return serve(r, token), added
+	}
+	download := func(rootCid string, ciphertext []byte, token, passphrase string) *httptest.ResponseRecorder {
+		mockClient.EXPECT().DownloadFile(gomock.Any(), rootCid).Return(ciphertext, nil)
+		r := httptest.NewRequest(http.MethodGet, "/v1/file?cid="+rootCid, nil)
+		if passphrase != "" {
+			r.Header.Set(passphraseHeader, passphrase)
+		}
+		return serve(r, token)
+	}
+
+	const secret = "the launch is on tuesday"
+
+	t.Run("Master key", func(t *testing.T) {
+		w, ciphertext := upload(testCid, secret, map[string]string{"encrypt": "true"}, testUserToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.True(t, bytes.HasPrefix(ciphertext, []byte("HIVE")))
+		require.NotContains(t, string(ciphertext), secret)
+
+		w = serve(httptest.NewRequest(http.MethodGet, "/v1/files/"+testCid+"/metadata", nil), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var file metadata.File
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &file))
+		require.True(t, file.Encrypted)
+		require.Equal(t, int64(len(secret)), file.Size)
+
+		// the uploader and the admin download the plaintext
+		for _, token := range []string{testUserToken, testAdminToken} {
+			w = download(testCid, ciphertext, token, "")
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, secret, w.Body.String())
+			require.Equal(t, "attachment; filename=notes.txt", w.Header().Get("Content-Disposition"))
+		}
+
+		// nobody else does
+		for _, token := range []string{"bob-token", ""} {
+			w = download(testCid, ciphertext, token, "")
+			require.Equal(t, http.StatusForbidden, w.Code)
+		}
+
+		// altered content is not served
+		altered := append([]byte{}, ciphertext...)
+		altered[len(altered)-1] ^= 1
+		w = download(testCid, altered, testUserToken, "")
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Passphrase", func(t *testing.T) {
+		w, ciphertext := upload(testNewCid, secret, map[string]string{"passphrase": "correct horse"}, "")
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NotContains(t, string(ciphertext), secret)
+
+		w = download(testNewCid, ciphertext, "", "correct horse")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, secret, w.Body.String())
+
+		w = download(testNewCid, ciphertext, "", "")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		w = download(testNewCid, ciphertext, testAdminToken, "hunter2")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Equal(t, `{"error":"wrong passphrase or key"}`, strings.TrimSpace(w.Body.String()))
+	})
+
+	t.Run("No key material", func(t *testing.T) {
+		// the wrapped keys and KDF parameters are never served, so passphrases cannot be brute-forced offline
+		mockClient.EXPECT().ListPins(gomock.Any()).Return(map[string]any{"Keys": map[string]any{}}, nil)
+		responses := []*httptest.ResponseRecorder{
+			serve(httptest.NewRequest(http.MethodGet, "/v1/files/"+testCid+"/metadata", nil), ""),
+			serve(httptest.NewRequest(http.MethodGet, "/v1/files/"+testNewCid+"/metadata", nil), ""),
+			serve(httptest.NewRequest(http.MethodGet, "/v1/pins", nil), ""),
+		}
+		for _, w := range responses {
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Contains(t, w.Body.String(), `"encrypted":true`)
+			for _, field := range []string{"encryption", "wrapped_key", "key_id", "kdf", "salt"} {
+				require.NotContains(t, w.Body.String(), field)
+			}
+		}
+	})
+
+	t.Run("Not indexed", func(t *testing.T) {
+		// the text of encrypted files is not indexed
+		w := serve(httptest.NewRequest(http.MethodGet, "/v1/search?q=launch", nil), testUserToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"query":"launch","results":[]}`, w.Body.String())
+	})
+
+	t.Run("Not shared", func(t *testing.T) {
+		// share links would serve the ciphertext
+		for _, c := range []string{testCid, testNewCid} {
+			r := httptest.NewRequest(http.MethodPost, "/v1/shares", strings.NewReader(url.Values{"cid": {c}}.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := serve(r, testUserToken)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Equal(t, `{"error":"encrypted files cannot be shared"}`, strings.TrimSpace(w.Body.String()))
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		w, _ := upload(testPeerID, secret, map[string]string{"encrypt": "maybe"}, "")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,406 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/ipfs/go-cid"
+	iface "github.com/ipfs/kubo/core/coreiface"
+	"github.com/zde37/Hive/internal/gateway"
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+const (
+	ipfsCacheControl = "public, max-age=29030400, immutable" // /ipfs/ content never changes.
+	ipnsCacheControl = "public, max-age=60"                  // /ipns/ names are republished.
+
+	rawType = "application/vnd.ipld.raw"
+	carType = "application/vnd.ipld.car"
+)
+
+// dirPage lists the entries of a UnixFS directory without an index.html.
+var dirPage = template.Must(template.New("dir").Parse(`<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8" />
+    <title>Index of {{.Path}}</title>
+  </head>
+  <body style="font-family: sans-serif">
+    <h2>Index of {{.Path}}</h2>
+    <table>
+      <tr><th align="left">Name</th><th align="right">Size</th><th align="left">CID</th></tr>
+      {{if .Parent}}<tr><td><a href="..">..</a></td><td></td><td></td></tr>{{end}}
+      {{range .Entries}}<tr>
+        <td><a href="{{.Href}}">{{.Name}}</a></td>
+        <td align="right">{{.Size}}</td>
+        <td><code>{{.Cid}}</code></td>
+      </tr>{{end}}
+    </table>
+  </body>
+</html>
+`))
+
+// dirEntry is an entry of a directory listing.
+type dirEntry struct {
+	Name string
+	Href string
+	Size string
+	Cid  string
+}
+
+// gatewayErrorStatus maps an error from the gateway to its HTTP error status.
+func gatewayErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, ipfs.ErrInvalidPath):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	case errors.Is(err, ipfs.ErrPathNotFound):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
+
+// GatewayMux returns a mux serving only the gateway routes. Unlike Mux, it does not sandbox the content, which
+// must then be served in an origin of its own.
+func (h *handlerImpl) GatewayMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /ipfs/{path...}", timeoutErrorMiddleware(h.IPFSGateway, 0))
+	mux.Handle("GET /ipns/{path...}", timeoutErrorMiddleware(h.IPNSGateway, 0))
+	return mux
+}
+
+// ipfsGateway handles a request for /ipfs/{cid}/{path} as a path gateway does.
+func (h *handlerImpl) IPFSGateway(w http.ResponseWriter, r *http.Request) error {
+	root, rest, _ := strings.Cut(r.PathValue("path"), "/")
+	if _, err := cid.Decode(root); err != nil {
+		return NewErrorStatus(fmt.Errorf("invalid cid %q", root), http.StatusBadRequest, 0)
+	}
+	if err := h.checkDenylist(r, "gateway", "/ipfs/"+r.PathValue("path")); err != nil {
+		return err
+	}
+	return h.serveGateway(w, r, "/ipfs/"+root, "/ipfs/"+root, rest, ipfsCacheControl)
+}
+
+// ipnsGateway handles a request for /ipns/{name}/{path} as a path gateway does, where name is an IPNS name or
+// a DNSLink domain.
+func (h *handlerImpl) IPNSGateway(w http.ResponseWriter, r *http.Request) error {
+	name, rest, _ := strings.Cut(r.PathValue("path"), "/")
+	if err := h.checkDenylist(r, "gateway", "/ipns/"+r.PathValue("path")); err != nil {
+		return err
+	}
+	root, err := h.ipfs.ResolveName(r.Context(), name)
+	if errors.Is(err, ipfs.ErrInvalidPath) {
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	}
+	if err != nil {
+		// names that cannot be resolved are the fault of their publisher, not of Hive
+		return NewErrorStatus(err, http.StatusBadGateway, 0)
+	}
+	// the name may point to blocked content
+	if err := h.checkDenylist(r, "gateway", strings.TrimSuffix(root+"/"+rest, "/")); err != nil {
+		return err
+	}
+	return h.serveGateway(w, r, root, "/ipns/"+name, rest, ipnsCacheControl)
+}
+
+// serveGateway serves the rest path under the content path root, which is served at urlRoot. A raw block or a
+// CAR is served if one is asked for, with the "format" query parameter or the Accept header. Otherwise files are
+// served with the type of their extension, directories with their index.html or a listing, and missing paths by
+// the _redirects file of root.
+func (h *handlerImpl) serveGateway(w http.ResponseWriter, r *http.Request, root, urlRoot, rest, cacheControl string) error {
+	contentPath := strings.TrimSuffix(root+"/"+rest, "/")
+	w.Header().Set("X-Ipfs-Path", r.URL.EscapedPath())
+	w.Header().Set("Vary", "Accept")
+
+	format, err := responseFormat(r)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case rawType:
+		return h.serveRaw(w, r, contentPath, cacheControl)
+	case carType:
+		return h.serveCar(w, r, contentPath, cacheControl)
+	}
+
+	// the root was checked by the caller, the content below it is checked by its own CID
+	if err := h.checkResolvedDenylist(r, "gateway", contentPath, gatewayErrorStatus); err != nil {
+		return err
+	}
+	file, err := h.ipfs.OpenFile(r.Context(), contentPath)
+	switch {
+	case err == nil:
+		defer file.Close()
+		return serveGatewayFile(w, r, file, path.Base(contentPath), http.StatusOK, cacheControl)
+	case errors.Is(err, ipfs.ErrNotFile):
+		return h.serveDir(w, r, contentPath, rest, cacheControl)
+	case errors.Is(err, ipfs.ErrPathNotFound):
+		return h.serveRedirects(w, r, root, urlRoot, rest, cacheControl, err)
+	default:
+		return gatewayErrorStatus(err)
+	}
+}
+
+// responseFormat returns the trustless response type asked for by the "format" query parameter or the Accept
+// header, or an empty string for a regular response.
+func responseFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "raw":
+		return rawType, nil
+	case "car":
+		return carType, nil
+	case "":
+	default:
+		return "", NewErrorStatus(fmt.Errorf("unsupported format %q", format), http.StatusBadRequest, 0)
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && (mediaType == rawType || mediaType == carType) {
+			return mediaType, nil
+		}
+	}
+	return "", nil
+}
+
+// resolveBlock returns the CID of the block a content path resolves to, failing if the path goes on inside
+// the block.
+func (h *handlerImpl) resolveBlock(r *http.Request, contentPath string) (string, error) {
+	res, err := h.ipfs.DagResolve(r.Context(), contentPath)
+	if err != nil {
+		return "", gatewayErrorStatus(err)
+	}
+	if res.RemPath != "" {
+		return "", NewErrorStatus(fmt.Errorf("%w: %s", ipfs.ErrPathNotFound, contentPath), http.StatusNotFound, 0)
+	}
+	return res.Cid, nil
+}
+
+// serveRaw serves the block a content path resolves to.
+func (h *handlerImpl) serveRaw(w http.ResponseWriter, r *http.Request, contentPath, cacheControl string) error {
+	c, err := h.resolveBlock(r, contentPath)
+	if err != nil {
+		return err
+	}
+	if err := h.checkDenylist(r, "gateway", "/ipfs/"+c); err != nil {
+		return err
+	}
+	block, err := h.ipfs.GetBlock(r.Context(), c)
+	if err != nil {
+		return gatewayErrorStatus(err)
+	}
+
+	setTrustlessHeaders(w, c, "raw", "bin", cacheControl)
+	w.Header().Set("Content-Type", rawType)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(block))
+	return nil
+}
+
+// serveCar serves the DAG rooted at the block a content path resolves to as a CARv1 stream. The CAR holds the
+// whole DAG of that block, not the blocks the path was resolved through.
+func (h *handlerImpl) serveCar(w http.ResponseWriter, r *http.Request, contentPath, cacheControl string) error {
+	c, err := h.resolveBlock(r, contentPath)
+	if err != nil {
+		return err
+	}
+	if err := h.checkDenylist(r, "gateway", "/ipfs/"+c); err != nil {
+		return err
+	}
+
+	etag := setTrustlessHeaders(w, c, "car", "car", cacheControl)
+	w.Header().Set("Content-Type", carType+"; version=1")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	cw := &countingWriter{w: w}
+	if err := h.ipfs.ExportCar(r.Context(), c, cw); err != nil {
+		if cw.n == 0 {
+			for _, header := range []string{"ETag", "Cache-Control", "Content-Disposition"} {
+				w.Header().Del(header)
+			}
+			return gatewayErrorStatus(err)
+		}
+		// the status is sent, the client sees a truncated CAR
+		log.Printf("failed to export car %s: %v", c, err)
+	}
+	return nil
+}
+
+// setTrustlessHeaders sets the headers of a trustless response for the block c, and returns its ETag.
+func setTrustlessHeaders(w http.ResponseWriter, c, format, ext, cacheControl string) string {
+	etag := strconv.Quote(c + "." + format)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": c + "." + ext}))
+	return etag
+}
+
+// serveDir serves the index.html of a directory, or a listing of its entries if it has none. Directories are
+// redirected to their URL with a trailing slash first, so that relative links resolve inside them.
+func (h *handlerImpl) serveDir(w http.ResponseWriter, r *http.Request, contentPath, rest, cacheControl string) error {
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		target := r.URL.EscapedPath() + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return nil
+	}
+
+	// the index.html may be blocked by itself
+	if err := h.checkResolvedDenylist(r, "gateway", contentPath+"/index.html", gatewayErrorStatus); err != nil {
+		return err
+	}
+	index, err := h.ipfs.OpenFile(r.Context(), contentPath+"/index.html")
+	if err == nil {
+		defer index.Close()
+		return serveGatewayFile(w, r, index, "index.html", http.StatusOK, cacheControl)
+	}
+	if !errors.Is(err, ipfs.ErrPathNotFound) && !errors.Is(err, ipfs.ErrNotFile) {
+		return gatewayErrorStatus(err)
+	}
+
+	files, err := h.ipfs.ListDir(r.Context(), contentPath)
+	if err != nil {
+		return gatewayErrorStatus(err)
+	}
+	data := struct {
+		Path    string
+		Parent  bool
+		Entries []dirEntry
+	}{
+		Path:    r.URL.Path,
+		Parent:  rest != "",
+		Entries: make([]dirEntry, len(files)),
+	}
+	for i, f := range files {
+		entry := dirEntry{Name: f.Name, Href: url.PathEscape(f.Name), Cid: f.Cid.String()}
+		if f.Type == iface.TDirectory {
+			entry.Href += "/"
+		} else {
+			entry.Size = humanize.IBytes(f.Size)
+		}
+		data.Entries[i] = entry
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", cacheControl)
+	return dirPage.Execute(w, data)
+}
+
+// serveRedirects serves a path missing from root by the first rule of the _redirects file of root matching it,
+// redirecting to its target or serving the target with the status of the rule. Without a matching rule, the
+// path is not found.
+func (h *handlerImpl) serveRedirects(w http.ResponseWriter, r *http.Request, root, urlRoot, rest, cacheControl string, notFound error) error {
+	file, err := h.ipfs.OpenFile(r.Context(), root+"/"+gateway.RedirectsFile)
+	if errors.Is(err, ipfs.ErrPathNotFound) || errors.Is(err, ipfs.ErrNotFile) {
+		return gatewayErrorStatus(notFound)
+	}
+	if err != nil {
+		return gatewayErrorStatus(err)
+	}
+	data, err := io.ReadAll(io.LimitReader(file, gateway.MaxRedirectsSize+1))
+	file.Close()
+	if err != nil {
+		return gatewayErrorStatus(err)
+	}
+
+	// a broken _redirects file is the fault of the website, not of Hive
+	rules, err := gateway.ParseRedirects(data)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 0)
+	}
+	to, status, ok := gateway.Match(rules, "/"+rest)
+	if !ok {
+		return gatewayErrorStatus(notFound)
+	}
+
+	if status >= 300 && status < 400 {
+		if strings.HasPrefix(to, "/") {
+			to = urlRoot + to
+		}
+		http.Redirect(w, r, to, status)
+		return nil
+	}
+	if !strings.HasPrefix(to, "/") {
+		return NewErrorStatus(fmt.Errorf("%w: cannot serve %s with status %d", gateway.ErrInvalidRedirects, to, status),
+			http.StatusInternalServerError, 0)
+	}
+
+	// the target stays inside root, and may be blocked by itself
+	targetPath := root + path.Clean(to)
+	if err := h.checkResolvedDenylist(r, "gateway", targetPath, gatewayErrorStatus); err != nil {
+		return err
+	}
+	target, err := h.ipfs.OpenFile(r.Context(), targetPath)
+	if err != nil {
+		return gatewayErrorStatus(err)
+	}
+	defer target.Close()
+	return serveGatewayFile(w, r, target, path.Base(to), status, cacheControl)
+}
+
+// serveGatewayFile serves a file with the type of its name's extension, sniffed from its content without one.
+// Ranges and conditional requests are only supported with a 200 status.
+func serveGatewayFile(w http.ResponseWriter, r *http.Request, file ipfs.FileReader, name string, status int, cacheControl string) error {
+	w.Header().Set("Cache-Control", cacheControl)
+	if status == http.StatusOK {
+		http.ServeContent(w, r, name, time.Time{}, file)
+		return nil
+	}
+
+	size, err := file.Size()
+	if err != nil {
+		return gatewayErrorStatus(err)
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(file, head)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return gatewayErrorStatus(err)
+		}
+		contentType = http.DetectContentType(head[:n])
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return gatewayErrorStatus(err)
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		io.Copy(w, file)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	iface "github.com/ipfs/kubo/core/coreiface"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/config"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGateway(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+	root := "/ipfs/" + testCid
+	notFound := func(p string) error { return fmt.Errorf("%w: %s", ipfs.ErrPathNotFound, p) }
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		// content served in Hive's origin is always sandboxed
+		require.Equal(t, "sandbox", w.Header().Get("Content-Security-Policy"))
+		return w
+	}
+	get := func(target string) *httptest.ResponseRecorder {
+		return serve(httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	t.Run("File", func(t *testing.T) {
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/css/site.css").Return(newTestFile("body {}"), nil)
+		w := get(root + "/css/site.css")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "body {}", w.Body.String())
+		require.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, ipfsCacheControl, w.Header().Get("Cache-Control"))
+		require.Equal(t, root+"/css/site.css", w.Header().Get("X-Ipfs-Path"))
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		mockClient.EXPECT().OpenFile(gomock.Any(), root).Return(newTestFile("0123456789"), nil)
+		r := httptest.NewRequest(http.MethodGet, root, nil)
+		r.Header.Set("Range", "bytes=2-4")
+		w := serve(r)
+		require.Equal(t, http.StatusPartialContent, w.Code)
+		require.Equal(t, "234", w.Body.String())
+	})
+
+	t.Run("Directory redirects to a trailing slash", func(t *testing.T) {
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/docs").Return(nil, ipfs.ErrNotFile)
+		w := get(root + "/docs?x=1")
+		require.Equal(t, http.StatusMovedPermanently, w.Code)
+		require.Equal(t, root+"/docs/?x=1", w.Header().Get("Location"))
+	})
+
+	t.Run("Directory index.html", func(t *testing.T) {
+		mockClient.EXPECT().OpenFile(gomock.Any(), root).Return(nil, ipfs.ErrNotFile)
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/index.html").Return(newTestFile("<h1>home</h1>"), nil)
+		w := get(root + "/")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "<h1>home</h1>", w.Body.String())
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("Directory listing", func(t *testing.T) {
+		c, err := cid.Decode(testNewCid)
+		require.NoError(t, err)
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/docs").Return(nil, ipfs.ErrNotFile)
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/docs/index.html").Return(nil, notFound(root+"/docs/index.html"))
+		mockClient.EXPECT().ListDir(gomock.Any(), root+"/docs").Return([]ipfs.DirFileDetail{
+			{Name: "a <b>.txt", Cid: c, Size: 2048, Type: iface.TFile},
+			{Name: "images", Cid: c, Type: iface.TDirectory},
+		}, nil)
+
+		w := get(root + "/docs/")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		require.Contains(t, body, `<a href="..">..</a>`)
+		require.Contains(t, body, `<a href="a%20%3Cb%3E.txt">a &lt;b&gt;.txt</a>`)
+		require.Contains(t, body, "2.0 KiB")
+		require.Contains(t, body, `<a href="images/">images</a>`)
+		require.Contains(t, body, testNewCid)
+	})
+
+	t.Run("Redirects", func(t *testing.T) {
+		redirects := "# site rules\n/old/:page /new/:page 301\n/app/* /index.html 200\n/* /404.html 404\n"
+		tests := []struct {
+			name     string
+			path     string
+			status   int
+			location string
+			target   string
+		}{
+			{name: "Redirect", path: "/old/about", status: http.StatusMovedPermanently, location: root + "/new/about"},
+			{name: "Rewrite", path: "/app/users/1", status: http.StatusOK, target: "/index.html"},
+			{name: "Custom 404", path: "/missing", status: http.StatusNotFound, target: "/404.html"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockClient.EXPECT().OpenFile(gomock.Any(), root+tt.path).Return(nil, notFound(root+tt.path))
+				mockClient.EXPECT().OpenFile(gomock.Any(), root+"/_redirects").Return(newTestFile(redirects), nil)
+				if tt.target != "" {
+					mockClient.EXPECT().OpenFile(gomock.Any(), root+tt.target).Return(newTestFile("<p>"+tt.target+"</p>"), nil)
+				}
+
+				w := get(root + tt.path)
+				require.Equal(t, tt.status, w.Code)
+				require.Equal(t, tt.location, w.Header().Get("Location"))
+				if tt.target != "" {
+					require.Equal(t, "<p>"+tt.target+"</p>", w.Body.String())
+					require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+				}
+			})
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/missing").Return(nil, notFound(root+"/missing"))
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/_redirects").Return(nil, notFound(root+"/_redirects"))
+		w := get(root + "/missing")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, `{"error":"path not found: `+root+`/missing"}`, strings.TrimSpace(w.Body.String()))
+		require.Empty(t, w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Raw block", func(t *testing.T) {
+		mockClient.EXPECT().DagResolve(gomock.Any(), root+"/a.txt").Return(ipfs.DagResolveResult{Cid: testNewCid}, nil)
+		mockClient.EXPECT().GetBlock(gomock.Any(), testNewCid).Return([]byte("block"), nil)
+		w := get(root + "/a.txt?format=raw")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "block", w.Body.String())
+		require.Equal(t, rawType, w.Header().Get("Content-Type"))
+		require.Equal(t, `"`+testNewCid+`.raw"`, w.Header().Get("ETag"))
+		require.Equal(t, "attachment; filename="+testNewCid+".bin", w.Header().Get("Content-Disposition"))
+		require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	})
+
+	t.Run("CAR", func(t *testing.T) {
+		mockClient.EXPECT().DagResolve(gomock.Any(), root).Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+		mockClient.EXPECT().ExportCar(gomock.Any(), testCid, gomock.Any()).DoAndReturn(
+			func(_ any, _ string, w io.Writer) error {
+				_, err := w.Write([]byte("car"))
+				return err
+			})
+		r := httptest.NewRequest(http.MethodGet, root, nil)
+		r.Header.Set("Accept", "application/vnd.ipld.car; version=1")
+		w := serve(r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "car", w.Body.String())
+		require.Equal(t, "application/vnd.ipld.car; version=1", w.Header().Get("Content-Type"))
+		require.Equal(t, `"`+testCid+`.car"`, w.Header().Get("ETag"))
+		require.Equal(t, "Accept", w.Header().Get("Vary"))
+
+		mockClient.EXPECT().DagResolve(gomock.Any(), root).Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+		r = httptest.NewRequest(http.MethodGet, root+"?format=car", nil)
+		r.Header.Set("If-None-Match", `"`+testCid+`.car"`)
+		w = serve(r)
+		require.Equal(t, http.StatusNotModified, w.Code)
+
+		mockClient.EXPECT().DagResolve(gomock.Any(), root).Return(ipfs.DagResolveResult{Cid: testCid}, nil)
+		mockClient.EXPECT().ExportCar(gomock.Any(), testCid, gomock.Any()).Return(errors.New("export failed"))
+		w = get(root + "?format=car")
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Empty(t, w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Path inside a block", func(t *testing.T) {
+		mockClient.EXPECT().DagResolve(gomock.Any(), root+"/field").Return(ipfs.DagResolveResult{Cid: testCid, RemPath: "field"}, nil)
+		w := get(root + "/field?format=raw")
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("IPNS", func(t *testing.T) {
+		mockClient.EXPECT().ResolveName(gomock.Any(), "example.com").Return(root, nil)
+		mockClient.EXPECT().OpenFile(gomock.Any(), root+"/a.txt").Return(newTestFile("hello"), nil)
+		w := get("/ipns/example.com/a.txt")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "hello", w.Body.String())
+		require.Equal(t, ipnsCacheControl, w.Header().Get("Cache-Control"))
+
+		mockClient.EXPECT().ResolveName(gomock.Any(), "example.org").Return("", errors.New("could not resolve name"))
+		w = get("/ipns/example.org/")
+		require.Equal(t, http.StatusBadGateway, w.Code)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		w := get("/ipfs/nonsense/a.txt")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, `{"error":"invalid cid \"nonsense\""}`, strings.TrimSpace(w.Body.String()))
+
+		w = get(root + "?format=tar")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, `{"error":"unsupported format \"tar\""}`, strings.TrimSpace(w.Body.String()))
+	})
+}
+
+func TestGatewayMux(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	mux := NewHandlerImpl(mockClient, &config.Config{}).GatewayMux()
+
+	mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testCid).Return(newTestFile("<script>app()</script>"), nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ipfs/"+testCid, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	// in an origin of its own, the content is not sandboxed
+	require.Empty(t, w.Header().Get("Content-Security-Policy"))
+	require.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("<script>")))
+
+	// the gateway mux serves nothing else
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/self", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
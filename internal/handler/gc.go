@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/zde37/Hive/internal/gc"
+)
+
+// runGC performs a garbage collection of the IPFS repository, streaming the CID of every removed block as a
+// "removed" Server-Sent Event, followed by a "done" event with the report of the run.
+func (h *handlerImpl) RunGC(w http.ResponseWriter, r *http.Request) error {
+	stream, err := newEventStream(w, r)
+	if err != nil {
+		return err
+	}
+
+	run, err := h.collector.Run(r.Context(), gc.TriggerManual, func(cid string) error {
+		resp := struct {
+			Cid string `json:"cid"`
+		}{
+			Cid: cid,
+		}
+		return stream.send("removed", resp)
+	})
+	if errors.Is(err, gc.ErrRunning) {
+		return NewErrorStatus(err, http.StatusConflict, 0)
+	}
+	if err != nil {
+		return stream.fail(NewErrorStatus(err, http.StatusInternalServerError, 1))
+	}
+
+	return stream.send("done", run)
+}
+
+// getGCHistory retrieves the reports of the most recent garbage collection runs, newest first.
+func (h *handlerImpl) GetGCHistory(w http.ResponseWriter, r *http.Request) error {
+	runs, err := h.collector.History()
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Runs []gc.Run `json:"runs"`
+	}{
+		Runs: runs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/config"
+	"github.com/zde37/Hive/internal/gc"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+// timestamps matches the RFC 3339 timestamps of a garbage collection report.
+var timestamps = regexp.MustCompile(`"(started_at|finished_at)":"[^"]+"`)
+
+func TestRunGC(t *testing.T) {
+	tests := []struct {
+		name                string
+		token               string
+		setupMock           func(mockClient *mocked.MockClient)
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:  "Stream removed blocks",
+			token: testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				gomock.InOrder(
+					mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 2048}, nil),
+					mockClient.EXPECT().GarbageCollect(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ context.Context, fn func(string) error) error {
+							return fn(testCid)
+						}),
+					mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 1024}, nil),
+				)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedBody: "event: removed\ndata: {\"cid\":\"" + testCid + "\"}\n\n" +
+				"event: done\ndata: {\"trigger\":\"manual\",\"started_at\":\"T\",\"finished_at\":\"T\",\"removed\":1,\"bytes_reclaimed\":1024}",
+		},
+		{
+			name:  "Garbage collection fails",
+			token: testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{}, errors.New("repo locked"))
+			},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"repo locked"}`,
+		},
+		{
+			name:                "Missing token",
+			setupMock:           func(mockClient *mocked.MockClient) {},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"invalid admin token"}`,
+		},
+		{
+			name:                "Wrong token",
+			token:               "guess",
+			setupMock:           func(mockClient *mocked.MockClient) {},
+			expectedStatus:      http.StatusUnauthorized,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"invalid admin token"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/gc", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			body := timestamps.ReplaceAllString(strings.TrimSpace(w.Body.String()), `"$1":"T"`)
+			require.Equal(t, tt.expectedBody, body)
+		})
+	}
+}
+
+func TestGCHistory(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+	mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{}, nil).Times(2)
+	mockClient.EXPECT().GarbageCollect(gomock.Any(), gomock.Any()).Return(nil)
+
+	request := func(method, target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request(http.MethodGet, "/v1/gc/history")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"runs":[]}`, strings.TrimSpace(w.Body.String()))
+
+	require.Equal(t, http.StatusOK, request(http.MethodPost, "/v1/gc").Code)
+
+	w = request(http.MethodGet, "/v1/gc/history")
+	require.Equal(t, http.StatusOK, w.Code)
+	body := timestamps.ReplaceAllString(strings.TrimSpace(w.Body.String()), `"$1":"T"`)
+	require.Equal(t, `{"runs":[{"trigger":"manual","started_at":"T","finished_at":"T","removed":0,"bytes_reclaimed":0}]}`, body)
+}
+
+func TestAdminDisabled(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	handler := NewHandlerImpl(mockClient, &config.Config{}, WithCollector(gc.NewCollector(mockClient, nil))).Mux()
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/gc/history", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, `{"error":"admin api is disabled"}`, strings.TrimSpace(w.Body.String()))
+}
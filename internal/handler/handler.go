@@ -5,15 +5,78 @@ import "net/http"
 // Handler is an interface that defines the methods for handling HTTP requests.
 type Handler interface {
 	Mux() *http.ServeMux
+	GatewayMux() *http.ServeMux
 	Health(w http.ResponseWriter, r *http.Request) error
 	GetNodeInfo(w http.ResponseWriter, r *http.Request) error
+	GetSelf(w http.ResponseWriter, r *http.Request) error
+	GetStats(w http.ResponseWriter, r *http.Request) error
 	PingNode(w http.ResponseWriter, r *http.Request) error
 	AddFile(w http.ResponseWriter, r *http.Request) error
+	AddFolder(w http.ResponseWriter, r *http.Request) error
 	DownloadFile(w http.ResponseWriter, r *http.Request) error
 	ListNodes(w http.ResponseWriter, r *http.Request) error
 	ListPins(w http.ResponseWriter, r *http.Request) error
 	PinObject(w http.ResponseWriter, r *http.Request) error
 	DeleteFile(w http.ResponseWriter, r *http.Request) error
 	DisplayFileContents(w http.ResponseWriter, r *http.Request) error
+	Preview(w http.ResponseWriter, r *http.Request) error
+	Thumbnail(w http.ResponseWriter, r *http.Request) error
+	CreateShare(w http.ResponseWriter, r *http.Request) error
+	ListShares(w http.ResponseWriter, r *http.Request) error
+	RevokeShare(w http.ResponseWriter, r *http.Request) error
+	OpenShare(w http.ResponseWriter, r *http.Request) error
+	DownloadShare(w http.ResponseWriter, r *http.Request) error
+	IPFSGateway(w http.ResponseWriter, r *http.Request) error
+	IPNSGateway(w http.ResponseWriter, r *http.Request) error
+	ListDenylist(w http.ResponseWriter, r *http.Request) error
+	AddDenylistRule(w http.ResponseWriter, r *http.Request) error
+	RemoveDenylistRule(w http.ResponseWriter, r *http.Request) error
+	ReloadDenylist(w http.ResponseWriter, r *http.Request) error
+	GetDenylistAudit(w http.ResponseWriter, r *http.Request) error
+	ListQuarantine(w http.ResponseWriter, r *http.Request) error
 	DownloadFolder(w http.ResponseWriter, r *http.Request) error
+	ImportCar(w http.ResponseWriter, r *http.Request) error
+	DagPut(w http.ResponseWriter, r *http.Request) error
+	DagGet(w http.ResponseWriter, r *http.Request) error
+	DagStat(w http.ResponseWriter, r *http.Request) error
+	DagResolve(w http.ResponseWriter, r *http.Request) error
+	ListPubsubTopics(w http.ResponseWriter, r *http.Request) error
+	ListPubsubPeers(w http.ResponseWriter, r *http.Request) error
+	PublishPubsub(w http.ResponseWriter, r *http.Request) error
+	PubsubBridge(w http.ResponseWriter, r *http.Request) error
+	FindProviders(w http.ResponseWriter, r *http.Request) error
+	Provide(w http.ResponseWriter, r *http.Request) error
+	ConnectPeer(w http.ResponseWriter, r *http.Request) error
+	DisconnectPeer(w http.ResponseWriter, r *http.Request) error
+	ListPeering(w http.ResponseWriter, r *http.Request) error
+	AddPeering(w http.ResponseWriter, r *http.Request) error
+	RemovePeering(w http.ResponseWriter, r *http.Request) error
+	RunGC(w http.ResponseWriter, r *http.Request) error
+	GetGCHistory(w http.ResponseWriter, r *http.Request) error
+	GetPinHealth(w http.ResponseWriter, r *http.Request) error
+	CheckPinHealth(w http.ResponseWriter, r *http.Request) error
+	ListPinJobs(w http.ResponseWriter, r *http.Request) error
+	GetPinJob(w http.ResponseWriter, r *http.Request) error
+	DeletePinJob(w http.ResponseWriter, r *http.Request) error
+	UpdatePin(w http.ResponseWriter, r *http.Request) error
+	ListVersions(w http.ResponseWriter, r *http.Request) error
+	RollbackVersion(w http.ResponseWriter, r *http.Request) error
+	DiffVersions(w http.ResponseWriter, r *http.Request) error
+	GetFileMetadata(w http.ResponseWriter, r *http.Request) error
+	UpdateFileMetadata(w http.ResponseWriter, r *http.Request) error
+	ClearFileMetadata(w http.ResponseWriter, r *http.Request) error
+	AddFileTag(w http.ResponseWriter, r *http.Request) error
+	RemoveFileTag(w http.ResponseWriter, r *http.Request) error
+	Search(w http.ResponseWriter, r *http.Request) error
+	ListRemoteServices(w http.ResponseWriter, r *http.Request) error
+	AddRemoteService(w http.ResponseWriter, r *http.Request) error
+	RemoveRemoteService(w http.ResponseWriter, r *http.Request) error
+	ListRemotePins(w http.ResponseWriter, r *http.Request) error
+	AddRemotePin(w http.ResponseWriter, r *http.Request) error
+	RemoveRemotePin(w http.ResponseWriter, r *http.Request) error
+	ListPSAPins(w http.ResponseWriter, r *http.Request) error
+	AddPSAPin(w http.ResponseWriter, r *http.Request) error
+	GetPSAPin(w http.ResponseWriter, r *http.Request) error
+	ReplacePSAPin(w http.ResponseWriter, r *http.Request) error
+	RemovePSAPin(w http.ResponseWriter, r *http.Request) error
 }
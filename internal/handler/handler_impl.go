@@ -2,32 +2,162 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ipfs/go-cid"
+	"github.com/zde37/Hive/internal/config"
+	"github.com/zde37/Hive/internal/denylist"
+	"github.com/zde37/Hive/internal/encryption"
+	"github.com/zde37/Hive/internal/gc"
 	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/metadata"
+	"github.com/zde37/Hive/internal/pinhealth"
+	"github.com/zde37/Hive/internal/pinjob"
+	"github.com/zde37/Hive/internal/psa"
+	"github.com/zde37/Hive/internal/scanner"
+	"github.com/zde37/Hive/internal/search"
+	"github.com/zde37/Hive/internal/share"
+	"github.com/zde37/Hive/internal/thumbnail"
+	"github.com/zde37/Hive/internal/versions"
 )
 
-const maxUploadSize = 100 * 1024 * 1024 // 100MB in bytes
+const (
+	maxUploadSize = 100 * 1024 * 1024  // 100MB in bytes
+	maxCarSize    = 1024 * 1024 * 1024 // 1GB in bytes
+
+	folderMimeType = "inode/directory" // the MIME type recorded for uploaded folders.
+)
 
 // handlerImpl implements the Handler interface and manages HTTP request handling.
 type handlerImpl struct {
-	ipfs   ipfs.Client
-	server *http.ServeMux
+	ipfs       ipfs.Client
+	config     *config.Config
+	server     *http.ServeMux
+	collector  *gc.Collector       // runs garbage collections, the gc routes are only served if it is set.
+	checker    *pinhealth.Checker  // verifies pins, the pin health routes are only served if it is set.
+	pinJobs    *pinjob.Manager     // pins CIDs in the background, the pin job routes are only served if it is set.
+	pinService *psa.Service        // the Pinning Service API routes are only served if it is set.
+	versions   *versions.History   // records the versions of named pins, the version routes are only served if it is set.
+	files      *metadata.Index     // records the metadata of uploaded files, which is only recorded and served if it is set.
+	search     *search.Index       // indexes the text of uploaded documents, the search route is only served if it is set.
+	thumbs     *thumbnail.Cache    // caches the thumbnails of images, the thumbnail route is only served if it is set.
+	shares     *share.Manager      // issues share links, the share routes are only served if it is set.
+	denylist   *denylist.Manager   // blocks content, which is only checked and the denylist routes only served if it is set.
+	scanner    scanner.Scanner     // scans uploads for malware, which are only scanned if it is set.
+	quarantine *scanner.Quarantine // keeps infected uploads, the quarantine route is only served if it is set.
+	masterKey  []byte              // wraps the data keys of encrypted uploads, which need a passphrase if it is not set.
+}
+
+// Option configures an optional dependency of the handler.
+type Option func(*handlerImpl)
+
+// WithCollector sets the garbage collector behind the gc routes.
+func WithCollector(collector *gc.Collector) Option {
+	return func(h *handlerImpl) {
+		h.collector = collector
+	}
+}
+
+// WithPinChecker sets the pin verifier behind the pin health routes.
+func WithPinChecker(checker *pinhealth.Checker) Option {
+	return func(h *handlerImpl) {
+		h.checker = checker
+	}
+}
+
+// WithPinJobs sets the pin job manager behind the pin routes.
+func WithPinJobs(manager *pinjob.Manager) Option {
+	return func(h *handlerImpl) {
+		h.pinJobs = manager
+	}
+}
+
+// WithPinService sets the pinning service behind the Pinning Service API routes.
+func WithPinService(service *psa.Service) Option {
+	return func(h *handlerImpl) {
+		h.pinService = service
+	}
+}
+
+// WithVersions sets the version history behind the pin update and version routes.
+func WithVersions(history *versions.History) Option {
+	return func(h *handlerImpl) {
+		h.versions = history
+	}
+}
+
+// WithFileIndex sets the metadata index of the uploaded files.
+func WithFileIndex(index *metadata.Index) Option {
+	return func(h *handlerImpl) {
+		h.files = index
+	}
+}
+
+// WithSearchIndex sets the full-text index behind the search route.
+func WithSearchIndex(index *search.Index) Option {
+	return func(h *handlerImpl) {
+		h.search = index
+	}
+}
+
+// WithThumbnails sets the thumbnail cache behind the thumbnail route.
+func WithThumbnails(cache *thumbnail.Cache) Option {
+	return func(h *handlerImpl) {
+		h.thumbs = cache
+	}
+}
+
+// WithShares sets the share link manager behind the share routes.
+func WithShares(manager *share.Manager) Option {
+	return func(h *handlerImpl) {
+		h.shares = manager
+	}
+}
+
+// WithDenylist sets the denylist content is checked against, and behind the denylist routes.
+func WithDenylist(manager *denylist.Manager) Option {
+	return func(h *handlerImpl) {
+		h.denylist = manager
+	}
+}
+
+// WithScanner sets the scanner uploads are scanned with, and the quarantine infected uploads are kept in, if it
+// is not nil.
+func WithScanner(s scanner.Scanner, quarantine *scanner.Quarantine) Option {
+	return func(h *handlerImpl) {
+		h.scanner = s
+		h.quarantine = quarantine
+	}
+}
+
+// WithMasterKey sets the master key wrapping the data keys of the uploads encrypted without a passphrase.
+func WithMasterKey(key []byte) Option {
+	return func(h *handlerImpl) {
+		h.masterKey = key
+	}
 }
 
 // NewHandlerImpl creates and initializes a new Handler instance.
-func NewHandlerImpl(ipfs ipfs.Client) Handler {
+func NewHandlerImpl(ipfs ipfs.Client, config *config.Config, opts ...Option) Handler {
 	mux := http.NewServeMux()
 	handlerImpl := &handlerImpl{
 		ipfs:   ipfs,
+		config: config,
 		server: mux,
 	}
+	for _, opt := range opts {
+		opt(handlerImpl)
+	}
 
 	handlerImpl.registerRoutes()
 	return handlerImpl
@@ -42,23 +172,110 @@ func (h *handlerImpl) Mux() *http.ServeMux {
 func (h *handlerImpl) registerRoutes() {
 	h.server.Handle("GET /hello-world", errorMiddleware(h.Health))
 	h.server.Handle("GET /info/{peerid}", errorMiddleware(h.GetNodeInfo))
+	h.server.Handle("GET /self", errorMiddleware(h.GetSelf))
+	h.server.Handle("GET /stats", errorMiddleware(h.GetStats))
 	h.server.Handle("GET /peers", errorMiddleware(h.ListNodes))
+	h.server.Handle("GET /ping/{peerid}", timeoutErrorMiddleware(h.PingNode, 0))
 	h.server.Handle("GET /file", errorMiddleware(h.DownloadFile))
+	h.server.Handle("GET /preview/{cid}", timeoutErrorMiddleware(h.Preview, 0))
 	h.server.Handle("GET /pins", errorMiddleware(h.ListPins))
 	h.server.Handle("DELETE /file/{cid}", errorMiddleware(h.DeleteFile))
 	h.server.Handle("POST /file", errorMiddleware(h.AddFile))
+	h.server.Handle("POST /folder", errorMiddleware(h.AddFolder))
+	h.server.Handle("POST /car", timeoutErrorMiddleware(h.ImportCar, 0))
+	h.server.Handle("POST /dag", errorMiddleware(h.DagPut))
+	h.server.Handle("GET /dag/{path...}", errorMiddleware(h.DagGet))
+	h.server.Handle("GET /dag/stat/{cid}", errorMiddleware(h.DagStat))
+	h.server.Handle("GET /dag/resolve/{path...}", errorMiddleware(h.DagResolve))
+	h.server.Handle("GET /pubsub", errorMiddleware(h.ListPubsubTopics))
+	h.server.Handle("GET /pubsub/{topic}", timeoutErrorMiddleware(h.PubsubBridge, 0))
+	h.server.Handle("GET /pubsub/{topic}/peers", errorMiddleware(h.ListPubsubPeers))
+	h.server.Handle("POST /pubsub/{topic}", errorMiddleware(h.PublishPubsub))
+	h.server.Handle("GET /providers/{cid}", timeoutErrorMiddleware(h.FindProviders, 0))
+	h.server.Handle("POST /providers/{cid}", timeoutErrorMiddleware(h.Provide, 0))
+	h.server.Handle("POST /swarm/connect", errorMiddleware(h.admin(h.ConnectPeer)))
+	h.server.Handle("POST /swarm/disconnect", errorMiddleware(h.admin(h.DisconnectPeer)))
+	h.server.Handle("GET /peering", errorMiddleware(h.ListPeering))
+	h.server.Handle("POST /peering", errorMiddleware(h.admin(h.AddPeering)))
+	h.server.Handle("DELETE /peering/{peerid}", errorMiddleware(h.admin(h.RemovePeering)))
+	h.server.Handle("GET /remote/services", errorMiddleware(h.ListRemoteServices))
+	h.server.Handle("POST /remote/services", errorMiddleware(h.admin(h.AddRemoteService)))
+	h.server.Handle("DELETE /remote/services/{name}", errorMiddleware(h.admin(h.RemoveRemoteService)))
+	h.server.Handle("GET /remote/services/{name}/pins", errorMiddleware(h.ListRemotePins))
+	h.server.Handle("POST /remote/services/{name}/pins", errorMiddleware(h.AddRemotePin))
+	h.server.Handle("DELETE /remote/services/{name}/pins/{cid}", errorMiddleware(h.RemoveRemotePin))
+	if h.collector != nil {
+		h.server.Handle("POST /gc", timeoutErrorMiddleware(h.admin(h.RunGC), 0))
+		h.server.Handle("GET /gc/history", errorMiddleware(h.admin(h.GetGCHistory)))
+	}
+	if h.checker != nil {
+		h.server.Handle("GET /pins/health", errorMiddleware(h.GetPinHealth))
+		h.server.Handle("POST /pins/health", errorMiddleware(h.admin(h.CheckPinHealth)))
+	}
+	if h.pinJobs != nil {
+		h.server.Handle("POST /pin", errorMiddleware(h.PinObject))
+		h.server.Handle("GET /pin/jobs", errorMiddleware(h.ListPinJobs))
+		h.server.Handle("GET /pin/jobs/{id}", errorMiddleware(h.GetPinJob))
+		h.server.Handle("DELETE /pin/jobs/{id}", errorMiddleware(h.DeletePinJob))
+	}
+	if h.versions != nil {
+		h.server.Handle("POST /pin/update", timeoutErrorMiddleware(h.UpdatePin, 0))
+		h.server.Handle("GET /versions/{name}", errorMiddleware(h.ListVersions))
+		h.server.Handle("GET /versions/{name}/diff", timeoutErrorMiddleware(h.DiffVersions, 0))
+		h.server.Handle("POST /versions/{name}/rollback", timeoutErrorMiddleware(h.RollbackVersion, 0))
+	}
+	if h.files != nil {
+		h.server.Handle("GET /files/{cid}/metadata", errorMiddleware(h.GetFileMetadata))
+		h.server.Handle("PATCH /files/{cid}/metadata", errorMiddleware(h.UpdateFileMetadata))
+		h.server.Handle("DELETE /files/{cid}/metadata", errorMiddleware(h.ClearFileMetadata))
+		h.server.Handle("POST /files/{cid}/tags", errorMiddleware(h.AddFileTag))
+		h.server.Handle("DELETE /files/{cid}/tags/{tag}", errorMiddleware(h.RemoveFileTag))
+	}
+	if h.search != nil {
+		h.server.Handle("GET /search", errorMiddleware(h.Search))
+	}
+	if h.thumbs != nil {
+		h.server.Handle("GET /thumb/{cid}", timeoutErrorMiddleware(h.Thumbnail, 0))
+	}
+	if h.shares != nil {
+		h.server.Handle("GET /shares", errorMiddleware(h.ListShares))
+		h.server.Handle("POST /shares", errorMiddleware(h.CreateShare))
+		h.server.Handle("DELETE /shares/{id}", errorMiddleware(h.RevokeShare))
+	}
+	if h.denylist != nil {
+		h.server.Handle("GET /denylist", errorMiddleware(h.admin(h.ListDenylist)))
+		h.server.Handle("POST /denylist", errorMiddleware(h.admin(h.AddDenylistRule)))
+		h.server.Handle("DELETE /denylist", errorMiddleware(h.admin(h.RemoveDenylistRule)))
+		h.server.Handle("POST /denylist/reload", errorMiddleware(h.admin(h.ReloadDenylist)))
+		h.server.Handle("GET /denylist/audit", errorMiddleware(h.admin(h.GetDenylistAudit)))
+	}
+	if h.quarantine != nil {
+		h.server.Handle("GET /quarantine", errorMiddleware(h.admin(h.ListQuarantine)))
+	}
+	if h.pinService != nil {
+		h.server.Handle("GET /psa/pins", psaErrorMiddleware(h.authenticate(h.ListPSAPins)))
+		h.server.Handle("POST /psa/pins", psaErrorMiddleware(h.authenticate(h.AddPSAPin)))
+		h.server.Handle("GET /psa/pins/{requestid}", psaErrorMiddleware(h.authenticate(h.GetPSAPin)))
+		h.server.Handle("POST /psa/pins/{requestid}", psaErrorMiddleware(h.authenticate(h.ReplacePSAPin)))
+		h.server.Handle("DELETE /psa/pins/{requestid}", psaErrorMiddleware(h.authenticate(h.RemovePSAPin)))
+	}
 
-	// h.server.Handle("GET /ping/{peerid}", errorMiddleware(h.PingNode))
 	// h.server.Handle("GET /cat/{cid}", errorMiddleware(h.DisplayFileContents))
 	// h.server.Handle("GET /folder", errorMiddleware(h.DownloadFolder))
-
-	// h.server.Handle("POST /pin", errorMiddleware(h.PinObject))
-	// h.server.Handle("POST /folder", errorMiddleware(h.AddFolder))
 	h.serveStaticFiles()
 	corsServer := corsMiddleware(h.server)
 
 	v1 := http.NewServeMux()
 	v1.Handle("/v1/", http.StripPrefix("/v1", corsServer))
+	// the gateway is served at the root, like the gateway of an IPFS node
+	gateway := sandboxMiddleware(h.GatewayMux())
+	v1.Handle("GET /ipfs/", gateway)
+	v1.Handle("GET /ipns/", gateway)
+	if h.shares != nil {
+		// share links are short and visited by people without access to the API
+		v1.Handle("GET /s/{token}", timeoutErrorMiddleware(h.OpenShare, 0))
+		v1.Handle("POST /s/{token}", timeoutErrorMiddleware(h.DownloadShare, 0))
+	}
 	h.server = v1
 }
 
@@ -100,32 +317,92 @@ func (h *handlerImpl) GetNodeInfo(w http.ResponseWriter, r *http.Request) error
 	return json.NewEncoder(w).Encode(nodeInfo)
 }
 
-// pingNode handles a request to ping an IPFS node identified by the provided peer ID.
-func (h *handlerImpl) PingNode(w http.ResponseWriter, r *http.Request) error {
-	peerID := r.PathValue("peerid")
-	if peerID == "" {
-		return NewErrorStatus(fmt.Errorf("peerid is required"), http.StatusBadRequest, 0)
+// getSelf retrieves information about the local IPFS node, its Kubo and repository versions, and the
+// gateway and web UI addresses Hive is configured with.
+func (h *handlerImpl) GetSelf(w http.ResponseWriter, r *http.Request) error {
+	nodeInfo, err := h.ipfs.Self(r.Context())
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
 
-	pingInfo, err := h.ipfs.Ping(r.Context(), peerID)
+	version, err := h.ipfs.Version(r.Context())
 	if err != nil {
 		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
 
 	resp := struct {
-		Success bool   `json:"success"`
-		Text    string `json:"text"`
-		Time    string `json:"time"`
+		ipfs.NodeInfo
+		KuboVersion string `json:"kubo_version"`
+		RepoVersion string `json:"repo_version"`
+		GatewayAddr string `json:"gateway_addr"`
+		WebUIAddr   string `json:"webui_addr"`
 	}{
-		Success: pingInfo[len(pingInfo)-1].Success,
-		Text:    pingInfo[0].Text,
-		Time:    strings.ReplaceAll(pingInfo[len(pingInfo)-1].Text, "Average latency: ", ""),
+		NodeInfo:    nodeInfo,
+		KuboVersion: version.Version,
+		RepoVersion: version.Repo,
+		GatewayAddr: h.config.GATEWAY_ADDR,
+		WebUIAddr:   h.config.WEB_UI_ADDR,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	return json.NewEncoder(w).Encode(resp)
 }
 
+// pingNode streams the replies of count (default 10) pings to the IPFS node identified by the provided peer ID
+// as "ping" Server-Sent Events, followed by a "stats" event summarizing the round-trip times and packet loss.
+func (h *handlerImpl) PingNode(w http.ResponseWriter, r *http.Request) error {
+	peerID := r.PathValue("peerid")
+	if peerID == "" {
+		return NewErrorStatus(fmt.Errorf("peerid is required"), http.StatusBadRequest, 0)
+	}
+
+	count := defaultPingCount
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPingCount {
+			return NewErrorStatus(fmt.Errorf("count must be between 1 and %d", maxPingCount), http.StatusBadRequest, 0)
+		}
+		count = n
+	}
+
+	stream, err := newEventStream(w, r)
+	if err != nil {
+		return err
+	}
+
+	// failed replies are held back until a ping succeeds, so that a peer which never answers is reported
+	// as an error status rather than as a stream of failures
+	var (
+		stats   pingStats
+		pending []pingReply
+	)
+	err = h.ipfs.Ping(r.Context(), peerID, count, func(info ipfs.PingInfo) error {
+		reply := stats.add(info)
+		if !info.Success && stats.received == 0 {
+			pending = append(pending, reply)
+			return nil
+		}
+
+		for _, p := range pending {
+			if err := stream.send("ping", p); err != nil {
+				return err
+			}
+		}
+		pending = nil
+		return stream.send("ping", reply)
+	})
+	if err != nil {
+		return stream.fail(pingErrorStatus(err))
+	}
+
+	for _, p := range pending {
+		if err := stream.send("ping", p); err != nil {
+			return err
+		}
+	}
+	return stream.send("stats", stats.summary())
+}
+
 // addFile handles the upload of a file to the IPFS network.
 func (h *handlerImpl) AddFile(w http.ResponseWriter, r *http.Request) error {
 	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 mb
@@ -137,6 +414,27 @@ func (h *handlerImpl) AddFile(w http.ResponseWriter, r *http.Request) error {
 		return NewErrorStatus(fmt.Errorf("name is required"), http.StatusBadRequest, 0)
 	}
 
+	tags, err := metadata.NormalizeTags(config.ParseList(r.FormValue("tags")))
+	if err != nil {
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	}
+
+	// Encrypt the file with a passphrase, or the master key without one
+	passphrase, encrypt := r.FormValue("passphrase"), false
+	if v := r.FormValue("encrypt"); v != "" {
+		if encrypt, err = strconv.ParseBool(v); err != nil {
+			return NewErrorStatus(fmt.Errorf("invalid encrypt: %q", v), http.StatusBadRequest, 0)
+		}
+	}
+	if encrypt = encrypt || passphrase != ""; encrypt {
+		if h.files == nil {
+			return NewErrorStatus(fmt.Errorf("encryption is not supported without the file index"), http.StatusBadRequest, 0)
+		}
+		if passphrase == "" && h.masterKey == nil {
+			return NewErrorStatus(fmt.Errorf("passphrase is required, no master key is configured"), http.StatusBadRequest, 0)
+		}
+	}
+
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		return NewErrorStatus(err, http.StatusBadRequest, 0)
@@ -162,11 +460,70 @@ func (h *handlerImpl) AddFile(w http.ResponseWriter, r *http.Request) error {
 		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
 
+	// Scan the file before it reaches IPFS
+	user := h.user(r)
+	rec := scanner.Record{Name: fileName, Filename: header.Filename, Size: header.Size, Uploader: user}
+	if err := h.scanUpload(r, tempFile.Name(), rec); err != nil {
+		return err
+	}
+
+	// Only the ciphertext of an encrypted file reaches IPFS
+	addPath := tempFile.Name()
+	var envelope *encryption.Envelope
+	if encrypt {
+		encrypted, env, err := h.encryptUpload(io.NewSectionReader(tempFile, 0, header.Size), passphrase)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(encrypted)
+		addPath, envelope = encrypted, env
+	}
+
 	// TODO: avoid duplicate entries
-	filePath, rootCid, err := h.ipfs.Add(r.Context(), fileName, tempFile.Name())
+	filePath, rootCid, err := h.ipfs.Add(r.Context(), fileName, addPath)
 	if err != nil {
 		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
+	// the CID is only known once the upload is added, so blocked content is unpinned right away
+	if err := h.checkDenylist(r, "pin", "/ipfs/"+rootCid); err != nil {
+		h.unpinBlocked(r, rootCid)
+		return err
+	}
+	head := make([]byte, 512)
+	n, _ := tempFile.ReadAt(head, 0)
+	mimeType := metadata.DetectType(header.Filename, header.Header.Get("Content-Type"), head[:n])
+
+	if h.versions != nil {
+		if _, err := h.versions.Record(fileName, rootCid, user); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+	if h.files != nil {
+		file := metadata.File{
+			Cid:        rootCid,
+			Name:       fileName,
+			Filename:   header.Filename,
+			MimeType:   mimeType,
+			Size:       header.Size,
+			Uploader:   user,
+			Tags:       tags,
+			UploadedAt: time.Now().UTC(),
+			Encryption: envelope,
+		}
+		if err := h.files.Put(file); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+	// the text and thumbnails of an encrypted file would reveal it
+	if h.search != nil && !encrypt {
+		doc := search.Document{Cid: rootCid, Name: fileName, Filename: header.Filename, Owner: user}
+		if err := h.indexText(io.NewSectionReader(tempFile, 0, header.Size), doc, mimeType); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+	if h.thumbs != nil && !encrypt && thumbnail.Supported(mimeType) {
+		h.generateThumbnails(io.NewSectionReader(tempFile, 0, header.Size), rootCid)
+	}
 
 	resp := struct {
 		FilePath string `json:"file_path"`
@@ -181,10 +538,9 @@ func (h *handlerImpl) AddFile(w http.ResponseWriter, r *http.Request) error {
 	return json.NewEncoder(w).Encode(resp)
 }
 
-// addFolder handles the upload of a folder to the IPFS network. // TODO: work on this
+// addFolder handles the upload of a folder to the IPFS network, whose files are the "file" parts of the form.
 func (h *handlerImpl) AddFolder(w http.ResponseWriter, r *http.Request) error {
-	reader, err := r.MultipartReader()
-	if err != nil {
+	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 mb
 		return NewErrorStatus(err, http.StatusBadRequest, 0)
 	}
 
@@ -193,49 +549,85 @@ func (h *handlerImpl) AddFolder(w http.ResponseWriter, r *http.Request) error {
 		return NewErrorStatus(fmt.Errorf("name is required"), http.StatusBadRequest, 0)
 	}
 
+	tags, err := metadata.NormalizeTags(config.ParseList(r.FormValue("tags")))
+	if err != nil {
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	}
+	// the wrapped key of a file covers a single ciphertext
+	if r.FormValue("encrypt") != "" || r.FormValue("passphrase") != "" {
+		return NewErrorStatus(fmt.Errorf("folders cannot be encrypted"), http.StatusBadRequest, 0)
+	}
+
+	headers := r.MultipartForm.File["file"]
+	if len(headers) == 0 {
+		return NewErrorStatus(fmt.Errorf("file is required"), http.StatusBadRequest, 0)
+	}
+
+	var totalSize int64
+	for _, header := range headers {
+		totalSize += header.Size
+	}
+	if totalSize > maxUploadSize {
+		return NewErrorStatus(fmt.Errorf("total upload size exceeds the maximum limit of 100MB"), http.StatusBadRequest, 0)
+	}
+
 	tempDir, err := os.MkdirTemp("", "upload-")
 	if err != nil {
 		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
 	defer os.RemoveAll(tempDir)
 
-	var totalSize int64
-	for {
-		part, err := reader.NextPart()
-		if err == io.EOF {
-			break
+	// Copy the uploaded files to the folder, scanning each of them before it reaches IPFS
+	user := h.user(r)
+	for _, header := range headers {
+		name := filepath.Base(header.Filename)
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			return NewErrorStatus(fmt.Errorf("invalid filename: %q", header.Filename), http.StatusBadRequest, 0)
 		}
-		if err != nil {
-			return NewErrorStatus(err, http.StatusBadRequest, 0)
-		}
-
-		if part.FileName() == "" {
-			continue // Skip non-file parts
+		dst := filepath.Join(tempDir, name)
+		if _, err := os.Stat(dst); err == nil {
+			return NewErrorStatus(fmt.Errorf("duplicate filename: %q", name), http.StatusBadRequest, 0)
 		}
-
-		dst, err := os.Create(filepath.Join(tempDir, part.FileName()))
-		if err != nil {
+		if err := copyPart(header, dst); err != nil {
 			return NewErrorStatus(err, http.StatusInternalServerError, 1)
 		}
-		defer dst.Close()
 
-		size, err := io.Copy(dst, part)
-		if err != nil {
-			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		rec := scanner.Record{Name: fileName, Filename: name, Size: header.Size, Uploader: user}
+		if err := h.scanUpload(r, dst, rec); err != nil {
+			return err
 		}
-		totalSize += size
-	}
-
-	log.Println(totalSize)
-	return nil
-	if totalSize > maxUploadSize {
-		return NewErrorStatus(fmt.Errorf("total upload size exceeds the maximum limit"), http.StatusBadRequest, 0)
 	}
 
 	filePath, rootCid, err := h.ipfs.Add(r.Context(), fileName, tempDir)
 	if err != nil {
 		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
+	// the CID is only known once the upload is added, so blocked content is unpinned right away
+	if err := h.checkDenylist(r, "pin", "/ipfs/"+rootCid); err != nil {
+		h.unpinBlocked(r, rootCid)
+		return err
+	}
+
+	if h.versions != nil {
+		if _, err := h.versions.Record(fileName, rootCid, user); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+	if h.files != nil {
+		file := metadata.File{
+			Cid:        rootCid,
+			Name:       fileName,
+			Filename:   fileName,
+			MimeType:   folderMimeType,
+			Size:       totalSize,
+			Uploader:   user,
+			Tags:       tags,
+			UploadedAt: time.Now().UTC(),
+		}
+		if err := h.files.Put(file); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
 
 	resp := struct {
 		FilePath string `json:"file_path"`
@@ -252,6 +644,25 @@ func (h *handlerImpl) AddFolder(w http.ResponseWriter, r *http.Request) error {
 	return json.NewEncoder(w).Encode(resp)
 }
 
+// copyPart copies an uploaded file to a new file at dst.
+func copyPart(header *multipart.FileHeader, dst string) error {
+	src, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, src); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 // listNodes is an HTTP handler that returns a list of connected IPFS nodes.
 func (h *handlerImpl) ListNodes(w http.ResponseWriter, r *http.Request) error {
 	nodes, err := h.ipfs.ListConnectedNodes(r.Context())
@@ -278,11 +689,27 @@ func (h *handlerImpl) ListPins(w http.ResponseWriter, r *http.Request) error {
 		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
 
+	files := map[string]metadata.File{}
+	if h.files != nil {
+		if files, err = h.files.List(); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+
+	// only the pins having every one of the "tag" query parameters are listed
+	if tags := r.URL.Query()["tag"]; len(tags) > 0 {
+		if pins, err = filterPins(pins, files, tags); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+
 	resp := struct {
-		Pins any `json:"pins"`
+		Pins  any                      `json:"pins"`
+		Files map[string]metadata.File `json:"files"` // the metadata of the pinned files uploaded through Hive, keyed by CID.
 		// Total int        `json:"total"`
 	}{
-		Pins: pins,
+		Pins:  pins,
+		Files: files,
 		// Total: len(pins),
 	}
 
@@ -290,6 +717,36 @@ func (h *handlerImpl) ListPins(w http.ResponseWriter, r *http.Request) error {
 	return json.NewEncoder(w).Encode(resp)
 }
 
+// filterPins keeps the pins listed by the IPFS node, and their metadata, whose metadata has every one of the
+// tags.
+func filterPins(pins any, files map[string]metadata.File, tags []string) (any, error) {
+	for c, file := range files {
+		if !file.HasTags(tags) {
+			delete(files, c)
+		}
+	}
+
+	list, ok := pins.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected pin list %T", pins)
+	}
+	keys, ok := list["Keys"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected pin list keys %T", list["Keys"])
+	}
+	for c := range keys {
+		if _, ok := files[c]; !ok {
+			delete(keys, c)
+		}
+	}
+	for c := range files {
+		if _, ok := keys[c]; !ok {
+			delete(files, c)
+		}
+	}
+	return list, nil
+}
+
 // displayFileContents is an HTTP handler that retrieves the content of a file
 // identified by the provided CID (Content Identifier) and returns it as a JSON response.
 func (h *handlerImpl) DisplayFileContents(w http.ResponseWriter, r *http.Request) error {
@@ -314,27 +771,30 @@ func (h *handlerImpl) DisplayFileContents(w http.ResponseWriter, r *http.Request
 	return json.NewEncoder(w).Encode(resp)
 }
 
-// pinObject handles a request to pin an IPFS object to the node.
+// pinObject enqueues a job that pins an IPFS object to the node in the background and responds with the job,
+// whose progress can be followed at the returned location.
 func (h *handlerImpl) PinObject(w http.ResponseWriter, r *http.Request) error {
 	name := r.FormValue("name")
-	cid := r.FormValue("cid")
-	if name == "" || cid == "" {
+	c := r.FormValue("cid")
+	if name == "" || c == "" {
 		return NewErrorStatus(fmt.Errorf("name and cid is required"), http.StatusBadRequest, 0)
 	}
-
-	path := fmt.Sprintf("/ipfs/%s", cid)
-	if err := h.ipfs.PinObject(r.Context(), name, path); err != nil {
-		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	if _, err := cid.Decode(c); err != nil {
+		return NewErrorStatus(fmt.Errorf("invalid cid %q", c), http.StatusBadRequest, 0)
+	}
+	if err := h.checkDenylist(r, "pin", "/ipfs/"+c); err != nil {
+		return err
 	}
 
-	resp := struct {
-		Status string `json:"status"`
-	}{
-		Status: "success",
+	job, err := h.pinJobs.Submit(c, name)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	return json.NewEncoder(w).Encode(resp)
+	w.Header().Set("Location", "/v1/pin/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(job)
 }
 
 // deleteFile is an HTTP handler that deletes an IPFS file identified by the provided CID (Content Identifier).
@@ -344,13 +804,27 @@ func (h *handlerImpl) DeleteFile(w http.ResponseWriter, r *http.Request) error {
 		return NewErrorStatus(fmt.Errorf("cid is required"), http.StatusBadRequest, 0)
 	}
 
-	cid = fmt.Sprintf("/ipfs/%s", cid)
-	if err := h.ipfs.DeleteFile(r.Context(), cid); err != nil {
+	if err := h.ipfs.DeleteFile(r.Context(), fmt.Sprintf("/ipfs/%s", cid)); err != nil {
 		if strings.HasPrefix(err.Error(), "..") {
 			return NewErrorStatus(err, http.StatusBadRequest, 0)
 		}
 		return NewErrorStatus(err, http.StatusInternalServerError, 1)
 	}
+	if h.files != nil {
+		if err := h.files.Delete(cid); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+	if h.search != nil {
+		if err := h.search.Remove(cid); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+	if h.thumbs != nil {
+		if err := h.thumbs.Remove(cid); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
 
 	resp := struct {
 		Status string `json:"status"`
@@ -368,19 +842,112 @@ func (h *handlerImpl) DownloadFile(w http.ResponseWriter, r *http.Request) error
 	if cid == "" {
 		return NewErrorStatus(fmt.Errorf("cid is required"), http.StatusBadRequest, 0)
 	}
+	if err := h.checkResolvedDenylist(r, "download", "/ipfs/"+cid, previewErrorStatus); err != nil {
+		return err
+	}
 
 	fileData, err := h.ipfs.DownloadFile(r.Context(), cid)
 	if err != nil {
 		return NewErrorStatus(fmt.Errorf("cid is required"), http.StatusInternalServerError, 1)
 	}
+	if fileData, err = h.decryptDownload(r, cid, fileData); err != nil {
+		return err
+	}
+
+	filename, mimeType, err := h.downloadName(cid)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
 
-	w.Header().Set("Content-Disposition", "attachment; filename="+cid)
-	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Type", mimeType)
 	w.Write(fileData)
 	return nil
 }
 
+// downloadName returns the filename and MIME type a file is downloaded under. Files uploaded through Hive keep
+// their original filename and type, other files are named after their CID.
+func (h *handlerImpl) downloadName(cid string) (string, string, error) {
+	if h.files != nil {
+		file, err := h.files.Get(cid)
+		switch {
+		case err == nil && file.Filename != "":
+			return file.Filename, file.MimeType, nil
+		case err != nil && !errors.Is(err, metadata.ErrNotFound):
+			return "", "", err
+		}
+	}
+	return cid, "application/octet-stream", nil
+}
+
 // downloadFolder is an HTTP handler that downloads an IPFS folder identified by the provided CID (Content Identifier).
 func (h *handlerImpl) DownloadFolder(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
+
+// importCar handles the upload of a CAR stream, either as the "file" field of a multipart form or as the raw
+// request body, importing its blocks into the IPFS node and pinning its roots under the given name.
+func (h *handlerImpl) ImportCar(w http.ResponseWriter, r *http.Request) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCarSize)
+
+	var car io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 mb
+			return NewErrorStatus(err, http.StatusBadRequest, 0)
+		}
+
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return NewErrorStatus(err, http.StatusBadRequest, 0)
+		}
+		defer file.Close()
+		car = file
+	}
+
+	name := r.FormValue("name")
+	if name == "" {
+		return NewErrorStatus(fmt.Errorf("name is required"), http.StatusBadRequest, 0)
+	}
+
+	tempFile, err := os.CreateTemp("", "import-*.car")
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if _, err = io.Copy(tempFile, car); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return NewErrorStatus(fmt.Errorf("car size exceeds the maximum limit of 1GB"), http.StatusRequestEntityTooLarge, 0)
+		}
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	}
+
+	result, err := h.ipfs.ImportCar(r.Context(), name, tempFile.Name())
+	if err != nil {
+		if errors.Is(err, ipfs.ErrInvalidCar) {
+			return NewErrorStatus(err, http.StatusUnprocessableEntity, 0)
+		}
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	// the roots pinned by the import are unpinned again if they are blocked
+	var blocked error
+	for _, root := range result.Roots {
+		if err := h.checkDenylist(r, "pin", "/ipfs/"+root.Cid); err != nil {
+			if root.PinError == "" {
+				h.unpinBlocked(r, root.Cid)
+			}
+			if blocked == nil {
+				blocked = err
+			}
+		}
+	}
+	if blocked != nil {
+		return blocked
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(result)
+}
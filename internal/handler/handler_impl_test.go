@@ -1,16 +1,37 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/config"
+	"github.com/zde37/Hive/internal/denylist"
+	"github.com/zde37/Hive/internal/encryption"
+	"github.com/zde37/Hive/internal/gc"
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/metadata"
 	mocked "github.com/zde37/Hive/internal/mocks"
+	"github.com/zde37/Hive/internal/pinhealth"
+	"github.com/zde37/Hive/internal/pinjob"
+	"github.com/zde37/Hive/internal/psa"
+	"github.com/zde37/Hive/internal/scanner"
+	"github.com/zde37/Hive/internal/search"
+	"github.com/zde37/Hive/internal/share"
+	"github.com/zde37/Hive/internal/store"
+	"github.com/zde37/Hive/internal/thumbnail"
+	"github.com/zde37/Hive/internal/versions"
 	"go.uber.org/mock/gomock"
 )
 
@@ -220,3 +241,344 @@ func TestPingNodes(t *testing.T) {
 		})
 	}
 }
+
+const (
+	testAdminToken = "admin-secret"
+	testUserToken  = "alice-token"
+)
+
+func newTestHandler(t *testing.T) (*mocked.MockClient, http.Handler) {
+	ctrl := gomock.NewController(t)
+	mockClient := mocked.NewMockClient(ctrl)
+	cfg := &config.Config{
+		GATEWAY_ADDR:  "http://127.0.0.1:8080",
+		WEB_UI_ADDR:   "http://127.0.0.1:5001/webui",
+		PUBSUB_TOPICS: []string{"hive"},
+		ADMIN_TOKEN:   testAdminToken,
+		USER_TOKENS:   map[string]string{testUserToken: "alice", "bob-token": "bob"},
+	}
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+
+	thumbs, err := thumbnail.NewCache(filepath.Join(t.TempDir(), "thumbnails"))
+	require.NoError(t, err)
+
+	shares, err := share.NewManager(st, []byte("test-secret"))
+	require.NoError(t, err)
+
+	denied, err := denylist.NewManager(st, "")
+	require.NoError(t, err)
+
+	quarantine, err := scanner.NewQuarantine(st, filepath.Join(t.TempDir(), "quarantine"))
+	require.NoError(t, err)
+
+	jobs := pinjob.NewManager(mockClient, st, 1)
+	files := metadata.NewIndex(st)
+	opts := []Option{
+		WithCollector(gc.NewCollector(mockClient, st)),
+		WithPinChecker(pinhealth.NewChecker(mockClient)),
+		WithPinJobs(jobs),
+		WithPinService(psa.NewService(mockClient, st, jobs, files)),
+		WithVersions(versions.NewHistory(st)),
+		WithFileIndex(files),
+		WithSearchIndex(search.NewIndex(st)),
+		WithThumbnails(thumbs),
+		WithShares(shares),
+		WithDenylist(denied),
+		WithScanner(fakeScanner{}, quarantine),
+		WithMasterKey(bytes.Repeat([]byte("k"), encryption.KeySize)),
+	}
+	return mockClient, NewHandlerImpl(mockClient, cfg, opts...).Mux()
+}
+
+func TestGetSelf(t *testing.T) {
+	nodeInfo := ipfs.NodeInfo{
+		Addresses:    []string{"/ip4/127.0.0.1/tcp/4001"},
+		AgentVersion: "kubo/0.29.0/",
+		ID:           "12D3KooWSelf",
+		Protocols:    []string{"/ipfs/ping/1.0.0"},
+		PublicKey:    "CAESI...",
+	}
+
+	tests := []struct {
+		name           string
+		setupMock      func(mockClient *mocked.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Self info",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Self(gomock.Any()).Return(nodeInfo, nil)
+				mockClient.EXPECT().Version(gomock.Any()).Return(ipfs.VersionInfo{Version: "0.29.0", Repo: "15"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"addresses":["/ip4/127.0.0.1/tcp/4001"],"AgentVersion":"kubo/0.29.0/","id":"12D3KooWSelf",` +
+				`"protocols":["/ipfs/ping/1.0.0"],"PublicKey":"CAESI...","kubo_version":"0.29.0","repo_version":"15",` +
+				`"gateway_addr":"http://127.0.0.1:8080","webui_addr":"http://127.0.0.1:5001/webui"}`,
+		},
+		{
+			name: "Id fails",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Self(gomock.Any()).Return(ipfs.NodeInfo{}, errors.New("node offline"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"node offline"}`,
+		},
+		{
+			name: "Version fails",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Self(gomock.Any()).Return(nodeInfo, nil)
+				mockClient.EXPECT().Version(gomock.Any()).Return(ipfs.VersionInfo{}, errors.New("node offline"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"node offline"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/self", nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestImportCar(t *testing.T) {
+	importResult := ipfs.CarImportResult{
+		Roots:      []ipfs.CarRoot{{Cid: "bafyroot"}},
+		BlockCount: 2,
+		BlockBytes: 128,
+	}
+
+	multipartBody := func(name string) (io.Reader, string) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if name != "" {
+			writer.WriteField("name", name)
+		}
+		part, _ := writer.CreateFormFile("file", "backup.car")
+		part.Write([]byte("car data"))
+		writer.Close()
+		return body, writer.FormDataContentType()
+	}
+
+	tests := []struct {
+		name           string
+		body           func() (io.Reader, string)
+		target         string
+		setupMock      func(mockClient *mocked.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Multipart upload",
+			body:   func() (io.Reader, string) { return multipartBody("backup") },
+			target: "/v1/car",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().ImportCar(gomock.Any(), "backup", gomock.Any()).DoAndReturn(
+					func(_ context.Context, _, carPath string) (ipfs.CarImportResult, error) {
+						data, err := os.ReadFile(carPath)
+						require.NoError(t, err)
+						require.Equal(t, "car data", string(data))
+						return importResult, nil
+					})
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"roots":[{"cid":"bafyroot"}],"block_count":2,"block_bytes":128}`,
+		},
+		{
+			name: "Raw body",
+			body: func() (io.Reader, string) {
+				return strings.NewReader("car data"), "application/vnd.ipld.car"
+			},
+			target: "/v1/car?name=backup",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().ImportCar(gomock.Any(), "backup", gomock.Any()).Return(importResult, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"roots":[{"cid":"bafyroot"}],"block_count":2,"block_bytes":128}`,
+		},
+		{
+			name:           "Missing name",
+			body:           func() (io.Reader, string) { return multipartBody("") },
+			target:         "/v1/car",
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"name is required"}`,
+		},
+		{
+			name:   "Invalid car",
+			body:   func() (io.Reader, string) { return multipartBody("backup") },
+			target: "/v1/car",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().ImportCar(gomock.Any(), "backup", gomock.Any()).Return(
+					ipfs.CarImportResult{}, fmt.Errorf("%w: mismatch in content integrity", ipfs.ErrInvalidCar))
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"error":"invalid car: mismatch in content integrity"}`,
+		},
+		{
+			name:   "IPFS error",
+			body:   func() (io.Reader, string) { return multipartBody("backup") },
+			target: "/v1/car",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().ImportCar(gomock.Any(), "backup", gomock.Any()).Return(
+					ipfs.CarImportResult{}, errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"connection refused"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			body, contentType := tt.body()
+			r := httptest.NewRequest(http.MethodPost, tt.target, body)
+			r.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestFileMetadata(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("name", "report"))
+	require.NoError(t, mw.WriteField("tags", "work, 2024"))
+	part, err := mw.CreateFormFile("file", "Q2 report.pdf")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("%PDF-1.7 report"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	mockClient.EXPECT().Add(gomock.Any(), "report", gomock.Any()).Return("/ipfs/"+testCid, testCid, nil)
+	r := httptest.NewRequest(http.MethodPost, "/v1/file", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	r.Header.Set("Authorization", "Bearer "+testUserToken)
+	require.Equal(t, http.StatusCreated, serve(r).Code)
+
+	mockClient.EXPECT().ListPins(gomock.Any()).Return(map[string]any{"Keys": map[string]any{testCid: map[string]string{"Name": "report", "Type": "recursive"}}}, nil)
+	w := serve(httptest.NewRequest(http.MethodGet, "/v1/pins", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var pins struct {
+		Files map[string]metadata.File `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pins))
+	file := pins.Files[testCid]
+	require.Equal(t, "report", file.Name)
+	require.Equal(t, "Q2 report.pdf", file.Filename)
+	require.Equal(t, "application/pdf", file.MimeType)
+	require.Equal(t, int64(15), file.Size)
+	require.Equal(t, "alice", file.Uploader)
+	require.Equal(t, []string{"work", "2024"}, file.Tags)
+
+	// files are downloaded under their original filename and type
+	mockClient.EXPECT().DownloadFile(gomock.Any(), testCid).Return([]byte("%PDF-1.7 report"), nil).Times(2)
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/file?cid="+testCid, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `attachment; filename="Q2 report.pdf"`, w.Header().Get("Content-Disposition"))
+	require.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+
+	mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+testCid).Return(nil)
+	require.Equal(t, http.StatusOK, serve(httptest.NewRequest(http.MethodDelete, "/v1/file/"+testCid, nil)).Code)
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/file?cid="+testCid, nil))
+	require.Equal(t, "attachment; filename="+testCid, w.Header().Get("Content-Disposition"))
+	require.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+}
+
+func TestAddFolder(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	upload := func(fields map[string]string, files ...string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for key, value := range fields {
+			require.NoError(t, mw.WriteField(key, value))
+		}
+		for _, filename := range files {
+			part, err := mw.CreateFormFile("file", filename)
+			require.NoError(t, err)
+			_, err = part.Write([]byte("<p>" + filename + "</p>"))
+			require.NoError(t, err)
+		}
+		require.NoError(t, mw.Close())
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/folder", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		r.Header.Set("Authorization", "Bearer "+testUserToken)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	mockClient.EXPECT().Add(gomock.Any(), "site", gomock.Any()).DoAndReturn(func(_ context.Context, _, path string) (string, string, error) {
+		entries, err := os.ReadDir(path)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		content, err := os.ReadFile(filepath.Join(path, "index.html"))
+		require.NoError(t, err)
+		require.Equal(t, "<p>index.html</p>", string(content))
+		return "/ipfs/" + testNewCid, testNewCid, nil
+	})
+	w := upload(map[string]string{"name": "site", "tags": "web"}, "index.html", "about.html")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.JSONEq(t, `{"file_path":"/ipfs/`+testNewCid+`","root_cid":"`+testNewCid+`","size":34}`, w.Body.String())
+
+	// the folder is recorded in the file index
+	r := httptest.NewRequest(http.MethodGet, "/v1/files/"+testNewCid+"/metadata", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	var file metadata.File
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &file))
+	require.Equal(t, "site", file.Name)
+	require.Equal(t, "site", file.Filename)
+	require.Equal(t, "inode/directory", file.MimeType)
+	require.Equal(t, int64(34), file.Size)
+	require.Equal(t, "alice", file.Uploader)
+	require.Equal(t, []string{"web"}, file.Tags)
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		files  []string
+		err    string
+	}{
+		{"Missing name", nil, []string{"index.html"}, "name is required"},
+		{"Missing files", map[string]string{"name": "site"}, nil, "file is required"},
+		{"Duplicate files", map[string]string{"name": "site"}, []string{"index.html", "index.html"}, `duplicate filename: \"index.html\"`},
+		{"Encrypted", map[string]string{"name": "site", "encrypt": "true"}, []string{"index.html"}, "folders cannot be encrypted"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := upload(tc.fields, tc.files...)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Equal(t, `{"error":"`+tc.err+`"}`, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
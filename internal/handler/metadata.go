@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ipfs/go-cid"
+	"github.com/zde37/Hive/internal/metadata"
+)
+
+// metadataErrorStatus maps an error from the file metadata index to its HTTP error status.
+func metadataErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, metadata.ErrInvalid):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	case errors.Is(err, metadata.ErrNotFound):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
+
+// pathCid returns the CID path value of a request, validated.
+func pathCid(r *http.Request) (string, error) {
+	c := r.PathValue("cid")
+	if _, err := cid.Decode(c); err != nil {
+		return "", NewErrorStatus(fmt.Errorf("invalid cid %q", c), http.StatusBadRequest, 0)
+	}
+	return c, nil
+}
+
+// writeFile writes the metadata of a file as the response.
+func writeFile(w http.ResponseWriter, file metadata.File) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(file)
+}
+
+// getFileMetadata handles a request to get the metadata of a CID.
+func (h *handlerImpl) GetFileMetadata(w http.ResponseWriter, r *http.Request) error {
+	c, err := pathCid(r)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.files.Get(c)
+	if err != nil {
+		return metadataErrorStatus(err)
+	}
+	return writeFile(w, file)
+}
+
+// updateFileMetadata handles a request to change the tags, description and custom key/value metadata of a
+// CID with the JSON patch in the body. Omitted fields are left unchanged and keys set to null are deleted.
+func (h *handlerImpl) UpdateFileMetadata(w http.ResponseWriter, r *http.Request) error {
+	c, err := pathCid(r)
+	if err != nil {
+		return err
+	}
+
+	var patch metadata.Patch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		return NewErrorStatus(fmt.Errorf("invalid metadata: %v", err), http.StatusBadRequest, 0)
+	}
+
+	file, err := h.files.Update(c, patch)
+	if err != nil {
+		return metadataErrorStatus(err)
+	}
+	return writeFile(w, file)
+}
+
+// clearFileMetadata handles a request to remove the tags, description and custom metadata of a CID. What was
+// recorded when the file was uploaded is kept.
+func (h *handlerImpl) ClearFileMetadata(w http.ResponseWriter, r *http.Request) error {
+	c, err := pathCid(r)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.files.Clear(c)
+	if err != nil {
+		return metadataErrorStatus(err)
+	}
+	return writeFile(w, file)
+}
+
+// addFileTag handles a request to add the "tag" to a CID.
+func (h *handlerImpl) AddFileTag(w http.ResponseWriter, r *http.Request) error {
+	c, err := pathCid(r)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.files.AddTag(c, r.FormValue("tag"))
+	if err != nil {
+		return metadataErrorStatus(err)
+	}
+	return writeFile(w, file)
+}
+
+// removeFileTag handles a request to remove a tag from a CID.
+func (h *handlerImpl) RemoveFileTag(w http.ResponseWriter, r *http.Request) error {
+	c, err := pathCid(r)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.files.RemoveTag(c, r.PathValue("tag"))
+	if err != nil {
+		return metadataErrorStatus(err)
+	}
+	return writeFile(w, file)
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFileMetadataRoutes(t *testing.T) {
+	_, handler := newTestHandler(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		contentType    string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Get unknown cid",
+			method:         http.MethodGet,
+			target:         "/v1/files/" + testCid + "/metadata",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"file metadata not found: ` + testCid + `"}`,
+		},
+		{
+			name:           "Invalid cid",
+			method:         http.MethodGet,
+			target:         "/v1/files/nonsense/metadata",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid cid \"nonsense\""}`,
+		},
+		{
+			name:           "Update",
+			method:         http.MethodPatch,
+			target:         "/v1/files/" + testCid + "/metadata",
+			body:           `{"tags":["project-x","confidential"],"description":"Survey results","meta":{"owner":"research"}}`,
+			contentType:    "application/json",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"cid":"` + testCid + `","name":"","filename":"","mime_type":"","size":0,"tags":["project-x","confidential"],` +
+				`"description":"Survey results","meta":{"owner":"research"},"uploaded_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Update with invalid body",
+			method:         http.MethodPatch,
+			target:         "/v1/files/" + testCid + "/metadata",
+			body:           `{"tags":"project-x"}`,
+			contentType:    "application/json",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid metadata: json: cannot unmarshal string into Go struct field Patch.tags of type []string"}`,
+		},
+		{
+			name:           "Update with invalid tag",
+			method:         http.MethodPatch,
+			target:         "/v1/files/" + testCid + "/metadata",
+			body:           `{"tags":["a,b"]}`,
+			contentType:    "application/json",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid file metadata: tags must have 1 to 64 characters and no commas, got \"a,b\""}`,
+		},
+		{
+			name:           "Remove tag",
+			method:         http.MethodDelete,
+			target:         "/v1/files/" + testCid + "/tags/confidential",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"cid":"` + testCid + `","name":"","filename":"","mime_type":"","size":0,"tags":["project-x"],` +
+				`"description":"Survey results","meta":{"owner":"research"},"uploaded_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Add tag",
+			method:         http.MethodPost,
+			target:         "/v1/files/" + testCid + "/tags",
+			body:           "tag=public",
+			contentType:    "application/x-www-form-urlencoded",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"cid":"` + testCid + `","name":"","filename":"","mime_type":"","size":0,"tags":["project-x","public"],` +
+				`"description":"Survey results","meta":{"owner":"research"},"uploaded_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Add empty tag",
+			method:         http.MethodPost,
+			target:         "/v1/files/" + testCid + "/tags",
+			contentType:    "application/x-www-form-urlencoded",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid file metadata: tags must have 1 to 64 characters and no commas, got \"\""}`,
+		},
+		{
+			name:           "Clear",
+			method:         http.MethodDelete,
+			target:         "/v1/files/" + testCid + "/metadata",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"cid":"` + testCid + `","name":"","filename":"","mime_type":"","size":0,"tags":[],` +
+				`"meta":{},"uploaded_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Clear unknown cid",
+			method:         http.MethodDelete,
+			target:         "/v1/files/" + testNewCid + "/metadata",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"file metadata not found: ` + testNewCid + `"}`,
+		},
+	}
+
+	// the cases run in order against the same handler
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestListPinsByTag(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	tag := func(c, tag string) {
+		r := httptest.NewRequest(http.MethodPost, "/v1/files/"+c+"/tags", strings.NewReader("tag="+tag))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	tag(testCid, "project-x")
+	tag(testCid, "public")
+	tag(testNewCid, "project-x")
+
+	listPins := func(query string) []string {
+		mockClient.EXPECT().ListPins(gomock.Any()).Return(map[string]any{"Keys": map[string]any{
+			testCid:    map[string]any{"Name": "report", "Type": "recursive"},
+			testNewCid: map[string]any{"Name": "dataset", "Type": "recursive"},
+			testPeerID: map[string]any{"Name": "", "Type": "indirect"},
+		}}, nil)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/pins"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Pins struct {
+				Keys map[string]any `json:"Keys"`
+			} `json:"pins"`
+			Files map[string]any `json:"files"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Subset(t, mapKeys(resp.Pins.Keys), mapKeys(resp.Files))
+		return mapKeys(resp.Pins.Keys)
+	}
+
+	require.Len(t, listPins(""), 3)
+	require.ElementsMatch(t, []string{testCid, testNewCid}, listPins("?tag=Project-X"))
+	require.ElementsMatch(t, []string{testCid}, listPins("?tag=project-x&tag=public"))
+	require.Empty(t, listPins("?tag=secret"))
+}
+
+// mapKeys returns the keys of m.
+func mapKeys(m map[string]any) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
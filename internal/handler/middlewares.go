@@ -2,9 +2,12 @@ package handler
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -24,14 +27,35 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// sandboxMiddleware is a middleware function that sandboxes the documents served by next with their content
+// security policy. A sandboxed document runs no scripts and has an origin of its own, so that content served in
+// Hive's origin cannot act as Hive.
+func sandboxMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "sandbox")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestTimeout is the deadline errorMiddleware applies to every request.
+const requestTimeout = 30 * time.Second
+
 // errorMiddleware is a middleware function that wraps a handler function and handles any errors that occur.
 // The wrapped handler function should return an error, which this middleware will handle.
 func errorMiddleware(f func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return timeoutErrorMiddleware(f, requestTimeout)
+}
+
+// timeoutErrorMiddleware is errorMiddleware with a custom request deadline. A zero timeout leaves the request
+// context without a deadline, for handlers that stream or run longer than a regular request.
+func timeoutErrorMiddleware(f func(http.ResponseWriter, *http.Request) error, timeout time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-		defer cancel()
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
 
-		r = r.WithContext(ctx)
+			r = r.WithContext(ctx)
+		}
 
 		startTime := time.Now()
 		err := f(w, r)
@@ -52,3 +76,62 @@ func errorMiddleware(f func(http.ResponseWriter, *http.Request) error) http.Hand
 		// log.Printf("Log => status: success, method: %s, path: %s, duration: %s", r.Method, r.URL.Path, duration)
 	}
 }
+
+// admin is a middleware function that restricts a handler to requests carrying the configured ADMIN_TOKEN as a
+// bearer token. Every request is rejected if no admin token is configured.
+func (h *handlerImpl) admin(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if h.config.ADMIN_TOKEN == "" {
+			return NewErrorStatus(fmt.Errorf("admin api is disabled"), http.StatusForbidden, 0)
+		}
+
+		if !h.isAdmin(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="hive"`)
+			return NewErrorStatus(fmt.Errorf("invalid admin token"), http.StatusUnauthorized, 0)
+		}
+		return f(w, r)
+	}
+}
+
+// isAdmin reports whether the request carries the configured ADMIN_TOKEN as a bearer token.
+func (h *handlerImpl) isAdmin(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && h.config.ADMIN_TOKEN != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.config.ADMIN_TOKEN)) == 1
+}
+
+// userKey is the request context key of the authenticated Hive user.
+type userKey struct{}
+
+// authenticate is a middleware function that restricts a handler to Hive users, identified by one of the
+// configured USER_TOKENS as a bearer token, and stores the user in the request context. Every request is
+// rejected if no user tokens are configured.
+func (h *handlerImpl) authenticate(f func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if len(h.config.USER_TOKENS) == 0 {
+			return NewErrorStatus(fmt.Errorf("user api is disabled"), http.StatusForbidden, 0)
+		}
+
+		user := h.user(r)
+		if user == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="hive"`)
+			return NewErrorStatus(fmt.Errorf("invalid user token"), http.StatusUnauthorized, 0)
+		}
+		return f(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	}
+}
+
+// user returns the Hive user identified by the bearer token of the request, or an empty string if the
+// request carries no known user token.
+func (h *handlerImpl) user(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return h.config.USER_TOKENS[token]
+}
+
+// userFromContext returns the Hive user stored in the context by authenticate.
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+const (
+	defaultPingCount = 10
+	maxPingCount     = 100
+)
+
+// pingReply is the "ping" event sent for each reply to a ping.
+type pingReply struct {
+	Seq     int     `json:"seq"`             // the sequence number of the ping, starting at 1.
+	Success bool    `json:"success"`         // whether the peer answered the ping.
+	RTT     float64 `json:"rtt_ms"`          // the round-trip time of the ping in milliseconds.
+	Error   string  `json:"error,omitempty"` // why the ping failed.
+}
+
+// pingStats accumulates the round-trip time and packet loss statistics of a ping run.
+type pingStats struct {
+	sent     int           // the number of pings sent.
+	received int           // the number of pings answered.
+	min      time.Duration // the shortest round-trip time.
+	max      time.Duration // the longest round-trip time.
+	total    time.Duration // the sum of all round-trip times.
+}
+
+// add records the reply to a ping and returns its event.
+func (s *pingStats) add(info ipfs.PingInfo) pingReply {
+	s.sent++
+	reply := pingReply{Seq: s.sent, Success: info.Success}
+	if !info.Success {
+		reply.Error = strings.TrimPrefix(info.Text, "Ping error: ")
+		return reply
+	}
+
+	if s.received == 0 || info.Time < s.min {
+		s.min = info.Time
+	}
+	if info.Time > s.max {
+		s.max = info.Time
+	}
+	s.received++
+	s.total += info.Time
+	reply.RTT = milliseconds(info.Time)
+	return reply
+}
+
+// summary returns the "stats" event of the ping run.
+func (s *pingStats) summary() any {
+	resp := struct {
+		Sent     int     `json:"sent"`
+		Received int     `json:"received"`
+		Loss     float64 `json:"loss_percent"`
+		Min      float64 `json:"min_ms"`
+		Avg      float64 `json:"avg_ms"`
+		Max      float64 `json:"max_ms"`
+	}{
+		Sent:     s.sent,
+		Received: s.received,
+		Min:      milliseconds(s.min),
+		Max:      milliseconds(s.max),
+	}
+	if s.sent > 0 {
+		resp.Loss = math.Round(float64(s.sent-s.received)/float64(s.sent)*10000) / 100
+	}
+	if s.received > 0 {
+		resp.Avg = milliseconds(s.total / time.Duration(s.received))
+	}
+	return resp
+}
+
+// milliseconds converts a duration to milliseconds, rounded to the microsecond.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// pingErrorStatus maps an error from a ping to its HTTP error status.
+func pingErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, ipfs.ErrInvalidPeer), errors.Is(err, ipfs.ErrPingSelf):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	case errors.Is(err, ipfs.ErrPeerNotFound), errors.Is(err, ipfs.ErrPeerUnreachable):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+// replyWith returns a mock Ping implementation passing the given replies to the callback and returning err.
+func replyWith(err error, replies ...ipfs.PingInfo) func(context.Context, string, int, func(ipfs.PingInfo) error) error {
+	return func(_ context.Context, _ string, _ int, fn func(ipfs.PingInfo) error) error {
+		for _, reply := range replies {
+			if err := fn(reply); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+func TestPingRoute(t *testing.T) {
+	tests := []struct {
+		name                string
+		target              string
+		setupMock           func(mockClient *mocked.MockClient)
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:   "Stream pings and stats",
+			target: "/v1/ping/" + testPeerID + "?count=3",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Ping(gomock.Any(), testPeerID, 3, gomock.Any()).DoAndReturn(replyWith(nil,
+					ipfs.PingInfo{Success: false, Text: "Ping error: stream reset"},
+					ipfs.PingInfo{Success: true, Time: 10 * time.Millisecond},
+					ipfs.PingInfo{Success: true, Time: 20500 * time.Microsecond},
+				))
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedBody: "event: ping\ndata: {\"seq\":1,\"success\":false,\"rtt_ms\":0,\"error\":\"stream reset\"}\n\n" +
+				"event: ping\ndata: {\"seq\":2,\"success\":true,\"rtt_ms\":10}\n\n" +
+				"event: ping\ndata: {\"seq\":3,\"success\":true,\"rtt_ms\":20.5}\n\n" +
+				"event: stats\ndata: {\"sent\":3,\"received\":2,\"loss_percent\":33.33,\"min_ms\":10,\"avg_ms\":15.25,\"max_ms\":20.5}",
+		},
+		{
+			name:   "Default count",
+			target: "/v1/ping/" + testPeerID,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Ping(gomock.Any(), testPeerID, defaultPingCount, gomock.Any()).DoAndReturn(replyWith(nil,
+					ipfs.PingInfo{Success: true, Time: time.Millisecond},
+				))
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedBody: "event: ping\ndata: {\"seq\":1,\"success\":true,\"rtt_ms\":1}\n\n" +
+				"event: stats\ndata: {\"sent\":1,\"received\":1,\"loss_percent\":0,\"min_ms\":1,\"avg_ms\":1,\"max_ms\":1}",
+		},
+		{
+			name:   "Ping self",
+			target: "/v1/ping/" + testPeerID,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Ping(gomock.Any(), testPeerID, defaultPingCount, gomock.Any()).Return(ipfs.ErrPingSelf)
+			},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"cannot ping self"}`,
+		},
+		{
+			name:   "Invalid peer ID",
+			target: "/v1/ping/not-a-peer",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Ping(gomock.Any(), "not-a-peer", defaultPingCount, gomock.Any()).Return(fmt.Errorf("%w: bad peer id", ipfs.ErrInvalidPeer))
+			},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"invalid peer: bad peer id"}`,
+		},
+		{
+			name:   "Peer lookup fails",
+			target: "/v1/ping/" + testPeerID,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Ping(gomock.Any(), testPeerID, defaultPingCount, gomock.Any()).Return(fmt.Errorf("%w: peer lookup failed: routing: not found", ipfs.ErrPeerNotFound))
+			},
+			expectedStatus:      http.StatusNotFound,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"peer not found: peer lookup failed: routing: not found"}`,
+		},
+		{
+			name:   "Peer never answers",
+			target: "/v1/ping/" + testPeerID,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Ping(gomock.Any(), testPeerID, defaultPingCount, gomock.Any()).DoAndReturn(replyWith(ipfs.ErrPeerUnreachable,
+					ipfs.PingInfo{Success: false, Text: "Ping error: failed to dial"},
+				))
+			},
+			expectedStatus:      http.StatusNotFound,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"peer unreachable"}`,
+		},
+		{
+			name:   "Error after the first reply",
+			target: "/v1/ping/" + testPeerID,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Ping(gomock.Any(), testPeerID, defaultPingCount, gomock.Any()).DoAndReturn(replyWith(errors.New("connection reset"),
+					ipfs.PingInfo{Success: true, Time: time.Millisecond},
+				))
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedBody: "event: ping\ndata: {\"seq\":1,\"success\":true,\"rtt_ms\":1}\n\n" +
+				"event: error\ndata: {\"error\":\"connection reset\"}",
+		},
+		{
+			name:                "Invalid count",
+			target:              "/v1/ping/" + testPeerID + "?count=0",
+			setupMock:           func(mockClient *mocked.MockClient) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"count must be between 1 and 100"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/zde37/Hive/internal/pinhealth"
+)
+
+// getPinHealth retrieves the report of the last pin verification and whether a verification is running.
+func (h *handlerImpl) GetPinHealth(w http.ResponseWriter, r *http.Request) error {
+	resp := struct {
+		Running bool              `json:"running"`
+		Report  *pinhealth.Report `json:"report"`
+	}{
+		Running: h.checker.Running(),
+		Report:  h.checker.Latest(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// checkPinHealth starts a pin verification in the background. The "repair" query parameter fetches missing
+// and corrupt blocks again from the network.
+func (h *handlerImpl) CheckPinHealth(w http.ResponseWriter, r *http.Request) error {
+	repair := false
+	if v := r.URL.Query().Get("repair"); v != "" {
+		var err error
+		if repair, err = strconv.ParseBool(v); err != nil {
+			return NewErrorStatus(fmt.Errorf("repair must be a boolean"), http.StatusBadRequest, 0)
+		}
+	}
+
+	// the verification outlives the request
+	err := h.checker.RunAsync(context.WithoutCancel(r.Context()), repair)
+	if errors.Is(err, pinhealth.ErrRunning) {
+		return NewErrorStatus(err, http.StatusConflict, 0)
+	}
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Status string `json:"status"`
+	}{
+		Status: "started",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(resp)
+}
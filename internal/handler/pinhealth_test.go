@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPinHealth(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	request := func(method, target, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request(http.MethodGet, "/v1/pins/health", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"running":false,"report":null}`, strings.TrimSpace(w.Body.String()))
+
+	w = request(http.MethodPost, "/v1/pins/health", "")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request(http.MethodPost, "/v1/pins/health?repair=maybe", testAdminToken)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"repair must be a boolean"}`, strings.TrimSpace(w.Body.String()))
+
+	release := make(chan struct{})
+	mockClient.EXPECT().VerifyRepo(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, func(ipfs.BadBlock) error) (int, error) {
+			<-release
+			return 1, nil
+		})
+	mockClient.EXPECT().VerifyPins(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(ipfs.PinVerification) error) error {
+			return fn(ipfs.PinVerification{Cid: testCid, Ok: false, BadNodes: []ipfs.BadBlock{{Cid: testCid, Error: "not found"}}})
+		})
+	mockClient.EXPECT().FetchBlock(gomock.Any(), testCid).Return(nil)
+
+	w = request(http.MethodPost, "/v1/pins/health?repair=true", testAdminToken)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Equal(t, `{"status":"started"}`, strings.TrimSpace(w.Body.String()))
+
+	w = request(http.MethodPost, "/v1/pins/health", testAdminToken)
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Equal(t, `{"error":"pin verification already running"}`, strings.TrimSpace(w.Body.String()))
+
+	close(release)
+	require.Eventually(t, func() bool {
+		body := request(http.MethodGet, "/v1/pins/health", "").Body.String()
+		return strings.Contains(body, `"running":false`) &&
+			strings.Contains(body, `"unhealthy":[{"cid":"`+testCid+`","bad_blocks":[{"cid":"`+testCid+`","error":"not found","corrupt":false,"repaired":true}]}]`)
+	}, 5*time.Second, 10*time.Millisecond)
+}
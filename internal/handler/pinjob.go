@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/zde37/Hive/internal/pinjob"
+)
+
+// listPinJobs retrieves every pin job, newest first.
+func (h *handlerImpl) ListPinJobs(w http.ResponseWriter, r *http.Request) error {
+	jobs, err := h.pinJobs.List()
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Jobs []pinjob.Job `json:"jobs"`
+	}{
+		Jobs: jobs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// getPinJob retrieves the state and progress of a pin job.
+func (h *handlerImpl) GetPinJob(w http.ResponseWriter, r *http.Request) error {
+	job, err := h.pinJobs.Get(r.PathValue("id"))
+	if err != nil {
+		return pinJobErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(job)
+}
+
+// deletePinJob cancels a queued or running pin job, which is kept as cancelled, or deletes a finished one.
+func (h *handlerImpl) DeletePinJob(w http.ResponseWriter, r *http.Request) error {
+	id := r.PathValue("id")
+	job, err := h.pinJobs.Cancel(id)
+	if errors.Is(err, pinjob.ErrFinished) {
+		err = h.pinJobs.Delete(id)
+	}
+	if err != nil {
+		return pinJobErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(job)
+}
+
+// pinJobErrorStatus maps a pin job error to the matching HTTP status.
+func pinJobErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, pinjob.ErrNotFound):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	case errors.Is(err, pinjob.ErrFinished), errors.Is(err, pinjob.ErrUnfinished):
+		return NewErrorStatus(err, http.StatusConflict, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/pinjob"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPinObject(t *testing.T) {
+	tests := []struct {
+		name           string
+		form           url.Values
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Missing name",
+			form:           url.Values{"cid": {testCid}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"name and cid is required"}`,
+		},
+		{
+			name:           "Invalid cid",
+			form:           url.Values{"cid": {"not-a-cid"}, "name": {"docs"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid cid \"not-a-cid\""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, handler := newTestHandler(t)
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/pin", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestPinJobs(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	request := func(method, target string) *httptest.ResponseRecorder {
+		var body *strings.Reader
+		if method == http.MethodPost {
+			body = strings.NewReader(url.Values{"cid": {testCid}, "name": {"docs"}}.Encode())
+		} else {
+			body = strings.NewReader("")
+		}
+		r := httptest.NewRequest(method, target, body)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	pinned := make(chan struct{})
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs", "/ipfs/"+testCid, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _ string, fn func(int) error) error {
+			if err := fn(4); err != nil {
+				return err
+			}
+			<-pinned
+			return nil
+		})
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs", "/ipfs/"+testCid, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ string, _ func(int) error) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).MaxTimes(1) // the second job may be cancelled before it starts
+
+	w := request(http.MethodPost, "/v1/pin")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var job pinjob.Job
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&job))
+	require.Equal(t, "/v1/pin/jobs/"+job.ID, w.Header().Get("Location"))
+	require.Equal(t, testCid, job.Cid)
+	require.Equal(t, pinjob.StateQueued, job.State)
+
+	require.Eventually(t, func() bool {
+		w := request(http.MethodGet, "/v1/pin/jobs/"+job.ID)
+		return w.Code == http.StatusOK && strings.Contains(w.Body.String(), `"state":"pinning","blocks":4`)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(pinned)
+	require.Eventually(t, func() bool {
+		w := request(http.MethodGet, "/v1/pin/jobs/"+job.ID)
+		return strings.Contains(w.Body.String(), `"state":"pinned"`)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	w = request(http.MethodPost, "/v1/pin")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var second pinjob.Job
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&second))
+
+	// unfinished jobs are cancelled and kept
+	mockClient.EXPECT().ListRecursivePins(gomock.Any()).Return(map[string]string{}, nil)
+	w = request(http.MethodDelete, "/v1/pin/jobs/"+second.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"state":"cancelled"`)
+
+	w = request(http.MethodGet, "/v1/pin/jobs")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Jobs []pinjob.Job `json:"jobs"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp.Jobs, 2)
+
+	// finished ones are deleted
+	for _, id := range []string{job.ID, second.ID} {
+		w = request(http.MethodDelete, "/v1/pin/jobs/"+id)
+		require.Equal(t, http.StatusOK, w.Code)
+		w = request(http.MethodGet, "/v1/pin/jobs/"+id)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	}
+	w = request(http.MethodGet, "/v1/pin/jobs")
+	require.JSONEq(t, `{"jobs":[]}`, w.Body.String())
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w = request(method, "/v1/pin/jobs/missing")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, `{"error":"pin job not found"}`, strings.TrimSpace(w.Body.String()))
+	}
+}
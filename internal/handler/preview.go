@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+const (
+	maxPreviewText = 64 << 10 // bounds the text returned by a preview, 64KB.
+	sniffLength    = 512      // the number of bytes a MIME type is sniffed from.
+
+	// previewPolicy keeps previewed content from loading anything but itself, so that a preview cannot run
+	// scripts or reach other resources in Hive's origin.
+	previewPolicy = "default-src 'none'; img-src 'self'; media-src 'self'; style-src 'unsafe-inline'"
+)
+
+// previewTypes are the MIME types outside of image/*, audio/* and video/* that are previewed inline.
+var previewTypes = map[string]bool{
+	"application/ogg": true,
+	"application/pdf": true,
+}
+
+// previewErrorStatus maps an error opening a file to preview to its HTTP error status.
+func previewErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, ipfs.ErrNotFile), errors.Is(err, ipfs.ErrInvalidPath):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
+
+// preview handles a request to preview a file safely. Its MIME type is sniffed from its first bytes rather than
+// trusted from its name: text is returned as a JSON string of up to 64KB, images, audio, video and PDFs are
+// streamed inline with their type, and any other content is refused. Previews are sandboxed by their content
+// security policy.
+func (h *handlerImpl) Preview(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", previewPolicy+"; sandbox")
+
+	c, err := pathCid(r)
+	if err != nil {
+		return err
+	}
+	if err := h.checkDenylist(r, "preview", "/ipfs/"+c); err != nil {
+		return err
+	}
+
+	file, err := h.ipfs.OpenFile(r.Context(), "/ipfs/"+c)
+	if err != nil {
+		return previewErrorStatus(err)
+	}
+	defer file.Close()
+
+	size, err := file.Size()
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		data, err := io.ReadAll(io.LimitReader(io.MultiReader(bytes.NewReader(head), file), maxPreviewText))
+		if err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+
+		resp := struct {
+			Cid       string `json:"cid"`
+			MimeType  string `json:"mime_type"`
+			Size      int64  `json:"size"`
+			Truncated bool   `json:"truncated"`
+			Content   string `json:"content"`
+		}{
+			Cid:       c,
+			MimeType:  contentType,
+			Size:      size,
+			Truncated: size > maxPreviewText,
+			Content:   strings.ToValidUTF8(string(data), ""),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(resp)
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"), previewTypes[mediaType]:
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+		filename, _, err := h.downloadName(c)
+		if err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+
+		// the PDF viewers of browsers do not run in sandboxed documents, and run the scripts of PDFs in their
+		// own origin
+		if mediaType == "application/pdf" {
+			w.Header().Set("Content-Security-Policy", previewPolicy)
+		}
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+		w.Header().Set("Content-Type", contentType)
+		http.ServeContent(w, r, "", time.Time{}, file)
+		return nil
+	default:
+		return NewErrorStatus(fmt.Errorf("cannot preview %s content", mediaType), http.StatusUnsupportedMediaType, 0)
+	}
+}
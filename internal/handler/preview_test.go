@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	"go.uber.org/mock/gomock"
+)
+
+// testFile is an in-memory ipfs.FileReader.
+type testFile struct {
+	*bytes.Reader
+}
+
+func (f testFile) Close() error { return nil }
+
+func (f testFile) Size() (int64, error) { return f.Reader.Size(), nil }
+
+func newTestFile(content string) ipfs.FileReader {
+	return testFile{bytes.NewReader([]byte(content))}
+}
+
+func TestPreview(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 64)
+
+	serve := func(content string, rangeHeader string) *httptest.ResponseRecorder {
+		mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testCid).Return(newTestFile(content), nil)
+		r := httptest.NewRequest(http.MethodGet, "/v1/preview/"+testCid, nil)
+		if rangeHeader != "" {
+			r.Header.Set("Range", rangeHeader)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		require.Contains(t, w.Header().Get("Content-Security-Policy"), "default-src 'none'")
+		return w
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		w := serve("<html><script>alert(1)</script></html>", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		// the markup is escaped and never rendered
+		require.Equal(t, `{"cid":"`+testCid+`","mime_type":"text/html; charset=utf-8","size":38,"truncated":false,`+
+			`"content":"\u003chtml\u003e\u003cscript\u003ealert(1)\u003c/script\u003e\u003c/html\u003e"}`,
+			strings.TrimSpace(w.Body.String()))
+	})
+
+	t.Run("Large text is truncated", func(t *testing.T) {
+		w := serve(strings.Repeat("é", maxPreviewText), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Size      int64  `json:"size"`
+			Truncated bool   `json:"truncated"`
+			Content   string `json:"content"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Equal(t, int64(2*maxPreviewText), resp.Size)
+		require.True(t, resp.Truncated)
+		require.Equal(t, strings.Repeat("é", maxPreviewText/2), resp.Content)
+	})
+
+	t.Run("Image", func(t *testing.T) {
+		w := serve(png, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		require.Equal(t, "inline; filename="+testCid, w.Header().Get("Content-Disposition"))
+		require.Contains(t, w.Header().Get("Content-Security-Policy"), "sandbox")
+		require.Equal(t, png, w.Body.String())
+	})
+
+	t.Run("Range of a video", func(t *testing.T) {
+		webm := "\x1a\x45\xdf\xa3" + strings.Repeat("v", 1000)
+		w := serve(webm, "bytes=0-3")
+		require.Equal(t, http.StatusPartialContent, w.Code)
+		require.Equal(t, "video/webm", w.Header().Get("Content-Type"))
+		require.Equal(t, "bytes 0-3/1004", w.Header().Get("Content-Range"))
+		require.Equal(t, webm[:4], w.Body.String())
+	})
+
+	t.Run("PDF", func(t *testing.T) {
+		w := serve("%PDF-1.4\n%âãÏÓ\n", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		require.NotContains(t, w.Header().Get("Content-Security-Policy"), "sandbox")
+	})
+
+	t.Run("Binary is refused", func(t *testing.T) {
+		w := serve("\x7fELF\x02\x01\x01\x00", "")
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		require.Equal(t, `{"error":"cannot preview application/octet-stream content"}`, strings.TrimSpace(w.Body.String()))
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testCid).Return(nil, fmt.Errorf("%w: /ipfs/%s", ipfs.ErrNotFile, testCid))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/preview/"+testCid, nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, `{"error":"not a file: /ipfs/`+testCid+`"}`, strings.TrimSpace(w.Body.String()))
+	})
+
+	t.Run("Invalid cid", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/preview/nonsense", nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
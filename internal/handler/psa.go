@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/zde37/Hive/internal/config"
+	"github.com/zde37/Hive/internal/psa"
+)
+
+const (
+	defaultPSALimit = 10   // the default number of pins listed per page.
+	maxPSALimit     = 1000 // the maximum number of pins listed per page.
+	maxPSACids      = 10   // the maximum number of CIDs a listing can be filtered by.
+	maxPSANameLen   = 255  // the maximum length of a pin name.
+	maxPSAOrigins   = 20   // the maximum number of origins of a pin.
+)
+
+// psaErrorMiddleware is errorMiddleware for the Pinning Service API, which reports errors as
+// {"error":{"reason","details"}} objects.
+func psaErrorMiddleware(f func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return errorMiddleware(func(w http.ResponseWriter, r *http.Request) error {
+		err := f(w, r)
+		if err == nil {
+			return nil
+		}
+
+		errRes, statusCode, errLevel := ErrorInfo(err)
+		if errLevel == 1 {
+			log.Printf("Log => status: failed, error: %s, status_code: %d, method: %s, path: %s", errRes, statusCode, r.Method, r.URL.Path)
+		}
+
+		resp := struct {
+			Error struct {
+				Reason  string `json:"reason"`
+				Details string `json:"details"`
+			} `json:"error"`
+		}{}
+		resp.Error.Reason = strings.ToUpper(strings.ReplaceAll(http.StatusText(statusCode), " ", "_"))
+		resp.Error.Details = errRes.Error
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		return json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// psaErrorStatus maps an error of the pinning service to its HTTP error status.
+func psaErrorStatus(err error) error {
+	if errors.Is(err, psa.ErrNotFound) {
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	}
+	return NewErrorStatus(err, http.StatusInternalServerError, 1)
+}
+
+// decodePSAPin decodes and validates the pin object in the body of a Pinning Service API request.
+func decodePSAPin(r *http.Request) (psa.Pin, error) {
+	var pin psa.Pin
+	if err := json.NewDecoder(r.Body).Decode(&pin); err != nil {
+		return psa.Pin{}, NewErrorStatus(fmt.Errorf("invalid pin: %v", err), http.StatusBadRequest, 0)
+	}
+	if _, err := cid.Decode(pin.Cid); err != nil {
+		return psa.Pin{}, NewErrorStatus(fmt.Errorf("invalid cid %q", pin.Cid), http.StatusBadRequest, 0)
+	}
+	if len(pin.Name) > maxPSANameLen {
+		return psa.Pin{}, NewErrorStatus(fmt.Errorf("name must be at most %d characters", maxPSANameLen), http.StatusBadRequest, 0)
+	}
+	if len(pin.Origins) > maxPSAOrigins {
+		return psa.Pin{}, NewErrorStatus(fmt.Errorf("at most %d origins are allowed", maxPSAOrigins), http.StatusBadRequest, 0)
+	}
+	return pin, nil
+}
+
+// parsePSAQuery parses the filters of a Pinning Service API pin listing. Only pinned pins are listed if no
+// status is given.
+func parsePSAQuery(r *http.Request) (psa.Query, error) {
+	values := r.URL.Query()
+	query := psa.Query{
+		Cids:     config.ParseList(values.Get("cid")),
+		Name:     values.Get("name"),
+		Match:    values.Get("match"),
+		Statuses: []psa.Status{psa.StatusPinned},
+		Limit:    defaultPSALimit,
+	}
+
+	if len(query.Cids) > maxPSACids {
+		return psa.Query{}, fmt.Errorf("at most %d cids are allowed", maxPSACids)
+	}
+	if len(query.Name) > maxPSANameLen {
+		return psa.Query{}, fmt.Errorf("name must be at most %d characters", maxPSANameLen)
+	}
+	if query.Match != "" && !slices.Contains([]string{"exact", "iexact", "partial", "ipartial"}, query.Match) {
+		return psa.Query{}, fmt.Errorf("invalid match %q", query.Match)
+	}
+	if v := values.Get("status"); v != "" {
+		query.Statuses = nil
+		for _, status := range config.ParseList(v) {
+			if !slices.Contains(psa.Statuses, psa.Status(status)) {
+				return psa.Query{}, fmt.Errorf("invalid status %q", status)
+			}
+			query.Statuses = append(query.Statuses, psa.Status(status))
+		}
+	}
+	for name, t := range map[string]*time.Time{"before": &query.Before, "after": &query.After} {
+		if v := values.Get(name); v != "" {
+			var err error
+			if *t, err = time.Parse(time.RFC3339, v); err != nil {
+				return psa.Query{}, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+			}
+		}
+	}
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPSALimit {
+			return psa.Query{}, fmt.Errorf("limit must be between 1 and %d", maxPSALimit)
+		}
+		query.Limit = limit
+	}
+	if v := values.Get("meta"); v != "" {
+		if err := json.Unmarshal([]byte(v), &query.Meta); err != nil {
+			return psa.Query{}, fmt.Errorf("meta must be a JSON object of strings")
+		}
+	}
+	return query, nil
+}
+
+// listPSAPins lists the pin requests of the user, implementing GET /pins of the Pinning Service API.
+func (h *handlerImpl) ListPSAPins(w http.ResponseWriter, r *http.Request) error {
+	query, err := parsePSAQuery(r)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	}
+
+	count, results, err := h.pinService.List(r.Context(), userFromContext(r.Context()), query)
+	if err != nil {
+		return psaErrorStatus(err)
+	}
+
+	resp := struct {
+		Count   int             `json:"count"`
+		Results []psa.PinStatus `json:"results"`
+	}{
+		Count:   count,
+		Results: results,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// addPSAPin requests the pin of an object for the user, implementing POST /pins of the Pinning Service API.
+func (h *handlerImpl) AddPSAPin(w http.ResponseWriter, r *http.Request) error {
+	pin, err := decodePSAPin(r)
+	if err != nil {
+		return err
+	}
+	if err := h.checkDenylist(r, "pin", "/ipfs/"+pin.Cid); err != nil {
+		return err
+	}
+
+	status, err := h.pinService.Add(r.Context(), userFromContext(r.Context()), pin)
+	if err != nil {
+		return psaErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(status)
+}
+
+// getPSAPin retrieves a pin request of the user, implementing GET /pins/{requestid} of the Pinning Service
+// API.
+func (h *handlerImpl) GetPSAPin(w http.ResponseWriter, r *http.Request) error {
+	status, err := h.pinService.Get(r.Context(), userFromContext(r.Context()), r.PathValue("requestid"))
+	if err != nil {
+		return psaErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(status)
+}
+
+// replacePSAPin replaces a pin request of the user by a new one, implementing POST /pins/{requestid} of the
+// Pinning Service API.
+func (h *handlerImpl) ReplacePSAPin(w http.ResponseWriter, r *http.Request) error {
+	pin, err := decodePSAPin(r)
+	if err != nil {
+		return err
+	}
+	if err := h.checkDenylist(r, "pin", "/ipfs/"+pin.Cid); err != nil {
+		return err
+	}
+
+	status, err := h.pinService.Replace(r.Context(), userFromContext(r.Context()), r.PathValue("requestid"), pin)
+	if err != nil {
+		return psaErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(status)
+}
+
+// removePSAPin removes a pin request of the user, implementing DELETE /pins/{requestid} of the Pinning
+// Service API.
+func (h *handlerImpl) RemovePSAPin(w http.ResponseWriter, r *http.Request) error {
+	if err := h.pinService.Remove(r.Context(), userFromContext(r.Context()), r.PathValue("requestid")); err != nil {
+		return psaErrorStatus(err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
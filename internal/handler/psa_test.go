@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/config"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"github.com/zde37/Hive/internal/pinjob"
+	"github.com/zde37/Hive/internal/psa"
+	"github.com/zde37/Hive/internal/store"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPSAAuthentication(t *testing.T) {
+	_, handler := newTestHandler(t)
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Missing token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":{"reason":"UNAUTHORIZED","details":"invalid user token"}}`,
+		},
+		{
+			name:           "Admin token",
+			token:          testAdminToken,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":{"reason":"UNAUTHORIZED","details":"invalid user token"}}`,
+		},
+		{
+			name:           "Invalid query",
+			token:          testUserToken,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"reason":"BAD_REQUEST","details":"invalid status \"lost\""}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/psa/pins?status=lost", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestPSADisabled(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	defer st.Close()
+
+	jobs := pinjob.NewManager(mockClient, st, 1)
+	handler := NewHandlerImpl(mockClient, &config.Config{}, WithPinService(psa.NewService(mockClient, st, jobs, nil))).Mux()
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/psa/pins", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, `{"error":{"reason":"FORBIDDEN","details":"user api is disabled"}}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestPSA(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+	mockClient.EXPECT().Self(gomock.Any()).Return(ipfs.NodeInfo{
+		ID:        "12D3KooWSelf",
+		Addresses: []string{"/ip4/127.0.0.1/tcp/4001/p2p/12D3KooWSelf"},
+	}, nil).AnyTimes()
+
+	request := func(method, target, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) psa.PinStatus {
+		var status psa.PinStatus
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+		return status
+	}
+
+	w := request(http.MethodPost, "/v1/psa/pins", testUserToken, `{"cid":"not-a-cid"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":{"reason":"BAD_REQUEST","details":"invalid cid \"not-a-cid\""}}`, strings.TrimSpace(w.Body.String()))
+
+	mockClient.EXPECT().ListRecursivePins(gomock.Any()).Return(map[string]string{}, nil)
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs", "/ipfs/"+testCid, gomock.Any()).Return(nil)
+	w = request(http.MethodPost, "/v1/psa/pins", testUserToken, `{"cid":"`+testCid+`","name":"docs","meta":{"app":"kubo"}}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	pin := decode(w)
+	require.Equal(t, psa.Pin{Cid: testCid, Name: "docs", Meta: map[string]string{"app": "kubo"}}, pin.Pin)
+	require.Equal(t, []string{"/ip4/127.0.0.1/tcp/4001/p2p/12D3KooWSelf"}, pin.Delegates)
+
+	require.Eventually(t, func() bool {
+		w := request(http.MethodGet, "/v1/psa/pins/"+pin.RequestID, testUserToken, "")
+		return w.Code == http.StatusOK && decode(w).Status == psa.StatusPinned
+	}, 5*time.Second, 10*time.Millisecond)
+
+	w = request(http.MethodGet, "/v1/psa/pins?cid="+testCid+"&meta="+`{"app":"kubo"}`, testUserToken, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Count   int             `json:"count"`
+		Results []psa.PinStatus `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Equal(t, 1, list.Count)
+	require.Equal(t, pin.RequestID, list.Results[0].RequestID)
+
+	// the pins of other users are invisible
+	w = request(http.MethodGet, "/v1/psa/pins", "bob-token", "")
+	require.Equal(t, `{"count":0,"results":[]}`, strings.TrimSpace(w.Body.String()))
+	w = request(http.MethodGet, "/v1/psa/pins/"+pin.RequestID, "bob-token", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, `{"error":{"reason":"NOT_FOUND","details":"pin request not found: `+pin.RequestID+`"}}`, strings.TrimSpace(w.Body.String()))
+
+	// the object stays pinned when it is replaced by a request for the same CID
+	replaced := make(chan struct{})
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs-v2", "/ipfs/"+testCid, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ string, _ func(int) error) error {
+			<-replaced
+			return nil
+		})
+	w = request(http.MethodPost, "/v1/psa/pins/"+pin.RequestID, testUserToken, `{"cid":"`+testCid+`","name":"docs-v2"}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	newPin := decode(w)
+	require.NotEqual(t, pin.RequestID, newPin.RequestID)
+	close(replaced)
+
+	require.Eventually(t, func() bool {
+		w := request(http.MethodGet, "/v1/psa/pins/"+newPin.RequestID, testUserToken, "")
+		return decode(w).Status == psa.StatusPinned
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+testCid).Return(nil)
+	w = request(http.MethodDelete, "/v1/psa/pins/"+newPin.RequestID, testUserToken, "")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Empty(t, w.Body.String())
+
+	w = request(http.MethodDelete, "/v1/psa/pins/"+newPin.RequestID, testUserToken, "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
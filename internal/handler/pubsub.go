@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+const maxPubsubMessageSize = 1024 * 1024 // 1MB in bytes, the largest message the pubsub router relays
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// pubsubFrame is the JSON frame a WebSocket client sends to publish a message on the bridged topic.
+type pubsubFrame struct {
+	Data []byte `json:"data"` // the base64 encoded message payload.
+}
+
+// allowedTopic returns the topic in the request path, or an error if it is not in the configured allow-list.
+func (h *handlerImpl) allowedTopic(r *http.Request) (string, error) {
+	topic := r.PathValue("topic")
+	if topic == "" {
+		return "", NewErrorStatus(fmt.Errorf("topic is required"), http.StatusBadRequest, 0)
+	}
+	if !h.config.PubsubTopicAllowed(topic) {
+		return "", NewErrorStatus(fmt.Errorf("topic %q is not allowed", topic), http.StatusForbidden, 0)
+	}
+	return topic, nil
+}
+
+// listPubsubTopics is an HTTP handler that returns the allowed pubsub topics the IPFS node is subscribed to.
+func (h *handlerImpl) ListPubsubTopics(w http.ResponseWriter, r *http.Request) error {
+	topics, err := h.ipfs.PubsubTopics(r.Context())
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	allowed := []string{}
+	for _, topic := range topics {
+		if h.config.PubsubTopicAllowed(topic) {
+			allowed = append(allowed, topic)
+		}
+	}
+
+	resp := struct {
+		Topics []string `json:"topics"`
+	}{
+		Topics: allowed,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// listPubsubPeers is an HTTP handler that returns the peers the IPFS node is connected to on a pubsub topic.
+func (h *handlerImpl) ListPubsubPeers(w http.ResponseWriter, r *http.Request) error {
+	topic, err := h.allowedTopic(r)
+	if err != nil {
+		return err
+	}
+
+	peers, err := h.ipfs.PubsubPeers(r.Context(), topic)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Peers []string `json:"peers"`
+		Total int      `json:"total"`
+	}{
+		Peers: peers,
+		Total: len(peers),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// publishPubsub is an HTTP handler that publishes the request body to a pubsub topic.
+func (h *handlerImpl) PublishPubsub(w http.ResponseWriter, r *http.Request) error {
+	topic, err := h.allowedTopic(r)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPubsubMessageSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return NewErrorStatus(fmt.Errorf("message size exceeds the maximum limit of 1MB"), http.StatusRequestEntityTooLarge, 0)
+		}
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	}
+
+	if err := h.ipfs.PubsubPublish(r.Context(), topic, data); err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Status string `json:"status"`
+	}{
+		Status: "success",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// pubsubBridge upgrades the request to a WebSocket and relays messages between it and a pubsub topic.
+// Every message received on the topic is sent as a JSON ipfs.PubsubMessage frame, and every pubsubFrame
+// received from the socket is published to the topic.
+func (h *handlerImpl) PubsubBridge(w http.ResponseWriter, r *http.Request) error {
+	topic, err := h.allowedTopic(r)
+	if err != nil {
+		return err
+	}
+	if !websocket.IsWebSocketUpgrade(r) {
+		return NewErrorStatus(fmt.Errorf("websocket upgrade required"), http.StatusBadRequest, 0)
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil // the upgrader has already replied to the client
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxPubsubMessageSize)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- h.ipfs.PubsubSubscribe(ctx, topic, func(msg ipfs.PubsubMessage) error {
+			return conn.WriteJSON(msg)
+		})
+	}()
+	go func() {
+		for {
+			var frame pubsubFrame
+			if err := conn.ReadJSON(&frame); err != nil {
+				errs <- err
+				return
+			}
+			if err := h.ipfs.PubsubPublish(ctx, topic, frame.Data); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	err = <-errs
+	cancel()
+
+	closeCode, closeText := websocket.CloseNormalClosure, ""
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil, websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway):
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		closeCode, closeText = websocket.CloseUnsupportedData, "invalid frame"
+	default:
+		log.Printf("Log => status: failed, error: %s, method: %s, path: %s", err, r.Method, r.URL.Path)
+		closeCode, closeText = websocket.CloseInternalServerErr, "pubsub relay failed"
+	}
+
+	// WriteControl is safe to call while the subscription may still be writing a frame
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, closeText), time.Now().Add(time.Second))
+	return nil
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPubsubRoutes(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		setupMock      func(mockClient *mocked.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "List only allowed topics",
+			method: http.MethodGet,
+			target: "/v1/pubsub",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().PubsubTopics(gomock.Any()).Return([]string{"hive", "private"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"topics":["hive"]}`,
+		},
+		{
+			name:   "List peers",
+			method: http.MethodGet,
+			target: "/v1/pubsub/hive/peers",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().PubsubPeers(gomock.Any(), "hive").Return([]string{"12D3KooWPeer"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"peers":["12D3KooWPeer"],"total":1}`,
+		},
+		{
+			name:           "List peers of a forbidden topic",
+			method:         http.MethodGet,
+			target:         "/v1/pubsub/private/peers",
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"topic \"private\" is not allowed"}`,
+		},
+		{
+			name:   "Publish",
+			method: http.MethodPost,
+			target: "/v1/pubsub/hive",
+			body:   "hello",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().PubsubPublish(gomock.Any(), "hive", []byte("hello")).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"success"}`,
+		},
+		{
+			name:           "Publish to a forbidden topic",
+			method:         http.MethodPost,
+			target:         "/v1/pubsub/private",
+			body:           "hello",
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"error":"topic \"private\" is not allowed"}`,
+		},
+		{
+			name:           "Bridge without upgrade",
+			method:         http.MethodGet,
+			target:         "/v1/pubsub/hive",
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"websocket upgrade required"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestPubsubBridge(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	published := make(chan []byte, 1)
+	mockClient.EXPECT().PubsubSubscribe(gomock.Any(), "hive", gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string, fn func(ipfs.PubsubMessage) error) error {
+			err := fn(ipfs.PubsubMessage{
+				From:   "12D3KooWPeer",
+				Seqno:  "17d1",
+				Topics: []string{"hive"},
+				Data:   []byte("from the network"),
+			})
+			if err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return nil
+		})
+	mockClient.EXPECT().PubsubPublish(gomock.Any(), "hive", []byte("from the socket")).DoAndReturn(
+		func(_ context.Context, _ string, data []byte) error {
+			published <- data
+			return nil
+		})
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/pubsub/hive"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var msg ipfs.PubsubMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	require.Equal(t, "12D3KooWPeer", msg.From)
+	require.Equal(t, "17d1", msg.Seqno)
+	require.Equal(t, []byte("from the network"), msg.Data)
+
+	require.NoError(t, conn.WriteJSON(pubsubFrame{Data: []byte("from the socket")}))
+	select {
+	case data := <-published:
+		require.Equal(t, []byte("from the socket"), data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("frame was not published")
+	}
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseUnsupportedData))
+}
+
+func TestPubsubBridgeForbiddenTopic(t *testing.T) {
+	_, handler := newTestHandler(t)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/pubsub/private"
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/zde37/Hive/internal/config"
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+// remoteErrorStatus maps an error from a remote pinning operation to its HTTP error status.
+func remoteErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, ipfs.ErrInvalidService), errors.Is(err, ipfs.ErrInvalidPath),
+		errors.Is(err, ipfs.ErrInvalidRemoteStatus):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	case errors.Is(err, ipfs.ErrServiceNotFound):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	case errors.Is(err, ipfs.ErrServiceExists):
+		return NewErrorStatus(err, http.StatusConflict, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
+
+// listRemoteServices handles a request to list the remote pinning services of the IPFS node. The "stat" query
+// parameter contacts every service for its pin counts.
+func (h *handlerImpl) ListRemoteServices(w http.ResponseWriter, r *http.Request) error {
+	stat := false
+	if v := r.URL.Query().Get("stat"); v != "" {
+		var err error
+		if stat, err = strconv.ParseBool(v); err != nil {
+			return NewErrorStatus(fmt.Errorf("stat must be a boolean"), http.StatusBadRequest, 0)
+		}
+	}
+
+	services, err := h.ipfs.ListRemoteServices(r.Context(), stat)
+	if err != nil {
+		return remoteErrorStatus(err)
+	}
+
+	resp := struct {
+		Services []ipfs.RemoteService `json:"services"`
+	}{
+		Services: services,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// addRemoteService handles a request to configure the Pinning Service API at "endpoint" as a remote pinning
+// service called "name", authenticated with the access token in "key".
+func (h *handlerImpl) AddRemoteService(w http.ResponseWriter, r *http.Request) error {
+	name, endpoint, key := r.FormValue("name"), r.FormValue("endpoint"), r.FormValue("key")
+	if name == "" || endpoint == "" || key == "" {
+		return NewErrorStatus(fmt.Errorf("name, endpoint and key are required"), http.StatusBadRequest, 0)
+	}
+
+	if err := h.ipfs.AddRemoteService(r.Context(), name, endpoint, key); err != nil {
+		return remoteErrorStatus(err)
+	}
+	return writeSuccess(w, http.StatusCreated)
+}
+
+// removeRemoteService handles a request to remove a remote pinning service. Its pins stay on the service.
+func (h *handlerImpl) RemoveRemoteService(w http.ResponseWriter, r *http.Request) error {
+	if err := h.ipfs.RemoveRemoteService(r.Context(), r.PathValue("name")); err != nil {
+		return remoteErrorStatus(err)
+	}
+	return writeSuccess(w, http.StatusOK)
+}
+
+// listRemotePins handles a request to list the pins on a remote pinning service, optionally limited to the
+// comma separated CIDs and statuses of the "cid" and "status" query parameters.
+func (h *handlerImpl) ListRemotePins(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	cids := config.ParseList(query.Get("cid"))
+	statuses := config.ParseList(query.Get("status"))
+
+	pins, err := h.ipfs.ListRemotePins(r.Context(), r.PathValue("name"), cids, statuses)
+	if err != nil {
+		return remoteErrorStatus(err)
+	}
+
+	resp := struct {
+		Pins []ipfs.RemotePin `json:"pins"`
+	}{
+		Pins: pins,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// addRemotePin handles a request to mirror the "cid" to a remote pinning service under "name". The service
+// pins it in the background, so the response is the queued pin.
+func (h *handlerImpl) AddRemotePin(w http.ResponseWriter, r *http.Request) error {
+	c := r.FormValue("cid")
+	if c == "" {
+		return NewErrorStatus(fmt.Errorf("cid is required"), http.StatusBadRequest, 0)
+	}
+	if err := h.checkDenylist(r, "pin", "/ipfs/"+c); err != nil {
+		return err
+	}
+
+	pin, err := h.ipfs.AddRemotePin(r.Context(), r.PathValue("name"), c, r.FormValue("name"))
+	if err != nil {
+		return remoteErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(pin)
+}
+
+// removeRemotePin handles a request to remove every pin of a CID from a remote pinning service.
+func (h *handlerImpl) RemoveRemotePin(w http.ResponseWriter, r *http.Request) error {
+	if err := h.ipfs.RemoveRemotePin(r.Context(), r.PathValue("name"), r.PathValue("cid")); err != nil {
+		return remoteErrorStatus(err)
+	}
+	return writeSuccess(w, http.StatusOK)
+}
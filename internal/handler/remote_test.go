@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRemoteRoutes(t *testing.T) {
+	serviceForm := url.Values{"name": {"psa"}, "endpoint": {"https://psa.example.com"}, "key": {"secret"}}.Encode()
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		token          string
+		setupMock      func(mockClient *mocked.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "List services",
+			method: http.MethodGet,
+			target: "/v1/remote/services?stat=true",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().ListRemoteServices(gomock.Any(), true).Return([]ipfs.RemoteService{
+					{Name: "psa", Endpoint: "https://psa.example.com", Stat: &ipfs.RemoteServiceStat{
+						Status: "valid", PinCount: &ipfs.RemotePinCount{Queued: 1, Pinned: 2},
+					}},
+					{Name: "down", Endpoint: "https://down.example.com", Stat: &ipfs.RemoteServiceStat{Status: "invalid"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"services":[{"name":"psa","endpoint":"https://psa.example.com","stat":{"status":"valid","pin_count":{"queued":1,"pinning":0,"pinned":2,"failed":0}}},` +
+				`{"name":"down","endpoint":"https://down.example.com","stat":{"status":"invalid"}}]}`,
+		},
+		{
+			name:           "List services with invalid stat",
+			method:         http.MethodGet,
+			target:         "/v1/remote/services?stat=maybe",
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"stat must be a boolean"}`,
+		},
+		{
+			name:   "Add service",
+			method: http.MethodPost,
+			target: "/v1/remote/services",
+			body:   serviceForm,
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().AddRemoteService(gomock.Any(), "psa", "https://psa.example.com", "secret").Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"status":"success"}`,
+		},
+		{
+			name:           "Add service without admin token",
+			method:         http.MethodPost,
+			target:         "/v1/remote/services",
+			body:           serviceForm,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid admin token"}`,
+		},
+		{
+			name:           "Add service without key",
+			method:         http.MethodPost,
+			target:         "/v1/remote/services",
+			body:           "name=psa&endpoint=https://psa.example.com",
+			token:          testAdminToken,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"name, endpoint and key are required"}`,
+		},
+		{
+			name:   "Add existing service",
+			method: http.MethodPost,
+			target: "/v1/remote/services",
+			body:   serviceForm,
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().AddRemoteService(gomock.Any(), "psa", "https://psa.example.com", "secret").Return(fmt.Errorf("%w: psa", ipfs.ErrServiceExists))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"remote pinning service already exists: psa"}`,
+		},
+		{
+			name:   "Remove unknown service",
+			method: http.MethodDelete,
+			target: "/v1/remote/services/psa",
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RemoveRemoteService(gomock.Any(), "psa").Return(fmt.Errorf("%w: psa", ipfs.ErrServiceNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"remote pinning service not found: psa"}`,
+		},
+		{
+			name:   "List pins",
+			method: http.MethodGet,
+			target: "/v1/remote/services/psa/pins?cid=" + testCid + "&status=queued,pinned",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().ListRemotePins(gomock.Any(), "psa", []string{testCid}, []string{"queued", "pinned"}).
+					Return([]ipfs.RemotePin{{Cid: testCid, Name: "docs", Status: "pinned"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"pins":[{"cid":"` + testCid + `","name":"docs","status":"pinned"}]}`,
+		},
+		{
+			name:   "List pins with invalid status",
+			method: http.MethodGet,
+			target: "/v1/remote/services/psa/pins?status=lost",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().ListRemotePins(gomock.Any(), "psa", nil, []string{"lost"}).
+					Return(nil, fmt.Errorf("%w: %q", ipfs.ErrInvalidRemoteStatus, "lost"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid remote pin status: \"lost\""}`,
+		},
+		{
+			name:   "Mirror pin",
+			method: http.MethodPost,
+			target: "/v1/remote/services/psa/pins",
+			body:   "cid=" + testCid + "&name=docs",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().AddRemotePin(gomock.Any(), "psa", testCid, "docs").
+					Return(ipfs.RemotePin{Cid: testCid, Name: "docs", Status: "queued"}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"cid":"` + testCid + `","name":"docs","status":"queued"}`,
+		},
+		{
+			name:           "Mirror pin without cid",
+			method:         http.MethodPost,
+			target:         "/v1/remote/services/psa/pins",
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"cid is required"}`,
+		},
+		{
+			name:   "Mirror pin fails",
+			method: http.MethodPost,
+			target: "/v1/remote/services/psa/pins",
+			body:   "cid=" + testCid,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().AddRemotePin(gomock.Any(), "psa", testCid, "").Return(ipfs.RemotePin{}, errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"connection refused"}`,
+		},
+		{
+			name:   "Remove pin",
+			method: http.MethodDelete,
+			target: "/v1/remote/services/psa/pins/" + testCid,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RemoveRemotePin(gomock.Any(), "psa", testCid).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"success"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+const (
+	defaultNumProviders = 20
+	maxNumProviders     = 100
+	defaultFindTimeout  = 30 * time.Second
+	maxFindTimeout      = 5 * time.Minute
+)
+
+// findProviders streams the peers that provide a CID as "provider" Server-Sent Events, followed by a
+// "done" event with the number of providers found. The "max" query parameter limits the number of
+// providers and "timeout" the duration of the search.
+func (h *handlerImpl) FindProviders(w http.ResponseWriter, r *http.Request) error {
+	c := r.PathValue("cid")
+	if _, err := cid.Decode(c); err != nil {
+		return NewErrorStatus(fmt.Errorf("invalid cid %q", c), http.StatusBadRequest, 0)
+	}
+
+	numProviders := defaultNumProviders
+	if v := r.URL.Query().Get("max"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxNumProviders {
+			return NewErrorStatus(fmt.Errorf("max must be between 1 and %d", maxNumProviders), http.StatusBadRequest, 0)
+		}
+		numProviders = n
+	}
+
+	timeout := defaultFindTimeout
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > maxFindTimeout {
+			return NewErrorStatus(fmt.Errorf("timeout must be a duration between 0s and %s", maxFindTimeout), http.StatusBadRequest, 0)
+		}
+		timeout = d
+	}
+
+	stream, err := newEventStream(w, r)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	total := 0
+	err = h.ipfs.FindProviders(ctx, c, numProviders, func(provider ipfs.Provider) error {
+		total++
+		return stream.send("provider", provider)
+	})
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return stream.fail(NewErrorStatus(err, http.StatusInternalServerError, 1))
+	}
+
+	resp := struct {
+		Total int `json:"total"`
+	}{
+		Total: total,
+	}
+	return stream.send("done", resp)
+}
+
+// provide handles a request to announce that the IPFS node provides a CID. The "recursive" query parameter
+// announces every block of the DAG as well.
+func (h *handlerImpl) Provide(w http.ResponseWriter, r *http.Request) error {
+	c := r.PathValue("cid")
+	if _, err := cid.Decode(c); err != nil {
+		return NewErrorStatus(fmt.Errorf("invalid cid %q", c), http.StatusBadRequest, 0)
+	}
+
+	recursive := false
+	if v := r.URL.Query().Get("recursive"); v != "" {
+		var err error
+		if recursive, err = strconv.ParseBool(v); err != nil {
+			return NewErrorStatus(fmt.Errorf("recursive must be a boolean"), http.StatusBadRequest, 0)
+		}
+	}
+
+	if err := h.ipfs.Provide(r.Context(), c, recursive); err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Status string `json:"status"`
+	}{
+		Status: "success",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
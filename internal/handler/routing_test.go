@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+const testCid = "bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy"
+
+func TestFindProviders(t *testing.T) {
+	provider := ipfs.Provider{ID: "12D3KooWPeer", Addrs: []string{"/ip4/10.0.0.1/tcp/4001"}}
+
+	tests := []struct {
+		name                string
+		target              string
+		setupMock           func(mockClient *mocked.MockClient)
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:   "Stream providers",
+			target: "/v1/providers/" + testCid + "?max=5",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().FindProviders(gomock.Any(), testCid, 5, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, _ int, fn func(ipfs.Provider) error) error {
+						return fn(provider)
+					})
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedBody: "event: provider\ndata: {\"id\":\"12D3KooWPeer\",\"addrs\":[\"/ip4/10.0.0.1/tcp/4001\"]}\n\n" +
+				"event: done\ndata: {\"total\":1}",
+		},
+		{
+			name:   "Search times out",
+			target: "/v1/providers/" + testCid,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().FindProviders(gomock.Any(), testCid, defaultNumProviders, gomock.Any()).Return(context.DeadlineExceeded)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedBody:        "event: done\ndata: {\"total\":0}",
+		},
+		{
+			name:   "Error before the first provider",
+			target: "/v1/providers/" + testCid,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().FindProviders(gomock.Any(), testCid, defaultNumProviders, gomock.Any()).Return(errors.New("routing failed"))
+			},
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"routing failed"}`,
+		},
+		{
+			name:   "Error after the first provider",
+			target: "/v1/providers/" + testCid,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().FindProviders(gomock.Any(), testCid, defaultNumProviders, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, _ int, fn func(ipfs.Provider) error) error {
+						if err := fn(provider); err != nil {
+							return err
+						}
+						return errors.New("routing failed")
+					})
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/event-stream",
+			expectedBody: "event: provider\ndata: {\"id\":\"12D3KooWPeer\",\"addrs\":[\"/ip4/10.0.0.1/tcp/4001\"]}\n\n" +
+				"event: error\ndata: {\"error\":\"routing failed\"}",
+		},
+		{
+			name:                "Invalid cid",
+			target:              "/v1/providers/not-a-cid",
+			setupMock:           func(mockClient *mocked.MockClient) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"invalid cid \"not-a-cid\""}`,
+		},
+		{
+			name:                "Invalid max",
+			target:              "/v1/providers/" + testCid + "?max=1000",
+			setupMock:           func(mockClient *mocked.MockClient) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"max must be between 1 and 100"}`,
+		},
+		{
+			name:                "Invalid timeout",
+			target:              "/v1/providers/" + testCid + "?timeout=1h",
+			setupMock:           func(mockClient *mocked.MockClient) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"timeout must be a duration between 0s and 5m0s"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestProvide(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		setupMock      func(mockClient *mocked.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Provide recursively",
+			target: "/v1/providers/" + testCid + "?recursive=true",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Provide(gomock.Any(), testCid, true).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"success"}`,
+		},
+		{
+			name:   "Block not found locally",
+			target: "/v1/providers/" + testCid,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().Provide(gomock.Any(), testCid, false).Return(errors.New("block was not found locally"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"block was not found locally"}`,
+		},
+		{
+			name:           "Invalid recursive",
+			target:         "/v1/providers/" + testCid + "?recursive=maybe",
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"recursive must be a boolean"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/zde37/Hive/internal/scanner"
+)
+
+// scanUpload scans the upload at path before it is added to IPFS. An infected upload is quarantined and fails
+// with a 422 status, and an upload that could not be scanned is refused rather than let through. Nothing is
+// scanned without a scanner.
+func (h *handlerImpl) scanUpload(r *http.Request, path string, rec scanner.Record) error {
+	if h.scanner == nil {
+		return nil
+	}
+
+	result, err := h.scanner.Scan(r.Context(), path)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusServiceUnavailable, 1)
+	}
+	if !result.Infected {
+		return nil
+	}
+
+	rec.Signature, rec.RemoteAddr = result.Signature, r.RemoteAddr
+	log.Printf("scanner: rejected %q (%s) uploaded by %q from %s", rec.Name, rec.Signature, rec.Uploader, rec.RemoteAddr)
+	if h.quarantine != nil {
+		if q, err := h.quarantine.Add(path, rec); err != nil {
+			log.Printf("scanner: failed to quarantine %q: %v", rec.Name, err)
+		} else {
+			log.Printf("scanner: quarantined %q as %s", rec.Name, q.ID)
+		}
+	}
+	if rec.Signature == "" {
+		return NewErrorStatus(scanner.ErrInfected, http.StatusUnprocessableEntity, 0)
+	}
+	return NewErrorStatus(fmt.Errorf("%w: %s", scanner.ErrInfected, rec.Signature), http.StatusUnprocessableEntity, 0)
+}
+
+// listQuarantine handles a request to list the uploads rejected by the scanner, newest first.
+func (h *handlerImpl) ListQuarantine(w http.ResponseWriter, r *http.Request) error {
+	records, err := h.quarantine.List()
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Files []scanner.Record `json:"files"`
+	}{
+		Files: records,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/scanner"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeScanner finds "EICAR" in files and fails to scan the files containing "unscannable".
+type fakeScanner struct{}
+
+func (fakeScanner) Scan(_ context.Context, path string) (scanner.Result, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return scanner.Result{}, err
+	}
+	switch {
+	case bytes.Contains(content, []byte("unscannable")):
+		return scanner.Result{}, fmt.Errorf("%w: clamd is down", scanner.ErrScanFailed)
+	case bytes.Contains(content, []byte("EICAR")):
+		return scanner.Result{Infected: true, Signature: "Eicar-Signature"}, nil
+	default:
+		return scanner.Result{}, nil
+	}
+}
+
+func TestScanUpload(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	serve := func(r *http.Request, token string) *httptest.ResponseRecorder {
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	upload := func(name, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("name", name))
+		part, err := mw.CreateFormFile("file", name+".exe")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/file", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		return serve(r, testUserToken)
+	}
+
+	// clean files are added to IPFS
+	mockClient.EXPECT().Add(gomock.Any(), "clean", gomock.Any()).Return("/ipfs/"+testCid, testCid, nil)
+	w := upload("clean", "hello world")
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// infected files never reach IPFS
+	w = upload("infected", "X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*")
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Equal(t, `{"error":"file is infected: Eicar-Signature"}`, strings.TrimSpace(w.Body.String()))
+
+	// neither do the files that could not be scanned
+	w = upload("unknown", "unscannable")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/quarantine", nil), testAdminToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Files []scanner.Record `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Files, 1)
+	require.Equal(t, "infected", resp.Files[0].Name)
+	require.Equal(t, "infected.exe", resp.Files[0].Filename)
+	require.Equal(t, "Eicar-Signature", resp.Files[0].Signature)
+	require.Equal(t, "alice", resp.Files[0].Uploader)
+	require.NotEmpty(t, resp.Files[0].ID)
+
+	// the quarantine is listed with the admin token only
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/quarantine", nil), testUserToken)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
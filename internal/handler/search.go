@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zde37/Hive/internal/search"
+)
+
+const (
+	defaultSearchLimit = 20  // the number of search results returned if no limit is given.
+	maxSearchLimit     = 100 // the maximum number of search results returned.
+)
+
+// indexText indexes the text of an uploaded file of the given MIME type for full-text search, if it is a
+// text-like document. A document whose text cannot be extracted is not indexed, without failing the upload.
+func (h *handlerImpl) indexText(r io.Reader, doc search.Document, mimeType string) error {
+	text, ok, err := search.Extract(r, doc.Filename, mimeType)
+	if err != nil {
+		log.Printf("failed to extract the text of %s: %v", doc.Cid, err)
+		return nil
+	}
+	if !ok || text == "" {
+		return nil
+	}
+
+	doc.Text = text
+	return h.search.Add(doc)
+}
+
+// search handles a full-text search of the uploaded documents for the words of the "q" query parameter,
+// responding with the most relevant first, up to "limit". Users identified by their bearer token find their
+// own uploads and the anonymous ones, everyone else only the anonymous uploads.
+func (h *handlerImpl) Search(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		return NewErrorStatus(fmt.Errorf("q is required"), http.StatusBadRequest, 0)
+	}
+
+	limit := defaultSearchLimit
+	if v := query.Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxSearchLimit {
+			return NewErrorStatus(fmt.Errorf("limit must be between 1 and %d", maxSearchLimit), http.StatusBadRequest, 0)
+		}
+	}
+
+	results, err := h.search.Search(q, h.user(r), limit)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Query   string          `json:"query"`
+		Results []search.Result `json:"results"`
+	}{
+		Query:   q,
+		Results: results,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/search"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSearch(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	upload := func(rootCid, filename, content, token string) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("name", filename))
+		part, err := mw.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		mockClient.EXPECT().Add(gomock.Any(), filename, gomock.Any()).Return("/ipfs/"+rootCid, rootCid, nil)
+		r := httptest.NewRequest(http.MethodPost, "/v1/file", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		require.Equal(t, http.StatusCreated, serve(r).Code)
+	}
+	find := func(target, token string) []search.Result {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := serve(r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Results []search.Result `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Results
+	}
+
+	upload(testCid, "roadmap.md", "# Roadmap\n\nShip the gateway in Q3.", testUserToken)
+	upload(testNewCid, "changelog.txt", "Added the gateway and the search API.", "")
+	upload(testPeerID, "logo.png", "\x89PNG gateway", "")
+
+	results := find("/v1/search?q=gateway", "")
+	require.Len(t, results, 1)
+	require.Equal(t, testNewCid, results[0].Cid)
+	require.Equal(t, "changelog.txt", results[0].Filename)
+	require.Equal(t, "Added the gateway and the search API.", results[0].Snippet)
+
+	results = find("/v1/search?q=gateway", testUserToken)
+	require.Len(t, results, 2)
+	results = find("/v1/search?q=GATEWAY+roadmap&limit=1", testUserToken)
+	require.Len(t, results, 1)
+	require.Equal(t, testCid, results[0].Cid)
+
+	// deleted files are no longer found
+	mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+testNewCid).Return(nil)
+	require.Equal(t, http.StatusOK, serve(httptest.NewRequest(http.MethodDelete, "/v1/file/"+testNewCid, nil)).Code)
+	require.Empty(t, find("/v1/search?q=gateway", ""))
+
+	w := serve(httptest.NewRequest(http.MethodGet, "/v1/search?q=+", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"q is required"}`, strings.TrimSpace(w.Body.String()))
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/search?q=gateway&limit=0", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"limit must be between 1 and 100"}`, strings.TrimSpace(w.Body.String()))
+}
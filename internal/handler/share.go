@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/zde37/Hive/internal/share"
+)
+
+// passwordPage asks for the password of a protected share link.
+var passwordPage = template.Must(template.New("password").Parse(`<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8" />
+    <title>Hive - Protected file</title>
+  </head>
+  <body style="font-family: sans-serif; max-width: 400px; margin: 15% auto">
+    <h2>This file is protected</h2>
+    {{if .}}<p style="color: #c00">{{.}}</p>{{end}}
+    <form method="post">
+      <input type="password" name="password" placeholder="Password" autofocus required />
+      <button type="submit">Download</button>
+    </form>
+  </body>
+</html>
+`))
+
+// sharedFile is a share link with its URL, relative to Hive's address.
+type sharedFile struct {
+	share.Share
+	URL string `json:"url"`
+}
+
+// shareErrorStatus maps an error from the share links to its HTTP error status.
+func shareErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, share.ErrInvalid):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	case errors.Is(err, share.ErrPassword):
+		return NewErrorStatus(err, http.StatusUnauthorized, 0)
+	case errors.Is(err, share.ErrNotFound):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	case errors.Is(err, share.ErrExpired), errors.Is(err, share.ErrExhausted):
+		return NewErrorStatus(err, http.StatusGone, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
+
+// createShare handles a request to create a share link for the "cid" form value, valid for the "expires_in"
+// duration and "max_downloads" downloads, and protected by "password". Without them, the link is valid forever,
+// for any number of downloads and without a password.
+func (h *handlerImpl) CreateShare(w http.ResponseWriter, r *http.Request) error {
+	c := r.FormValue("cid")
+	if c == "" {
+		return NewErrorStatus(fmt.Errorf("cid is required"), http.StatusBadRequest, 0)
+	}
+	if _, err := cid.Decode(c); err != nil {
+		return NewErrorStatus(fmt.Errorf("invalid cid %q", c), http.StatusBadRequest, 0)
+	}
+	if err := h.checkDenylist(r, "share", "/ipfs/"+c); err != nil {
+		return err
+	}
+	// share links serve content as it is stored, which would be the ciphertext
+	encrypted, err := h.isEncrypted(c)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return NewErrorStatus(fmt.Errorf("encrypted files cannot be shared"), http.StatusBadRequest, 0)
+	}
+
+	opts := share.Options{Cid: c, CreatedBy: h.user(r), Password: r.FormValue("password")}
+	if v := r.FormValue("expires_in"); v != "" {
+		var err error
+		if opts.ExpiresIn, err = time.ParseDuration(v); err != nil {
+			return NewErrorStatus(fmt.Errorf("invalid expires_in %q", v), http.StatusBadRequest, 0)
+		}
+	}
+	if v := r.FormValue("max_downloads"); v != "" {
+		var err error
+		if opts.MaxDownloads, err = strconv.Atoi(v); err != nil {
+			return NewErrorStatus(fmt.Errorf("invalid max_downloads %q", v), http.StatusBadRequest, 0)
+		}
+	}
+
+	s, token, err := h.shares.Create(opts)
+	if err != nil {
+		return shareErrorStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(sharedFile{Share: s, URL: "/s/" + token})
+}
+
+// listShares handles a request to list the share links of the "cid" query parameter, or every share link
+// without it, newest first. Users only see the links they created, the admin sees all of them.
+func (h *handlerImpl) ListShares(w http.ResponseWriter, r *http.Request) error {
+	shares, err := h.shares.List(r.URL.Query().Get("cid"))
+	if err != nil {
+		return shareErrorStatus(err)
+	}
+
+	resp := struct {
+		Shares []sharedFile `json:"shares"`
+	}{
+		Shares: []sharedFile{},
+	}
+	for _, s := range shares {
+		if h.canManageShare(r, s) {
+			resp.Shares = append(resp.Shares, sharedFile{Share: s, URL: "/s/" + h.shares.Token(s)})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// revokeShare handles a request to revoke a share link, whose URL stops working. Only its creator and the admin
+// may revoke a link.
+func (h *handlerImpl) RevokeShare(w http.ResponseWriter, r *http.Request) error {
+	s, err := h.shares.Get(r.PathValue("id"))
+	if err != nil {
+		return shareErrorStatus(err)
+	}
+	if !h.canManageShare(r, s) {
+		return NewErrorStatus(fmt.Errorf("only the creator of a share link may revoke it"), http.StatusForbidden, 0)
+	}
+	if err := h.shares.Revoke(s.ID); err != nil {
+		return shareErrorStatus(err)
+	}
+
+	resp := struct {
+		Status string `json:"status"`
+	}{
+		Status: "success",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// canManageShare reports whether the request may list and revoke the share link: it was created by the same
+// user, or the request carries the admin token. The links created without a user are only managed by the admin.
+func (h *handlerImpl) canManageShare(r *http.Request, s share.Share) bool {
+	user := h.user(r)
+	return (user != "" && user == s.CreatedBy) || h.isAdmin(r)
+}
+
+// openShare handles a visit of a share link, downloading its file, or asking for its password if it is
+// protected.
+func (h *handlerImpl) OpenShare(w http.ResponseWriter, r *http.Request) error {
+	s, err := h.shares.Authorize(r.PathValue("token"), "")
+	if errors.Is(err, share.ErrPassword) {
+		return writePasswordPage(w, http.StatusOK, "")
+	}
+	if err != nil {
+		return shareErrorStatus(err)
+	}
+	return h.serveShare(w, r, s)
+}
+
+// downloadShare handles the password form of a protected share link, downloading its file if the "password"
+// form value is right.
+func (h *handlerImpl) DownloadShare(w http.ResponseWriter, r *http.Request) error {
+	s, err := h.shares.Authorize(r.PathValue("token"), r.FormValue("password"))
+	if errors.Is(err, share.ErrPassword) {
+		return writePasswordPage(w, http.StatusUnauthorized, "Wrong password, try again.")
+	}
+	if err != nil {
+		return shareErrorStatus(err)
+	}
+	return h.serveShare(w, r, s)
+}
+
+// serveShare streams the file of a share link as an attachment, counting the download. Every request for
+// content counts, ranges included, so that a limit cannot be worked around by downloading a file in parts.
+func (h *handlerImpl) serveShare(w http.ResponseWriter, r *http.Request, s share.Share) error {
+	if err := h.checkDenylist(r, "download", "/ipfs/"+s.Cid); err != nil {
+		return err
+	}
+	encrypted, err := h.isEncrypted(s.Cid)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return NewErrorStatus(fmt.Errorf("encrypted files cannot be shared"), http.StatusForbidden, 0)
+	}
+	file, err := h.ipfs.OpenFile(r.Context(), "/ipfs/"+s.Cid)
+	if err != nil {
+		return previewErrorStatus(err)
+	}
+	defer file.Close()
+
+	if r.Method != http.MethodHead {
+		if _, err := h.shares.Consume(s.ID); err != nil {
+			return shareErrorStatus(err)
+		}
+	}
+
+	filename, mimeType, err := h.downloadName(s.Cid)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", previewPolicy+"; sandbox")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Type", mimeType)
+	http.ServeContent(w, r, "", time.Time{}, file)
+	return nil
+}
+
+// writePasswordPage responds with the password form of a protected share link and an optional message.
+func writePasswordPage(w http.ResponseWriter, status int, message string) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	return passwordPage.Execute(w, message)
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestShares(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	serveAs := func(r *http.Request, token string) *httptest.ResponseRecorder {
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		return serve(r)
+	}
+	postForm := func(target string, form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("Authorization", "Bearer "+testUserToken)
+		return serve(r)
+	}
+	createShare := func(form url.Values) sharedFile {
+		w := postForm("/v1/shares", form)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var s sharedFile
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+		require.True(t, strings.HasPrefix(s.URL, "/s/"))
+		return s
+	}
+	download := func(r *http.Request) *httptest.ResponseRecorder {
+		mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testCid).Return(newTestFile("<h1>report</h1>"), nil)
+		w := serve(r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "<h1>report</h1>", w.Body.String())
+		require.Equal(t, "attachment; filename="+testCid, w.Header().Get("Content-Disposition"))
+		require.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+		require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		return w
+	}
+
+	t.Run("Download limit", func(t *testing.T) {
+		s := createShare(url.Values{"cid": {testCid}, "expires_in": {"1h"}, "max_downloads": {"1"}})
+		require.Equal(t, "alice", s.CreatedBy)
+		require.Equal(t, 1, s.MaxDownloads)
+		require.NotNil(t, s.ExpiresAt)
+
+		download(httptest.NewRequest(http.MethodGet, s.URL, nil))
+
+		w := serve(httptest.NewRequest(http.MethodGet, s.URL, nil))
+		require.Equal(t, http.StatusGone, w.Code)
+		require.Equal(t, `{"error":"share link download limit reached"}`, strings.TrimSpace(w.Body.String()))
+	})
+
+	t.Run("Password", func(t *testing.T) {
+		s := createShare(url.Values{"cid": {testCid}, "password": {"hunter2"}})
+		require.True(t, s.Protected)
+
+		w := serve(httptest.NewRequest(http.MethodGet, s.URL, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Body.String(), `<form method="post">`)
+
+		w = postForm(s.URL, url.Values{"password": {"wrong"}})
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Body.String(), "Wrong password")
+
+		r := httptest.NewRequest(http.MethodPost, s.URL, strings.NewReader("password=hunter2"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		download(r)
+	})
+
+	t.Run("HEAD requests are not counted", func(t *testing.T) {
+		s := createShare(url.Values{"cid": {testCid}, "max_downloads": {"1"}})
+
+		mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testCid).Return(newTestFile("<h1>report</h1>"), nil)
+		w := serve(httptest.NewRequest(http.MethodHead, s.URL, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "15", w.Header().Get("Content-Length"))
+
+		download(httptest.NewRequest(http.MethodGet, s.URL, nil))
+	})
+
+	t.Run("List and revoke", func(t *testing.T) {
+		s := createShare(url.Values{"cid": {testNewCid}})
+
+		list := func(token string) []sharedFile {
+			w := serveAs(httptest.NewRequest(http.MethodGet, "/v1/shares?cid="+testNewCid, nil), token)
+			require.Equal(t, http.StatusOK, w.Code)
+			var resp struct {
+				Shares []sharedFile `json:"shares"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			return resp.Shares
+		}
+		// the links of a user are only listed for that user and the admin
+		require.Equal(t, []sharedFile{s}, list(testUserToken))
+		require.Equal(t, []sharedFile{s}, list(testAdminToken))
+		require.Empty(t, list("bob-token"))
+		require.Empty(t, list(""))
+
+		// and only revoked by them
+		for _, token := range []string{"bob-token", ""} {
+			w := serveAs(httptest.NewRequest(http.MethodDelete, "/v1/shares/"+s.ID, nil), token)
+			require.Equal(t, http.StatusForbidden, w.Code)
+		}
+		w := serveAs(httptest.NewRequest(http.MethodDelete, "/v1/shares/"+s.ID, nil), testUserToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = serve(httptest.NewRequest(http.MethodGet, s.URL, nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		w = serveAs(httptest.NewRequest(http.MethodDelete, "/v1/shares/"+s.ID, nil), testAdminToken)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Anonymous links", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/v1/shares", strings.NewReader(url.Values{"cid": {testNewCid}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := serve(r)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var s sharedFile
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+		require.Empty(t, s.CreatedBy)
+
+		// a link created without a user is not listed or revoked by anonymous callers
+		w = serve(httptest.NewRequest(http.MethodGet, "/v1/shares", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"shares":[]}`, w.Body.String())
+		w = serve(httptest.NewRequest(http.MethodDelete, "/v1/shares/"+s.ID, nil))
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		// but by the admin
+		w = serveAs(httptest.NewRequest(http.MethodGet, "/v1/shares?cid="+testNewCid, nil), testAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), s.ID)
+		w = serveAs(httptest.NewRequest(http.MethodDelete, "/v1/shares/"+s.ID, nil), testAdminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			form     url.Values
+			expected string
+		}{
+			{form: url.Values{}, expected: `{"error":"cid is required"}`},
+			{form: url.Values{"cid": {"nonsense"}}, expected: `{"error":"invalid cid \"nonsense\""}`},
+			{form: url.Values{"cid": {testCid}, "expires_in": {"1 day"}}, expected: `{"error":"invalid expires_in \"1 day\""}`},
+			{form: url.Values{"cid": {testCid}, "max_downloads": {"-1"}}, expected: `{"error":"invalid share link: the download limit must not be negative"}`},
+		}
+		for _, tt := range tests {
+			w := postForm("/v1/shares", tt.form)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Equal(t, tt.expected, strings.TrimSpace(w.Body.String()))
+		}
+
+		w := serve(httptest.NewRequest(http.MethodGet, "/s/forged.0.AAAAAAAAAAAAAAAAAAAAAA", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// eventStream writes Server-Sent Events to a response. The response headers are only written with the
+// first event, so a handler can still fail with a regular error response until it has sent something.
+type eventStream struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	flusher http.Flusher
+	started bool
+}
+
+// newEventStream creates an eventStream for the given response.
+func newEventStream(w http.ResponseWriter, r *http.Request) (*eventStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, NewErrorStatus(fmt.Errorf("streaming is not supported"), http.StatusInternalServerError, 1)
+	}
+	return &eventStream{w: w, r: r, flusher: flusher}, nil
+}
+
+// send writes an event carrying the JSON encoding of data and flushes it to the client.
+func (s *eventStream) send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if !s.started {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+		s.w.Header().Set("Connection", "keep-alive")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	if _, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// fail reports err to the client. Before the first event it is returned as is, to be handled by
+// errorMiddleware; afterwards it is sent as an "error" event carrying an ErrorResponse.
+func (s *eventStream) fail(err error) error {
+	if !s.started {
+		return err
+	}
+
+	errRes, statusCode, errLevel := ErrorInfo(err)
+	if errLevel == 1 { // log only critical errors
+		log.Printf("Log => status: failed, error: %s, status_code: %d, method: %s, path: %s", errRes, statusCode, s.r.Method, s.r.URL.Path)
+	}
+	return s.send("error", errRes)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+// getStats retrieves the repository, bitswap, bandwidth and DHT statistics of the IPFS node. The bandwidth is
+// reported in total and for each protocol the node supports.
+func (h *handlerImpl) GetStats(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	repo, err := h.ipfs.RepoStat(ctx)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	bitswap, err := h.ipfs.BitswapStat(ctx)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	total, err := h.ipfs.BandwidthStat(ctx, "")
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	self, err := h.ipfs.Self(ctx)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	protocols := make(map[string]ipfs.BandwidthStat, len(self.Protocols))
+	for _, protocol := range self.Protocols {
+		bw, err := h.ipfs.BandwidthStat(ctx, protocol)
+		if err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+		protocols[protocol] = bw
+	}
+
+	dht, err := h.ipfs.DHTStat(ctx)
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	type bandwidth struct {
+		Total     ipfs.BandwidthStat            `json:"total"`
+		Protocols map[string]ipfs.BandwidthStat `json:"protocols"`
+	}
+	resp := struct {
+		Repo      ipfs.RepoStat    `json:"repo"`
+		Bitswap   ipfs.BitswapStat `json:"bitswap"`
+		Bandwidth bandwidth        `json:"bandwidth"`
+		DHT       []ipfs.DHTStat   `json:"dht"`
+	}{
+		Repo:      repo,
+		Bitswap:   bitswap,
+		Bandwidth: bandwidth{Total: total, Protocols: protocols},
+		DHT:       dht,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
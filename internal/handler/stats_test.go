@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(mockClient *mocked.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Aggregate stats",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{RepoSize: 1024, StorageMax: 4096, NumObjects: 3, RepoPath: "/data/ipfs", Version: "fs-repo@15"}, nil)
+				mockClient.EXPECT().BitswapStat(gomock.Any()).Return(ipfs.BitswapStat{Wantlist: []string{testCid}, Peers: []string{}, BlocksReceived: 2, DataReceived: 512}, nil)
+				mockClient.EXPECT().BandwidthStat(gomock.Any(), "").Return(ipfs.BandwidthStat{TotalIn: 100, TotalOut: 50, RateIn: 1.5, RateOut: 0.5}, nil)
+				mockClient.EXPECT().Self(gomock.Any()).Return(ipfs.NodeInfo{Protocols: []string{"/ipfs/bitswap/1.2.0"}}, nil)
+				mockClient.EXPECT().BandwidthStat(gomock.Any(), "/ipfs/bitswap/1.2.0").Return(ipfs.BandwidthStat{TotalIn: 80, TotalOut: 10, RateIn: 1, RateOut: 0.25}, nil)
+				mockClient.EXPECT().DHTStat(gomock.Any()).Return([]ipfs.DHTStat{{Name: "wan", Buckets: 2, Peers: 10, ConnectedPeers: 4}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"repo":{"repo_size":1024,"storage_max":4096,"num_objects":3,"repo_path":"/data/ipfs","version":"fs-repo@15"},` +
+				`"bitswap":{"wantlist":["` + testCid + `"],"peers":[],"provide_buf_len":0,"blocks_received":2,"data_received":512,` +
+				`"dup_blks_received":0,"dup_data_received":0,"messages_received":0,"blocks_sent":0,"data_sent":0},` +
+				`"bandwidth":{"total":{"total_in":100,"total_out":50,"rate_in":1.5,"rate_out":0.5},` +
+				`"protocols":{"/ipfs/bitswap/1.2.0":{"total_in":80,"total_out":10,"rate_in":1,"rate_out":0.25}}},` +
+				`"dht":[{"name":"wan","buckets":2,"peers":10,"connected_peers":4}]}`,
+		},
+		{
+			name: "Repo stat fails",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{}, errors.New("repo locked"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"repo locked"}`,
+		},
+		{
+			name: "DHT stat fails",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RepoStat(gomock.Any()).Return(ipfs.RepoStat{}, nil)
+				mockClient.EXPECT().BitswapStat(gomock.Any()).Return(ipfs.BitswapStat{}, nil)
+				mockClient.EXPECT().BandwidthStat(gomock.Any(), "").Return(ipfs.BandwidthStat{}, nil)
+				mockClient.EXPECT().Self(gomock.Any()).Return(ipfs.NodeInfo{}, nil)
+				mockClient.EXPECT().DHTStat(gomock.Any()).Return(nil, errors.New("this action must be run in online mode"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"this action must be run in online mode"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/stats", nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+// swarmErrorStatus maps an error from a swarm or peering operation to its HTTP error status.
+func swarmErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, ipfs.ErrInvalidPeer):
+		return NewErrorStatus(err, http.StatusBadRequest, 0)
+	case errors.Is(err, ipfs.ErrPeerNotFound):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	default:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+}
+
+// writeSuccess writes the {"status":"success"} response of the swarm and peering handlers.
+func writeSuccess(w http.ResponseWriter, status int) error {
+	resp := struct {
+		Status string `json:"status"`
+	}{
+		Status: "success",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// connectPeer handles a request to connect the IPFS node to the peer at the "addr" multiaddr, which must
+// end with /p2p/<peer id>.
+func (h *handlerImpl) ConnectPeer(w http.ResponseWriter, r *http.Request) error {
+	addr := r.FormValue("addr")
+	if addr == "" {
+		return NewErrorStatus(fmt.Errorf("addr is required"), http.StatusBadRequest, 0)
+	}
+
+	if err := h.ipfs.SwarmConnect(r.Context(), addr); err != nil {
+		return swarmErrorStatus(err)
+	}
+	return writeSuccess(w, http.StatusOK)
+}
+
+// disconnectPeer handles a request to disconnect the IPFS node from a peer, given as a peer ID or a
+// multiaddr ending with /p2p/<peer id> in "addr".
+func (h *handlerImpl) DisconnectPeer(w http.ResponseWriter, r *http.Request) error {
+	addr := r.FormValue("addr")
+	if addr == "" {
+		return NewErrorStatus(fmt.Errorf("addr is required"), http.StatusBadRequest, 0)
+	}
+
+	if err := h.ipfs.SwarmDisconnect(r.Context(), addr); err != nil {
+		return swarmErrorStatus(err)
+	}
+	return writeSuccess(w, http.StatusOK)
+}
+
+// listPeering handles a request to list the peers the IPFS node is configured to stay connected to.
+func (h *handlerImpl) ListPeering(w http.ResponseWriter, r *http.Request) error {
+	peers, err := h.ipfs.ListPeering(r.Context())
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	resp := struct {
+		Peers []ipfs.PeeringPeer `json:"peers"`
+	}{
+		Peers: peers,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// addPeering handles a request to add the peer at the "addr" multiaddr to the IPFS node's persistent
+// peering list.
+func (h *handlerImpl) AddPeering(w http.ResponseWriter, r *http.Request) error {
+	addr := r.FormValue("addr")
+	if addr == "" {
+		return NewErrorStatus(fmt.Errorf("addr is required"), http.StatusBadRequest, 0)
+	}
+
+	if err := h.ipfs.AddPeering(r.Context(), addr); err != nil {
+		return swarmErrorStatus(err)
+	}
+	return writeSuccess(w, http.StatusCreated)
+}
+
+// removePeering handles a request to remove a peer from the IPFS node's persistent peering list.
+func (h *handlerImpl) RemovePeering(w http.ResponseWriter, r *http.Request) error {
+	if err := h.ipfs.RemovePeering(r.Context(), r.PathValue("peerid")); err != nil {
+		return swarmErrorStatus(err)
+	}
+	return writeSuccess(w, http.StatusOK)
+}
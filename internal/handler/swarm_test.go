@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testPeerID   = "12D3KooWRBy97UB99e3J6hiPesre1MZeuNQvfan4gBziswrRJsNK"
+	testPeerAddr = "/ip4/10.0.0.1/tcp/4001/p2p/" + testPeerID
+)
+
+func TestSwarmRoutes(t *testing.T) {
+	form := url.Values{"addr": {testPeerAddr}}.Encode()
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		token          string
+		setupMock      func(mockClient *mocked.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Connect",
+			method: http.MethodPost,
+			target: "/v1/swarm/connect",
+			body:   form,
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().SwarmConnect(gomock.Any(), testPeerAddr).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"success"}`,
+		},
+		{
+			name:           "Connect without addr",
+			method:         http.MethodPost,
+			target:         "/v1/swarm/connect",
+			token:          testAdminToken,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"addr is required"}`,
+		},
+		{
+			name:   "Connect to invalid addr",
+			method: http.MethodPost,
+			target: "/v1/swarm/connect",
+			body:   "addr=nonsense",
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().SwarmConnect(gomock.Any(), "nonsense").Return(fmt.Errorf("%w: bad multiaddr", ipfs.ErrInvalidPeer))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid peer: bad multiaddr"}`,
+		},
+		{
+			name:   "Connect fails",
+			method: http.MethodPost,
+			target: "/v1/swarm/connect",
+			body:   form,
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().SwarmConnect(gomock.Any(), testPeerAddr).Return(errors.New("failed to dial"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"failed to dial"}`,
+		},
+		{
+			name:   "Disconnect",
+			method: http.MethodPost,
+			target: "/v1/swarm/disconnect",
+			body:   "addr=" + testPeerID,
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().SwarmDisconnect(gomock.Any(), testPeerID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"success"}`,
+		},
+		{
+			name:   "List peering",
+			method: http.MethodGet,
+			target: "/v1/peering",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().ListPeering(gomock.Any()).Return([]ipfs.PeeringPeer{
+					{ID: testPeerID, Addrs: []string{"/ip4/10.0.0.1/tcp/4001"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"peers":[{"id":"` + testPeerID + `","addrs":["/ip4/10.0.0.1/tcp/4001"]}]}`,
+		},
+		{
+			name:   "Add peering",
+			method: http.MethodPost,
+			target: "/v1/peering",
+			body:   form,
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().AddPeering(gomock.Any(), testPeerAddr).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"status":"success"}`,
+		},
+		{
+			name:   "Remove peering",
+			method: http.MethodDelete,
+			target: "/v1/peering/" + testPeerID,
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RemovePeering(gomock.Any(), testPeerID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"success"}`,
+		},
+		{
+			name:   "Remove unknown peering",
+			method: http.MethodDelete,
+			target: "/v1/peering/" + testPeerID,
+			token:  testAdminToken,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().RemovePeering(gomock.Any(), testPeerID).Return(fmt.Errorf("%w: %s is not a peering peer", ipfs.ErrPeerNotFound, testPeerID))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"peer not found: ` + testPeerID + ` is not a peering peer"}`,
+		},
+		{
+			name:           "Connect without admin token",
+			method:         http.MethodPost,
+			target:         "/v1/swarm/connect",
+			body:           form,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid admin token"}`,
+		},
+		{
+			name:           "Disconnect without admin token",
+			method:         http.MethodPost,
+			target:         "/v1/swarm/disconnect",
+			body:           "addr=" + testPeerID,
+			token:          testUserToken,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid admin token"}`,
+		},
+		{
+			name:           "Add peering without admin token",
+			method:         http.MethodPost,
+			target:         "/v1/peering",
+			body:           form,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid admin token"}`,
+		},
+		{
+			name:           "Remove peering without admin token",
+			method:         http.MethodDelete,
+			target:         "/v1/peering/" + testPeerID,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid admin token"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zde37/Hive/internal/metadata"
+	"github.com/zde37/Hive/internal/thumbnail"
+)
+
+// generateThumbnails caches the thumbnails of an uploaded image. An image that cannot be decoded has no
+// thumbnails, without failing the upload.
+func (h *handlerImpl) generateThumbnails(r io.Reader, cid string) {
+	if err := h.thumbs.Generate(cid, r); err != nil {
+		log.Printf("failed to generate the thumbnails of %s: %v", cid, err)
+	}
+}
+
+// thumbnail handles a request for the thumbnail of an image of width "w" pixels, a JPEG image. Only images
+// uploaded through Hive have thumbnails; those missing from the cache, e.g. after a rebuild of the file index,
+// are generated on their first request.
+func (h *handlerImpl) Thumbnail(w http.ResponseWriter, r *http.Request) error {
+	c, err := pathCid(r)
+	if err != nil {
+		return err
+	}
+	if err := h.checkDenylist(r, "thumbnail", "/ipfs/"+c); err != nil {
+		return err
+	}
+
+	width := thumbnail.DefaultWidth
+	if v := r.URL.Query().Get("w"); v != "" {
+		if width, err = strconv.Atoi(v); err != nil || !slices.Contains(thumbnail.Widths, width) {
+			widths := make([]string, len(thumbnail.Widths))
+			for n, width := range thumbnail.Widths {
+				widths[n] = strconv.Itoa(width)
+			}
+			return NewErrorStatus(fmt.Errorf("w must be one of %s", strings.Join(widths, ", ")), http.StatusBadRequest, 0)
+		}
+	}
+
+	thumb, err := h.thumbs.Open(c, width)
+	if errors.Is(err, thumbnail.ErrNotFound) {
+		if err := h.generateFromIPFS(r, c); err != nil {
+			return err
+		}
+		thumb, err = h.thumbs.Open(c, width)
+	}
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	defer thumb.Close()
+
+	stat, err := thumb.Stat()
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	// the content of a CID, and so its thumbnails, never change
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Type", "image/jpeg")
+	http.ServeContent(w, r, "", stat.ModTime(), thumb)
+	return nil
+}
+
+// generateFromIPFS caches the thumbnails of the indexed image with the given CID, fetched from the IPFS node.
+// Other CIDs are never fetched, so that anonymous requests cannot fill the cache.
+func (h *handlerImpl) generateFromIPFS(r *http.Request, cid string) error {
+	if h.files == nil {
+		return NewErrorStatus(fmt.Errorf("%w: %s", thumbnail.ErrNotFound, cid), http.StatusNotFound, 0)
+	}
+	indexed, err := h.files.Get(cid)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return NewErrorStatus(fmt.Errorf("%w: %s", thumbnail.ErrNotFound, cid), http.StatusNotFound, 0)
+	}
+	if err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	if indexed.Encryption != nil || !thumbnail.Supported(indexed.MimeType) {
+		return NewErrorStatus(fmt.Errorf("%w: %s", thumbnail.ErrUnsupported, indexed.MimeType), http.StatusUnsupportedMediaType, 0)
+	}
+
+	file, err := h.ipfs.OpenFile(r.Context(), "/ipfs/"+cid)
+	if err != nil {
+		return previewErrorStatus(err)
+	}
+	defer file.Close()
+
+	err = h.thumbs.Generate(cid, file)
+	switch {
+	case errors.Is(err, thumbnail.ErrUnsupported):
+		return NewErrorStatus(err, http.StatusUnsupportedMediaType, 0)
+	case err != nil:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	return nil
+}
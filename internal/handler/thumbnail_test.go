@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestThumbnail(t *testing.T) {
+	const textCid = "bafkreigj5t26ktd3h4teb3gkeh4w2tbwewrlpe2rat2byxw6fgjvvhssze"
+	mockClient, handler := newTestHandler(t)
+
+	var photo bytes.Buffer
+	require.NoError(t, png.Encode(&photo, image.NewGray(image.Rect(0, 0, 512, 256))))
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	thumbSize := func(target string) image.Point {
+		w := serve(httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+		require.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+
+		img, format, err := image.Decode(w.Body)
+		require.NoError(t, err)
+		require.Equal(t, "jpeg", format)
+		return img.Bounds().Size()
+	}
+
+	// upload uploads content as filename, added under rootCid
+	upload := func(filename string, content []byte, rootCid string) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("name", "photo"))
+		part, err := mw.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		mockClient.EXPECT().Add(gomock.Any(), "photo", gomock.Any()).Return("/ipfs/"+rootCid, rootCid, nil)
+		r := httptest.NewRequest(http.MethodPost, "/v1/file", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		require.Equal(t, http.StatusCreated, serve(r).Code)
+	}
+
+	// thumbnails are generated at upload
+	upload("photo.png", photo.Bytes(), testCid)
+	require.Equal(t, image.Point{128, 64}, thumbSize("/v1/thumb/"+testCid))
+	require.Equal(t, image.Point{256, 128}, thumbSize("/v1/thumb/"+testCid+"?w=256"))
+
+	// uploaded images without thumbnails are fetched from the node on their first request only
+	upload("broken.png", photo.Bytes()[:64], testNewCid)
+	mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testNewCid).Return(newTestFile(photo.String()), nil)
+	require.Equal(t, image.Point{64, 32}, thumbSize("/v1/thumb/"+testNewCid+"?w=64"))
+	require.Equal(t, image.Point{64, 32}, thumbSize("/v1/thumb/"+testNewCid+"?w=64"))
+
+	// deleted files lose their thumbnails, and other CIDs are never fetched
+	mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+testNewCid).Return(nil)
+	require.Equal(t, http.StatusOK, serve(httptest.NewRequest(http.MethodDelete, "/v1/file/"+testNewCid, nil)).Code)
+	w := serve(httptest.NewRequest(http.MethodGet, "/v1/thumb/"+testNewCid, nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, `{"error":"thumbnail not found: `+testNewCid+`"}`, strings.TrimSpace(w.Body.String()))
+
+	// uploads of other types have no thumbnails
+	upload("notes.txt", []byte("plain text"), textCid)
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/thumb/"+textCid, nil))
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	require.Equal(t, `{"error":"unsupported image: text/plain; charset=utf-8"}`, strings.TrimSpace(w.Body.String()))
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/thumb/"+testCid+"?w=100", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"w must be one of 64, 128, 256"}`, strings.TrimSpace(w.Body.String()))
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/thumb/nonsense", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ipfs/go-cid"
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/versions"
+)
+
+// updatePin moves the recursive pin of the "from" CID to the "to" CID, fetching only the blocks they do not
+// share. The pin keeps its name, and the new CID is recorded as the latest version of that name.
+func (h *handlerImpl) UpdatePin(w http.ResponseWriter, r *http.Request) error {
+	from, to := r.FormValue("from"), r.FormValue("to")
+	if from == "" || to == "" {
+		return NewErrorStatus(fmt.Errorf("from and to are required"), http.StatusBadRequest, 0)
+	}
+	for _, c := range []string{from, to} {
+		if _, err := cid.Decode(c); err != nil {
+			return NewErrorStatus(fmt.Errorf("invalid cid %q", c), http.StatusBadRequest, 0)
+		}
+	}
+	if from == to {
+		return NewErrorStatus(fmt.Errorf("from and to must be different"), http.StatusBadRequest, 0)
+	}
+	if err := h.checkDenylist(r, "pin", "/ipfs/"+to); err != nil {
+		return err
+	}
+
+	name, err := h.ipfs.UpdatePin(r.Context(), from, to)
+	switch {
+	case errors.Is(err, ipfs.ErrNotPinned):
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	case errors.Is(err, ipfs.ErrAlreadyPinned):
+		return NewErrorStatus(err, http.StatusConflict, 0)
+	case err != nil:
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+
+	// unnamed pins have no history
+	history := []versions.Version{}
+	if name != "" {
+		if history, err = h.versions.RecordUpdate(name, from, to); err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+	}
+
+	resp := struct {
+		Name     string             `json:"name"`
+		From     string             `json:"from"`
+		To       string             `json:"to"`
+		Versions []versions.Version `json:"versions"`
+	}{
+		Name:     name,
+		From:     from,
+		To:       to,
+		Versions: history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// versionErrorStatus maps an error from the version history to its HTTP error status.
+func versionErrorStatus(err error) error {
+	if errors.Is(err, versions.ErrNotFound) {
+		return NewErrorStatus(err, http.StatusNotFound, 0)
+	}
+	return NewErrorStatus(err, http.StatusInternalServerError, 1)
+}
+
+// listVersions handles a request to list the versions of a logical file name, oldest first, along with the
+// current version.
+func (h *handlerImpl) ListVersions(w http.ResponseWriter, r *http.Request) error {
+	name := r.PathValue("name")
+	current, err := h.versions.Current(name)
+	if err != nil {
+		return versionErrorStatus(err)
+	}
+	history, err := h.versions.List(name)
+	if err != nil {
+		return versionErrorStatus(err)
+	}
+
+	resp := struct {
+		Name     string             `json:"name"`
+		Current  versions.Version   `json:"current"`
+		Versions []versions.Version `json:"versions"`
+	}{
+		Name:     name,
+		Current:  current,
+		Versions: history,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// rollbackVersion handles a request to make the version of a logical file name with the given "cid" current
+// again. The pin of the current version is moved to it first, so that the name keeps a single pin.
+func (h *handlerImpl) RollbackVersion(w http.ResponseWriter, r *http.Request) error {
+	name, c := r.PathValue("name"), r.FormValue("cid")
+	if c == "" {
+		return NewErrorStatus(fmt.Errorf("cid is required"), http.StatusBadRequest, 0)
+	}
+	if _, err := h.versions.Find(name, c); err != nil {
+		return versionErrorStatus(err)
+	}
+	if err := h.checkDenylist(r, "pin", "/ipfs/"+c); err != nil {
+		return err
+	}
+
+	current, err := h.versions.Current(name)
+	if err != nil {
+		return versionErrorStatus(err)
+	}
+	if err := h.pinVersion(r.Context(), name, current.Cid, c); err != nil {
+		return NewErrorStatus(err, http.StatusInternalServerError, 1)
+	}
+	current, err = h.versions.Rollback(name, c)
+	if err != nil {
+		return versionErrorStatus(err)
+	}
+
+	resp := struct {
+		Name    string           `json:"name"`
+		Current versions.Version `json:"current"`
+	}{
+		Name:    name,
+		Current: current,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// pinVersion moves the pin of the current version of name to the version with the given CID. The version is
+// pinned under the name if the current one is not pinned anymore, and the current one is unpinned if the version
+// already is.
+func (h *handlerImpl) pinVersion(ctx context.Context, name, current, c string) error {
+	if current == c {
+		return h.ipfs.PinObject(ctx, name, "/ipfs/"+c)
+	}
+
+	_, err := h.ipfs.UpdatePin(ctx, current, c)
+	switch {
+	case errors.Is(err, ipfs.ErrNotPinned):
+		return h.ipfs.PinObject(ctx, name, "/ipfs/"+c)
+	case errors.Is(err, ipfs.ErrAlreadyPinned):
+		return h.ipfs.DeleteFile(ctx, "/ipfs/"+current)
+	default:
+		return err
+	}
+}
+
+// diffVersions handles a request to compare two versions of a logical directory name by listing both. The
+// "to" query parameter defaults to the current version and "from" to the version recorded before "to".
+func (h *handlerImpl) DiffVersions(w http.ResponseWriter, r *http.Request) error {
+	name := r.PathValue("name")
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+
+	if to == "" {
+		current, err := h.versions.Current(name)
+		if err != nil {
+			return versionErrorStatus(err)
+		}
+		to = current.Cid
+	}
+	if from == "" {
+		history, err := h.versions.List(name)
+		if err != nil {
+			return versionErrorStatus(err)
+		}
+		for i := len(history) - 1; i > 0; i-- {
+			if history[i].Cid == to {
+				from = history[i-1].Cid
+				break
+			}
+		}
+		if from == "" {
+			return NewErrorStatus(fmt.Errorf("%s has no version before %s", name, to), http.StatusBadRequest, 0)
+		}
+	}
+
+	for _, c := range []string{from, to} {
+		if _, err := h.versions.Find(name, c); err != nil {
+			return versionErrorStatus(err)
+		}
+	}
+
+	listings := make([][]ipfs.DirFileDetail, 2)
+	for i, c := range []string{from, to} {
+		// only directories are diffed, the listing of a file would be its chunks
+		file, err := h.ipfs.OpenFile(r.Context(), "/ipfs/"+c)
+		if err == nil {
+			file.Close()
+			return NewErrorStatus(fmt.Errorf("versions are not directories: %s is a file", c), http.StatusBadRequest, 0)
+		}
+		if !errors.Is(err, ipfs.ErrNotFile) {
+			return previewErrorStatus(err)
+		}
+
+		entries, err := h.ipfs.ListDir(r.Context(), "/ipfs/"+c)
+		if err != nil {
+			return NewErrorStatus(err, http.StatusInternalServerError, 1)
+		}
+		listings[i] = entries
+	}
+
+	resp := struct {
+		Name string `json:"name"`
+		From string `json:"from"`
+		To   string `json:"to"`
+		versions.Diff
+	}{
+		Name: name,
+		From: from,
+		To:   to,
+		Diff: versions.DiffDirs(listings[0], listings[1]),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
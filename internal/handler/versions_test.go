@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+const testNewCid = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+
+func TestUpdatePin(t *testing.T) {
+	form := url.Values{"from": {testCid}, "to": {testNewCid}}.Encode()
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(mockClient *mocked.MockClient)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Missing to",
+			body:           "from=" + testCid,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"from and to are required"}`,
+		},
+		{
+			name:           "Invalid cid",
+			body:           "from=" + testCid + "&to=nonsense",
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid cid \"nonsense\""}`,
+		},
+		{
+			name:           "Same cid",
+			body:           "from=" + testCid + "&to=" + testCid,
+			setupMock:      func(mockClient *mocked.MockClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"from and to must be different"}`,
+		},
+		{
+			name: "From not pinned",
+			body: form,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().UpdatePin(gomock.Any(), testCid, testNewCid).Return("", fmt.Errorf("%w: %s", ipfs.ErrNotPinned, testCid))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"not pinned: ` + testCid + `"}`,
+		},
+		{
+			name: "To already pinned",
+			body: form,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().UpdatePin(gomock.Any(), testCid, testNewCid).Return("", fmt.Errorf("%w: %s", ipfs.ErrAlreadyPinned, testNewCid))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"already pinned: ` + testNewCid + `"}`,
+		},
+		{
+			name: "Unnamed pin",
+			body: form,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().UpdatePin(gomock.Any(), testCid, testNewCid).Return("", nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"","from":"` + testCid + `","to":"` + testNewCid + `","versions":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient, handler := newTestHandler(t)
+			tt.setupMock(mockClient)
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/pin/update", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.expectedStatus, w.Code)
+			require.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestUpdatePinHistory(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+	mockClient.EXPECT().UpdatePin(gomock.Any(), testCid, testNewCid).Return("dataset", nil)
+	mockClient.EXPECT().UpdatePin(gomock.Any(), testNewCid, testCid).Return("dataset", nil)
+
+	update := func(from, to string) string {
+		r := httptest.NewRequest(http.MethodPost, "/v1/pin/update", strings.NewReader(url.Values{"from": {from}, "to": {to}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	body := update(testCid, testNewCid)
+	require.Contains(t, body, `"name":"dataset"`)
+	require.Equal(t, 2, strings.Count(body, `"cid":`))
+
+	body = update(testNewCid, testCid)
+	require.Equal(t, 3, strings.Count(body, `"cid":`))
+	require.Regexp(t, `"versions":\[\{"cid":"`+testCid+`".*\{"cid":"`+testNewCid+`".*\{"cid":"`+testCid+`"`, body)
+}
+
+func TestVersions(t *testing.T) {
+	mockClient, handler := newTestHandler(t)
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	// the recursive pins of the node, keyed by CID
+	pins := map[string]string{}
+	namePins := func(name string) []string {
+		var cids []string
+		for c, n := range pins {
+			if n == name {
+				cids = append(cids, c)
+			}
+		}
+		return cids
+	}
+	upload := func(rootCid, token string) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		require.NoError(t, mw.WriteField("name", "site"))
+		part, err := mw.CreateFormFile("file", "site.tar")
+		require.NoError(t, err)
+		_, err = part.Write([]byte("content"))
+		require.NoError(t, err)
+		require.NoError(t, mw.Close())
+
+		mockClient.EXPECT().Add(gomock.Any(), "site", gomock.Any()).DoAndReturn(func(context.Context, string, string) (string, string, error) {
+			pins[rootCid] = "site"
+			return "/ipfs/" + rootCid, rootCid, nil
+		})
+		r := httptest.NewRequest(http.MethodPost, "/v1/file", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		require.Equal(t, http.StatusCreated, serve(r).Code)
+	}
+
+	w := serve(httptest.NewRequest(http.MethodGet, "/v1/versions/site", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, `{"error":"version not found: site"}`, strings.TrimSpace(w.Body.String()))
+
+	upload(testCid, testUserToken)
+	upload(testNewCid, "")
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/versions/site", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Regexp(t, `^\{"name":"site","current":\{"cid":"`+testNewCid+`","created_at":"[^"]+"\},`+
+		`"versions":\[\{"cid":"`+testCid+`","uploader":"alice","created_at":"[^"]+"\},\{"cid":"`+testNewCid+`",`, w.Body.String())
+
+	// the previous version is diffed against the current one by default
+	a, b := cid.MustParse(testCid), cid.MustParse(testNewCid)
+	mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testCid).Return(nil, ipfs.ErrNotFile)
+	mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testNewCid).Return(nil, ipfs.ErrNotFile)
+	mockClient.EXPECT().ListDir(gomock.Any(), "/ipfs/"+testCid).Return([]ipfs.DirFileDetail{{Name: "index.html", Cid: a, Size: 5}}, nil)
+	mockClient.EXPECT().ListDir(gomock.Any(), "/ipfs/"+testNewCid).Return([]ipfs.DirFileDetail{{Name: "index.html", Cid: b, Size: 7}, {Name: "app.js", Cid: a, Size: 5}}, nil)
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/versions/site/diff", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"name":"site","from":"`+testCid+`","to":"`+testNewCid+`",`+
+		`"added":[{"name":"app.js","new_cid":"`+testCid+`","size":5}],"removed":[],`+
+		`"modified":[{"name":"index.html","old_cid":"`+testCid+`","new_cid":"`+testNewCid+`","size":7}],"unchanged":0}`,
+		strings.TrimSpace(w.Body.String()))
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/versions/site/diff?from="+testNewCid+"&to="+testNewCid+"x", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// versions that are files cannot be diffed
+	mockClient.EXPECT().OpenFile(gomock.Any(), "/ipfs/"+testCid).Return(newTestFile("hello"), nil)
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/versions/site/diff", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"versions are not directories: `+testCid+` is a file"}`, strings.TrimSpace(w.Body.String()))
+
+	rollback := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/versions/site/rollback", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return serve(r)
+	}
+	require.Equal(t, http.StatusBadRequest, rollback("").Code)
+	require.Equal(t, http.StatusNotFound, rollback("cid="+testNewCid+"x").Code)
+
+	mockClient.EXPECT().UpdatePin(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, from, to string) (string, error) {
+		if _, ok := pins[from]; !ok {
+			return "", ipfs.ErrNotPinned
+		}
+		if _, ok := pins[to]; ok {
+			return "", ipfs.ErrAlreadyPinned
+		}
+		pins[to] = pins[from]
+		delete(pins, from)
+		return pins[to], nil
+	}).AnyTimes()
+	mockClient.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, objectPath string) error {
+		delete(pins, strings.TrimPrefix(objectPath, "/ipfs/"))
+		return nil
+	}).AnyTimes()
+
+	// the uploads pinned both versions, the current one is unpinned
+	require.ElementsMatch(t, []string{testCid, testNewCid}, namePins("site"))
+	w = rollback("cid=" + testCid)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"current":{"cid":"`+testCid+`","uploader":"alice"`)
+	require.Equal(t, []string{testCid}, namePins("site"))
+
+	// the pin is moved back and forth
+	w = rollback("cid=" + testNewCid)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{testNewCid}, namePins("site"))
+	w = rollback("cid=" + testCid)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{testCid}, namePins("site"))
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/versions/site", nil))
+	require.Contains(t, w.Body.String(), `"current":{"cid":"`+testCid+`"`)
+
+	// the oldest version has nothing to be diffed against
+	w = serve(httptest.NewRequest(http.MethodGet, "/v1/versions/site/diff", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, `{"error":"site has no version before `+testCid+`"}`, strings.TrimSpace(w.Body.String()))
+}
@@ -2,19 +2,61 @@ package ipfs
 
 import (
 	"context"
+	"io"
 )
 
 // Client is an interface that provides methods for interacting with an IPFS node.
 type Client interface {
 	NodeInfo(ctx context.Context, peerID string) (NodeInfo, error)
-	Ping(ctx context.Context, peerID string) ([]PingInfo, error)
+	Self(ctx context.Context) (NodeInfo, error)
+	Version(ctx context.Context) (VersionInfo, error)
+	Ping(ctx context.Context, peerID string, count int, fn func(PingInfo) error) error
 	Add(ctx context.Context, fileName, filePath string) (string, string, error)
 	DownloadFile(ctx context.Context, cid string) ([]byte, error)
+	OpenFile(ctx context.Context, filePath string) (FileReader, error)
+	ResolveName(ctx context.Context, name string) (string, error)
+	GetBlock(ctx context.Context, cid string) ([]byte, error)
+	ExportCar(ctx context.Context, cid string, w io.Writer) error
 	ListConnectedNodes(ctx context.Context) ([]Node, error)
 	ListPins(ctx context.Context) (any, error)
+	ListRecursivePins(ctx context.Context) (map[string]string, error)
 	PinObject(ctx context.Context, name, objectPath string) error
+	PinObjectProgress(ctx context.Context, name, objectPath string, fn func(blocks int) error) error
+	UpdatePin(ctx context.Context, fromCid, toCid string) (string, error)
 	DeleteFile(ctx context.Context, objectPath string) error
 	DisplayFileContent(ctx context.Context, filePath string) (string, error)
 	DownloadDir(ctx context.Context, cid string, outputPath string) error
 	ListDir(ctx context.Context, dirPath string) ([]DirFileDetail, error)
+	ImportCar(ctx context.Context, name, carPath string) (CarImportResult, error)
+	DagPut(ctx context.Context, data io.Reader, inputCodec, storeCodec string, pin bool) (string, error)
+	DagGet(ctx context.Context, dagPath, outputCodec string) ([]byte, error)
+	DagStat(ctx context.Context, cid string) (DagStat, error)
+	DagResolve(ctx context.Context, dagPath string) (DagResolveResult, error)
+	PubsubPublish(ctx context.Context, topic string, data []byte) error
+	PubsubSubscribe(ctx context.Context, topic string, fn func(PubsubMessage) error) error
+	PubsubTopics(ctx context.Context) ([]string, error)
+	PubsubPeers(ctx context.Context, topic string) ([]string, error)
+	FindProviders(ctx context.Context, cid string, numProviders int, fn func(Provider) error) error
+	Provide(ctx context.Context, cid string, recursive bool) error
+	SwarmConnect(ctx context.Context, addr string) error
+	SwarmDisconnect(ctx context.Context, addr string) error
+	ListPeering(ctx context.Context) ([]PeeringPeer, error)
+	AddPeering(ctx context.Context, addr string) error
+	RemovePeering(ctx context.Context, peerID string) error
+	RepoStat(ctx context.Context) (RepoStat, error)
+	BitswapStat(ctx context.Context) (BitswapStat, error)
+	BandwidthStat(ctx context.Context, protocol string) (BandwidthStat, error)
+	DHTStat(ctx context.Context) ([]DHTStat, error)
+	GarbageCollect(ctx context.Context, fn func(cid string) error) error
+	VerifyPins(ctx context.Context, fn func(PinVerification) error) error
+	VerifyRepo(ctx context.Context, fn func(BadBlock) error) (int, error)
+	ListRefs(ctx context.Context, cid string, fn func(cid string) error) error
+	RemoveBlock(ctx context.Context, cid string) error
+	FetchBlock(ctx context.Context, cid string) error
+	ListRemoteServices(ctx context.Context, stat bool) ([]RemoteService, error)
+	AddRemoteService(ctx context.Context, name, endpoint, key string) error
+	RemoveRemoteService(ctx context.Context, name string) error
+	AddRemotePin(ctx context.Context, service, cid, name string) (RemotePin, error)
+	ListRemotePins(ctx context.Context, service string, cids, statuses []string) ([]RemotePin, error)
+	RemoveRemotePin(ctx context.Context, service, cid string) error
 }
@@ -2,12 +2,16 @@ package ipfs
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ipfs/boxo/files"
@@ -16,11 +20,20 @@ import (
 	"github.com/ipfs/kubo/client/rpc"
 	iface "github.com/ipfs/kubo/core/coreiface"
 	"github.com/ipfs/kubo/core/coreiface/options"
+	carv2 "github.com/ipld/go-car/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/routing"
+	"github.com/multiformats/go-multiaddr"
 )
 
 // ClientImpl is the implementation of the IPFS client.
 type ClientImpl struct {
-	rpc *rpc.HttpApi // the RPC client.
+	rpc       *rpc.HttpApi // the RPC client.
+	peeringMu sync.Mutex   // serializes updates of the node's Peering.Peers config.
+
+	// gcMu is held by garbage collections and shared by adds and CAR imports until their content is pinned, so
+	// that their blocks are not collected between the import and the pin that names it.
+	gcMu sync.RWMutex
 }
 
 // NodeInfo contains information about an IPFS node. TODO: look into 'AgentVersion' and 'PublicKey' fields
@@ -32,6 +45,15 @@ type NodeInfo struct {
 	PublicKey    string   `json:"PublicKey"`    // the public key of the node.
 }
 
+// VersionInfo contains the version of the IPFS node software and of its repository.
+type VersionInfo struct {
+	Version string `json:"version"` // the Kubo version.
+	Commit  string `json:"commit"`  // the git commit Kubo was built from.
+	Repo    string `json:"repo"`    // the version of the repository format.
+	System  string `json:"system"`  // the architecture and operating system of the node.
+	Golang  string `json:"golang"`  // the Go version Kubo was built with.
+}
+
 // Node represents an IPFS node.
 type Node struct {
 	ID        string `json:"id"`        // the ID of the node.
@@ -64,6 +86,142 @@ type DirFileDetail struct {
 	Type iface.FileType `json:"type"` // the type of the file.
 }
 
+// FileReader is a UnixFS file of the IPFS node, fetched from the node as it is read. Seeking requests the
+// content again from the new offset.
+type FileReader interface {
+	io.ReadSeekCloser
+	Size() (int64, error) // the size of the file in bytes.
+}
+
+// CarImportResult contains the outcome of importing a CAR stream into the IPFS node.
+type CarImportResult struct {
+	Roots      []CarRoot `json:"roots"`       // the roots declared in the CAR header.
+	BlockCount uint64    `json:"block_count"` // the number of blocks imported.
+	BlockBytes uint64    `json:"block_bytes"` // the total size of the imported blocks in bytes.
+}
+
+// CarRoot represents a root of an imported CAR stream and the result of pinning it.
+type CarRoot struct {
+	Cid      string `json:"cid"`                 // the CID of the root.
+	PinError string `json:"pin_error,omitempty"` // the reason the root could not be pinned, if any.
+}
+
+// DagStat contains size information about an IPLD DAG.
+type DagStat struct {
+	Cid       string `json:"cid"`        // the CID of the DAG root.
+	Size      uint64 `json:"size"`       // the total size of the unique blocks in the DAG in bytes.
+	NumBlocks int    `json:"num_blocks"` // the number of unique blocks in the DAG.
+}
+
+// DagResolveResult contains the result of resolving an IPLD path.
+type DagResolveResult struct {
+	Cid     string `json:"cid"`      // the CID of the block the path resolves to.
+	RemPath string `json:"rem_path"` // the part of the path that resolves inside that block.
+}
+
+// PubsubMessage represents a message received on a pubsub topic.
+type PubsubMessage struct {
+	From   string   `json:"from"`   // the ID of the peer that published the message.
+	Seqno  string   `json:"seqno"`  // the hex encoded sequence number of the message.
+	Topics []string `json:"topics"` // the topics the message was published to.
+	Data   []byte   `json:"data"`   // the message payload.
+}
+
+// Provider represents a peer that provides a CID.
+type Provider struct {
+	ID    string   `json:"id"`    // the ID of the providing peer.
+	Addrs []string `json:"addrs"` // the known addresses of the providing peer.
+}
+
+// PeeringPeer represents a peer the IPFS node always stays connected to.
+type PeeringPeer struct {
+	ID    string   `json:"id"`    // the ID of the peer.
+	Addrs []string `json:"addrs"` // the addresses the peer is dialed on.
+}
+
+// RepoStat contains the disk usage of the IPFS node's repository.
+type RepoStat struct {
+	RepoSize   uint64 `json:"repo_size"`   // the size of the repository in bytes.
+	StorageMax uint64 `json:"storage_max"` // the configured maximum size of the repository in bytes.
+	NumObjects uint64 `json:"num_objects"` // the number of blocks in the repository.
+	RepoPath   string `json:"repo_path"`   // the path of the repository on the node.
+	Version    string `json:"version"`     // the version of the repository format.
+}
+
+// BitswapStat contains the transfer statistics of the IPFS node's bitswap exchange.
+type BitswapStat struct {
+	Wantlist         []string `json:"wantlist"`          // the CIDs the node is looking for.
+	Peers            []string `json:"peers"`             // the peers the node exchanges blocks with.
+	ProvideBufLen    int      `json:"provide_buf_len"`   // the number of blocks waiting to be provided.
+	BlocksReceived   uint64   `json:"blocks_received"`   // the number of blocks received.
+	DataReceived     uint64   `json:"data_received"`     // the number of bytes received.
+	DupBlksReceived  uint64   `json:"dup_blks_received"` // the number of duplicate blocks received.
+	DupDataReceived  uint64   `json:"dup_data_received"` // the number of duplicate bytes received.
+	MessagesReceived uint64   `json:"messages_received"` // the number of bitswap messages received.
+	BlocksSent       uint64   `json:"blocks_sent"`       // the number of blocks sent.
+	DataSent         uint64   `json:"data_sent"`         // the number of bytes sent.
+}
+
+// BandwidthStat contains the bandwidth usage of the IPFS node.
+type BandwidthStat struct {
+	TotalIn  int64   `json:"total_in"`  // the number of bytes received.
+	TotalOut int64   `json:"total_out"` // the number of bytes sent.
+	RateIn   float64 `json:"rate_in"`   // the current receive rate in bytes per second.
+	RateOut  float64 `json:"rate_out"`  // the current send rate in bytes per second.
+}
+
+// DHTStat summarizes the routing table of one of the IPFS node's DHTs.
+type DHTStat struct {
+	Name           string `json:"name"`            // the name of the DHT, "wan" or "lan".
+	Buckets        int    `json:"buckets"`         // the number of buckets in the routing table.
+	Peers          int    `json:"peers"`           // the number of peers in the routing table.
+	ConnectedPeers int    `json:"connected_peers"` // the number of peers in the routing table the node is connected to.
+}
+
+// BadBlock is a block that is missing from or corrupt in the IPFS node's repository.
+type BadBlock struct {
+	Cid   string `json:"cid"`   // the CID of the block.
+	Error string `json:"error"` // why the block could not be read.
+}
+
+// PinVerification is the result of verifying that every block of a recursive pin is present.
+type PinVerification struct {
+	Cid      string     `json:"cid"`       // the CID of the pin.
+	Ok       bool       `json:"ok"`        // whether every block of the pin is present.
+	BadNodes []BadBlock `json:"bad_nodes"` // the blocks of the pin that are missing.
+}
+
+// RemotePinStatuses are the statuses of a pin on a remote pinning service.
+var RemotePinStatuses = []string{"queued", "pinning", "pinned", "failed"}
+
+// RemoteService represents a remote pinning service configured on the IPFS node.
+type RemoteService struct {
+	Name     string             `json:"name"`           // the name of the service.
+	Endpoint string             `json:"endpoint"`       // the Pinning Service API endpoint.
+	Stat     *RemoteServiceStat `json:"stat,omitempty"` // the state of the service, only set when requested.
+}
+
+// RemoteServiceStat represents the state of a remote pinning service.
+type RemoteServiceStat struct {
+	Status   string          `json:"status"`              // "valid", or "invalid" if the service could not be reached.
+	PinCount *RemotePinCount `json:"pin_count,omitempty"` // the number of pins in every status, unset if the service is invalid.
+}
+
+// RemotePinCount represents the number of pins in every status on a remote pinning service.
+type RemotePinCount struct {
+	Queued  int `json:"queued"`
+	Pinning int `json:"pinning"`
+	Pinned  int `json:"pinned"`
+	Failed  int `json:"failed"`
+}
+
+// RemotePin represents a pin on a remote pinning service.
+type RemotePin struct {
+	Cid    string `json:"cid"`    // the CID of the pin.
+	Name   string `json:"name"`   // the name of the pin.
+	Status string `json:"status"` // one of RemotePinStatuses.
+}
+
 // NewClientImpl creates a new IPFS client implementation.
 func NewClientImpl(rpc *rpc.HttpApi) Client {
 	return &ClientImpl{
@@ -81,6 +239,9 @@ func (c *ClientImpl) Add(ctx context.Context, fileName, filePath string) (string
 		return "", "", err
 	}
 
+	c.gcMu.RLock()
+	defer c.gcMu.RUnlock()
+
 	var node files.Node
 	if stat.IsDir() {
 		node, err = files.NewSerialFile(filePath, false, stat)
@@ -132,6 +293,31 @@ func (c *ClientImpl) NodeInfo(ctx context.Context, peerID string) (NodeInfo, err
 	return res, err
 }
 
+// Self returns information about the local IPFS node.
+func (c *ClientImpl) Self(ctx context.Context) (NodeInfo, error) {
+	var res NodeInfo
+	err := c.rpc.Request("id").
+		Exec(ctx, &res)
+	return res, err
+}
+
+// Version returns the version of the local IPFS node and of its repository.
+func (c *ClientImpl) Version(ctx context.Context) (VersionInfo, error) {
+	var res struct {
+		Version string
+		Commit  string
+		Repo    string
+		System  string
+		Golang  string
+	}
+	err := c.rpc.Request("version").
+		Exec(ctx, &res)
+	if err != nil {
+		return VersionInfo{}, err
+	}
+	return VersionInfo(res), nil
+}
+
 // DisplayFileContent returns the contents of the file at the given path as a string. If the path is empty, it returns an error.
 func (c *ClientImpl) DisplayFileContent(ctx context.Context, filePath string) (string, error) {
 	if filePath == "" {
@@ -159,38 +345,60 @@ func (c *ClientImpl) DisplayFileContent(ctx context.Context, filePath string) (s
 	return builder.String(), nil
 }
 
-// Ping sends a ping request to the IPFS peer with the given ID and returns the ping response, which includes information about the success, text, and duration of the ping.
-func (c *ClientImpl) Ping(ctx context.Context, peerID string) ([]PingInfo, error) {
-	if peerID == "" {
-		return nil, fmt.Errorf("no peer id provided")
+// Ping sends count pings to the IPFS peer with the given ID and calls fn with the reply to each of them as it
+// arrives. The informational messages of the ping command (peer lookup, average latency) are not passed to fn.
+func (c *ClientImpl) Ping(ctx context.Context, peerID string, count int, fn func(PingInfo) error) error {
+	id, err := peer.Decode(peerID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPeer, err)
+	}
+	if count <= 0 {
+		return fmt.Errorf("ping count must be greater than 0, was %d", count)
 	}
 
-	response, err := c.rpc.Request("ping").
-		Arguments(peerID).
+	response, err := c.rpc.Request("ping", id.String()).
+		Option("count", count).
 		Send(ctx) // Exec() does not decode the ping response well, fix it and create a pull request
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	if response.Output == nil {
-		return nil, fmt.Errorf("no output from ping request or failed to ping self")
+	if response.Error != nil {
+		if strings.Contains(response.Error.Message, "can't ping self") {
+			return ErrPingSelf
+		}
+		return response.Error
 	}
-	defer response.Output.Close()
+	defer response.Close()
 
-	var res []PingInfo
 	decoder := json.NewDecoder(response.Output)
 	for {
-		var r PingInfo
-		if err := decoder.Decode(&r); err != nil {
+		var info PingInfo
+		if err := decoder.Decode(&info); err != nil {
 			if err == io.EOF {
-				break
+				return nil
 			}
-			return nil, err
+			return pingError(err)
+		}
+
+		if info.Success && info.Text != "" {
+			continue
+		}
+		if err := fn(info); err != nil {
+			return err
 		}
-		res = append(res, r)
 	}
+}
 
-	return res, nil
+// pingError converts the errors the ping command reports mid-stream into their sentinel errors.
+func pingError(err error) error {
+	switch msg := err.Error(); {
+	case strings.HasPrefix(msg, "peer lookup failed"):
+		return fmt.Errorf("%w: %s", ErrPeerNotFound, msg)
+	case msg == "ping failed":
+		return ErrPeerUnreachable
+	default:
+		return err
+	}
 }
 
 // PinObject pins the IPFS object at the given path, ensuring that it is not garbage collected.
@@ -206,13 +414,291 @@ func (c *ClientImpl) PinObject(ctx context.Context, name, objectPath string) err
 		Exec(ctx, nil)
 }
 
-// garbageCollection performs a garbage collection on the IPFS repository to remove any unpinned objects.
-func (c *ClientImpl) garbageCollection(ctx context.Context) error {
-	return c.rpc.Request("repo/gc").
+// PinObjectProgress pins the IPFS object at the given path like PinObject, calling fn with the number of blocks
+// fetched so far while the pin is in progress.
+func (c *ClientImpl) PinObjectProgress(ctx context.Context, name, objectPath string, fn func(blocks int) error) error {
+	rootPath, err := path.NewPath(objectPath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	response, err := c.rpc.Request("pin/add", rootPath.String()).
+		Option("name", name).
+		Option("progress", true).
+		Send(ctx)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	defer response.Close()
+
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var res struct {
+			Pins     []string
+			Progress int
+		}
+		if err := decoder.Decode(&res); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if res.Progress > 0 {
+			if err := fn(res.Progress); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// UpdatePin moves the recursive pin of fromCid to toCid, only fetching the blocks of toCid that are not shared
+// with fromCid, and unpins fromCid. The pin keeps its name, which is returned.
+func (c *ClientImpl) UpdatePin(ctx context.Context, fromCid, toCid string) (string, error) {
+	fromPath, err := path.NewPath("/ipfs/" + fromCid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	toPath, err := path.NewPath("/ipfs/" + toCid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	var res struct {
+		Keys map[string]struct {
+			Type string
+			Name string
+		}
+	}
+	err = c.rpc.Request("pin/ls", fromPath.String()).
+		Option("type", "recursive").
+		Option("names", true).
+		Exec(ctx, &res)
+	if err != nil {
+		if strings.Contains(err.Error(), "is not pinned") {
+			return "", fmt.Errorf("%w: %s", ErrNotPinned, fromCid)
+		}
+		return "", err
+	}
+	var name string
+	for _, pin := range res.Keys {
+		name = pin.Name
+	}
+
+	err = c.rpc.Request("pin/update", fromPath.String(), toPath.String()).
+		Option("unpin", true).
 		Exec(ctx, nil)
+	switch {
+	case err == nil:
+		return name, nil
+	case strings.Contains(err.Error(), "'from' cid was not recursively pinned"):
+		return "", fmt.Errorf("%w: %s", ErrNotPinned, fromCid)
+	case strings.Contains(err.Error(), "'to' cid was already recursively pinned"):
+		return "", fmt.Errorf("%w: %s", ErrAlreadyPinned, toCid)
+	default:
+		return "", err
+	}
+}
+
+// GarbageCollect performs a garbage collection on the IPFS repository to remove any unpinned objects, calling
+// fn with the CID of every block it removes. It waits for the adds and CAR imports in progress to be pinned.
+func (c *ClientImpl) GarbageCollect(ctx context.Context, fn func(cid string) error) error {
+	c.gcMu.Lock()
+	defer c.gcMu.Unlock()
+
+	response, err := c.rpc.Request("repo/gc").
+		Send(ctx)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	defer response.Close()
+
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var res struct {
+			Key struct {
+				Cid string `json:"/"`
+			}
+			Error string
+		}
+		if err := decoder.Decode(&res); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if res.Error != "" {
+			return errors.New(res.Error)
+		}
+		if err := fn(res.Key.Cid); err != nil {
+			return err
+		}
+	}
+}
+
+// VerifyPins checks that every block of every recursive pin is present in the IPFS node's repository and
+// calls fn with the result for each pin.
+func (c *ClientImpl) VerifyPins(ctx context.Context, fn func(PinVerification) error) error {
+	response, err := c.rpc.Request("pin/verify").
+		Option("verbose", true).
+		Send(ctx)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	defer response.Close()
+
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var res struct {
+			Cid      string
+			Err      string
+			Ok       bool
+			BadNodes []struct {
+				Cid string
+				Err string
+			}
+		}
+		if err := decoder.Decode(&res); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if res.Err != "" {
+			return errors.New(res.Err)
+		}
+
+		verification := PinVerification{Cid: res.Cid, Ok: res.Ok, BadNodes: []BadBlock{}}
+		for _, node := range res.BadNodes {
+			verification.BadNodes = append(verification.BadNodes, BadBlock{Cid: node.Cid, Error: node.Err})
+		}
+		if err := fn(verification); err != nil {
+			return err
+		}
+	}
+}
+
+// VerifyRepo hashes every block in the IPFS node's repository, calling fn with each block whose content does
+// not match its CID, and returns the number of blocks checked.
+func (c *ClientImpl) VerifyRepo(ctx context.Context, fn func(BadBlock) error) (int, error) {
+	response, err := c.rpc.Request("repo/verify").
+		Send(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if response.Error != nil {
+		return 0, response.Error
+	}
+	defer response.Close()
+
+	blocks := 0
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var res struct {
+			Msg      string
+			Progress int
+		}
+		if err := decoder.Decode(&res); err != nil {
+			switch {
+			case err == io.EOF:
+				return blocks, nil
+			case strings.Contains(err.Error(), "some blocks were corrupt"): // reported after the corrupt blocks
+				return blocks, nil
+			default:
+				return blocks, err
+			}
+		}
+
+		if res.Progress > 0 {
+			blocks = res.Progress
+		}
+		// corrupt blocks are reported as "block <cid> was corrupt (<error>)"
+		rest, ok := strings.CutPrefix(res.Msg, "block ")
+		if !ok {
+			continue
+		}
+		cid, reason, ok := strings.Cut(rest, " was corrupt (")
+		if !ok {
+			continue
+		}
+		if err := fn(BadBlock{Cid: cid, Error: strings.TrimSuffix(reason, ")")}); err != nil {
+			return blocks, err
+		}
+	}
+}
+
+// ListRefs calls fn with the CID of every block the DAG with the given root CID links to, once per block. Only
+// the local repository is searched, a block missing from it ends the listing with an error.
+func (c *ClientImpl) ListRefs(ctx context.Context, cid string, fn func(cid string) error) error {
+	p, err := c.getPathFromCid(cid)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	response, err := c.rpc.Request("refs", p.String()).
+		Option("recursive", true).
+		Option("unique", true).
+		Option("offline", true).
+		Send(ctx)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	defer response.Close()
+
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var res struct {
+			Ref string
+			Err string
+		}
+		if err := decoder.Decode(&res); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if res.Err != "" {
+			return errors.New(res.Err)
+		}
+		if err := fn(res.Ref); err != nil {
+			return err
+		}
+	}
+}
+
+// RemoveBlock removes the local copy of the block with the given CID. Removing a block that is not present is
+// not an error, removing a pinned block is.
+func (c *ClientImpl) RemoveBlock(ctx context.Context, cid string) error {
+	p, err := c.getPathFromCid(cid)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	return c.rpc.Block().Rm(ctx, p, options.Block.Force(true))
 }
 
-// DeleteFile unpins the IPFS object at the given path and then performs a garbage collection to remove the unpinned object.
+// FetchBlock fetches the block with the given CID from the network, unless it is present locally, and stores it
+// in the IPFS node's repository.
+func (c *ClientImpl) FetchBlock(ctx context.Context, cid string) error {
+	p, err := c.getPathFromCid(cid)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	_, err = c.rpc.Block().Get(ctx, p)
+	return err
+}
+
+// DeleteFile unpins the IPFS object at the given path. Its blocks are removed from disk by the next garbage collection.
 func (c *ClientImpl) DeleteFile(ctx context.Context, objectPath string) error {
 	rootPath, err := path.NewPath(objectPath)
 	if err != nil {
@@ -230,14 +716,7 @@ func (c *ClientImpl) DeleteFile(ctx context.Context, objectPath string) error {
 		return fmt.Errorf("..object is pinned %s", res)
 	}
 
-	// first unpin object, then perform garbage collection
-	err = c.rpc.Pin().Rm(ctx, rootPath)
-	if err != nil {
-		return err
-	}
-
-	return nil
-	// return c.garbageCollection(ctx)
+	return c.rpc.Pin().Rm(ctx, rootPath)
 }
 
 // DownloadFile downloads the IPFS object with the given CID and returns its contents as a byte slice.
@@ -261,6 +740,75 @@ func (c *ClientImpl) DownloadFile(ctx context.Context, cid string) ([]byte, erro
 	return io.ReadAll(file)
 }
 
+// OpenFile opens the UnixFS file at the given content path, such as /ipfs/{cid}/{path}, for reading. It returns
+// ErrNotFile if the path is a directory or a symlink, and ErrPathNotFound if it does not exist.
+func (c *ClientImpl) OpenFile(ctx context.Context, filePath string) (FileReader, error) {
+	p, err := path.NewPath(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	node, err := c.rpc.Unixfs().Get(ctx, p)
+	if err != nil {
+		if strings.Contains(err.Error(), "no link named") {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, filePath)
+		}
+		return nil, err
+	}
+
+	file, ok := node.(FileReader)
+	if !ok {
+		node.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotFile, filePath)
+	}
+	return file, nil
+}
+
+// ResolveName resolves an IPNS name, a key or a DNSLink domain, to the content path it points to, such as
+// /ipfs/{cid}.
+func (c *ClientImpl) ResolveName(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("%w: no name provided", ErrInvalidPath)
+	}
+	p, err := c.rpc.Name().Resolve(ctx, "/ipns/"+name)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+// GetBlock returns the raw data of the block with the given CID.
+func (c *ClientImpl) GetBlock(ctx context.Context, cid string) ([]byte, error) {
+	p, err := c.getPathFromCid(cid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	r, err := c.rpc.Block().Get(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// ExportCar writes the DAG rooted at the given CID to w as a CARv1 stream.
+func (c *ClientImpl) ExportCar(ctx context.Context, cid string, w io.Writer) error {
+	if _, err := c.getPathFromCid(cid); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	response, err := c.rpc.Request("dag/export", cid).Send(ctx)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	defer response.Output.Close()
+
+	_, err = io.Copy(w, response.Output)
+	return err
+}
+
 // DownloadDir retrieves the IPFS object (directory) at the given CID and writes it to the specified output path.
 func (c *ClientImpl) DownloadDir(ctx context.Context, cid string, outputPath string) error {
 	path, err := c.getPathFromCid(cid)
@@ -336,6 +884,29 @@ func (c *ClientImpl) ListPins(ctx context.Context) (any, error) {
 	return res, err
 }
 
+// ListRecursivePins returns the names of the recursively pinned objects, keyed by CID. Unnamed pins have an
+// empty name.
+func (c *ClientImpl) ListRecursivePins(ctx context.Context) (map[string]string, error) {
+	var res struct {
+		Keys map[string]struct {
+			Name string
+		}
+	}
+	err := c.rpc.Request("pin/ls").
+		Option("type", "recursive").
+		Option("names", true).
+		Exec(ctx, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	pins := make(map[string]string, len(res.Keys))
+	for cid, pin := range res.Keys {
+		pins[cid] = pin.Name
+	}
+	return pins, nil
+}
+
 // ListDir returns a list of all the files and directories in the specified directory path.
 // For each file/directory, the function returns the name, CID, size, and type.
 func (c *ClientImpl) ListDir(ctx context.Context, dirPath string) ([]DirFileDetail, error) {
@@ -364,13 +935,637 @@ func (c *ClientImpl) ListDir(ctx context.Context, dirPath string) ([]DirFileDeta
 	return files, nil
 }
 
-// writeFile writes the contents of the specified files.File to the specified file path.
-func writeFile(file files.File, path string) error {
-	f, err := os.Create(path)
+// ImportCar verifies every block of the CAR file at carPath, imports the blocks into the IPFS node and pins
+// each root under the given name. Roots that are not fully contained in the CAR are not fetched from the
+// network; the reason they could not be pinned is reported in the result instead.
+func (c *ClientImpl) ImportCar(ctx context.Context, name, carPath string) (CarImportResult, error) {
+	if name == "" || carPath == "" {
+		return CarImportResult{}, fmt.Errorf("name and car path are required")
+	}
+
+	roots, err := verifyCar(carPath)
 	if err != nil {
-		return err
+		return CarImportResult{}, err
 	}
-	defer f.Close()
+
+	file, err := os.Open(carPath)
+	if err != nil {
+		return CarImportResult{}, err
+	}
+	defer file.Close()
+
+	// roots are pinned separately so they can be named, before a garbage collection can remove their blocks
+	c.gcMu.RLock()
+	defer c.gcMu.RUnlock()
+	response, err := c.rpc.Request("dag/import").
+		Option("pin-roots", false).
+		Option("stats", true).
+		FileBody(file).
+		Send(ctx)
+	if err != nil {
+		return CarImportResult{}, err
+	}
+	if response.Error != nil {
+		return CarImportResult{}, response.Error
+	}
+	defer response.Output.Close()
+
+	var res CarImportResult
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var out struct {
+			Stats *struct {
+				BlockCount      uint64
+				BlockBytesCount uint64
+			}
+		}
+		if err := decoder.Decode(&out); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return CarImportResult{}, err
+		}
+		if out.Stats != nil {
+			res.BlockCount = out.Stats.BlockCount
+			res.BlockBytes = out.Stats.BlockBytesCount
+		}
+	}
+
+	for _, root := range roots {
+		carRoot := CarRoot{Cid: root.String()}
+		err := c.rpc.Request("pin/add").
+			Arguments(path.FromCid(root).String()).
+			Option("name", name).
+			Option("offline", true).
+			Exec(ctx, nil)
+		if err != nil {
+			carRoot.PinError = err.Error()
+		}
+		res.Roots = append(res.Roots, carRoot)
+	}
+
+	return res, nil
+}
+
+// verifyCar reads every block of the CAR file at carPath, checking that its data hashes to its CID,
+// and returns the roots declared in the CAR header.
+func verifyCar(carPath string) ([]cid.Cid, error) {
+	file, err := os.Open(carPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := carv2.NewBlockReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCar, err)
+	}
+	if len(reader.Roots) == 0 {
+		return nil, fmt.Errorf("%w: no roots", ErrInvalidCar)
+	}
+
+	for {
+		_, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCar, err)
+		}
+	}
+
+	return reader.Roots, nil
+}
+
+// DagPut stores the IPLD node read from data, encoded with inputCodec, as a block encoded with storeCodec
+// and returns its CID. If pin is true, the node is pinned.
+func (c *ClientImpl) DagPut(ctx context.Context, data io.Reader, inputCodec, storeCodec string, pin bool) (string, error) {
+	if inputCodec == "" || storeCodec == "" {
+		return "", fmt.Errorf("input and store codecs are required")
+	}
+
+	var res struct {
+		Cid cid.Cid
+	}
+	err := c.rpc.Request("dag/put").
+		Option("input-codec", inputCodec).
+		Option("store-codec", storeCodec).
+		Option("pin", pin).
+		FileBody(data).
+		Exec(ctx, &res)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Cid.String(), nil
+}
+
+// DagGet returns the IPLD node at the given path, encoded with outputCodec. The path is a CID optionally
+// followed by fields to traverse into, e.g. "bafy.../author/name".
+func (c *ClientImpl) DagGet(ctx context.Context, dagPath, outputCodec string) ([]byte, error) {
+	p, err := dagPathFrom(dagPath)
+	if err != nil {
+		return nil, err
+	}
+	if outputCodec == "" {
+		return nil, fmt.Errorf("output codec is required")
+	}
+
+	response, err := c.rpc.Request("dag/get").
+		Arguments(p.String()).
+		Option("output-codec", outputCodec).
+		Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	defer response.Output.Close()
+
+	return io.ReadAll(response.Output)
+}
+
+// DagStat returns the total size and number of unique blocks of the DAG rooted at the given CID.
+func (c *ClientImpl) DagStat(ctx context.Context, cid string) (DagStat, error) {
+	p, err := dagPathFrom(cid)
+	if err != nil {
+		return DagStat{}, err
+	}
+
+	var res struct {
+		UniqueBlocks int
+		TotalSize    uint64
+	}
+	err = c.rpc.Request("dag/stat").
+		Arguments(p.String()).
+		Option("progress", false).
+		Exec(ctx, &res)
+	if err != nil {
+		return DagStat{}, err
+	}
+
+	return DagStat{
+		Cid:       cid,
+		Size:      res.TotalSize,
+		NumBlocks: res.UniqueBlocks,
+	}, nil
+}
+
+// DagResolve resolves the given IPLD path to the CID of the block it ends in and the remainder of the
+// path inside that block. It returns ErrPathNotFound if a link of the path does not exist.
+func (c *ClientImpl) DagResolve(ctx context.Context, dagPath string) (DagResolveResult, error) {
+	p, err := dagPathFrom(dagPath)
+	if err != nil {
+		return DagResolveResult{}, err
+	}
+
+	var res struct {
+		Cid     cid.Cid
+		RemPath string
+	}
+	err = c.rpc.Request("dag/resolve").
+		Arguments(p.String()).
+		Exec(ctx, &res)
+	if err != nil {
+		if strings.Contains(err.Error(), "no link named") {
+			return DagResolveResult{}, fmt.Errorf("%w: %s", ErrPathNotFound, dagPath)
+		}
+		return DagResolveResult{}, err
+	}
+
+	return DagResolveResult{
+		Cid:     res.Cid.String(),
+		RemPath: res.RemPath,
+	}, nil
+}
+
+// PubsubPublish publishes data to the given pubsub topic.
+func (c *ClientImpl) PubsubPublish(ctx context.Context, topic string, data []byte) error {
+	if topic == "" {
+		return fmt.Errorf("no topic provided")
+	}
+	return c.rpc.PubSub().Publish(ctx, topic, data)
+}
+
+// PubsubSubscribe subscribes to the given pubsub topic and calls fn for every message received, until the
+// context is done, the subscription ends or fn returns an error.
+func (c *ClientImpl) PubsubSubscribe(ctx context.Context, topic string, fn func(PubsubMessage) error) error {
+	if topic == "" {
+		return fmt.Errorf("no topic provided")
+	}
+
+	sub, err := c.rpc.PubSub().Subscribe(ctx, topic)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		msg, err := sub.Next(ctx)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		err = fn(PubsubMessage{
+			From:   msg.From().String(),
+			Seqno:  hex.EncodeToString(msg.Seq()),
+			Topics: msg.Topics(),
+			Data:   msg.Data(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// PubsubTopics returns the pubsub topics the IPFS node is subscribed to.
+func (c *ClientImpl) PubsubTopics(ctx context.Context) ([]string, error) {
+	return c.rpc.PubSub().Ls(ctx)
+}
+
+// PubsubPeers returns the IDs of the peers the IPFS node is connected to on the given pubsub topic.
+func (c *ClientImpl) PubsubPeers(ctx context.Context, topic string) ([]string, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("no topic provided")
+	}
+
+	peers, err := c.rpc.PubSub().Peers(ctx, options.PubSub.Topic(topic))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(peers))
+	for _, p := range peers {
+		ids = append(ids, p.String())
+	}
+	return ids, nil
+}
+
+// FindProviders searches the routing system for peers that provide the given CID and calls fn for every
+// provider found, until numProviders have been found, the context is done or fn returns an error.
+func (c *ClientImpl) FindProviders(ctx context.Context, cid string, numProviders int, fn func(Provider) error) error {
+	if _, err := c.getPathFromCid(cid); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	if numProviders <= 0 {
+		return fmt.Errorf("number of providers must be greater than 0")
+	}
+
+	response, err := c.rpc.Request("routing/findprovs", cid).
+		Option("num-providers", numProviders).
+		Send(ctx)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	defer response.Output.Close()
+
+	seen := make(map[string]bool)
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var event struct {
+			Type      routing.QueryEventType
+			Responses []Provider
+		}
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if event.Type != routing.Provider {
+			continue
+		}
+
+		for _, provider := range event.Responses {
+			if seen[provider.ID] {
+				continue
+			}
+			seen[provider.ID] = true
+
+			if provider.Addrs == nil {
+				provider.Addrs = []string{}
+			}
+			if err := fn(provider); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Provide announces to the routing system that the IPFS node provides the given CID. If recursive is true,
+// every block of the DAG rooted at the CID is announced as well.
+func (c *ClientImpl) Provide(ctx context.Context, cid string, recursive bool) error {
+	p, err := c.getPathFromCid(cid)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	return c.rpc.Routing().Provide(ctx, p, options.Routing.Recursive(recursive))
+}
+
+// SwarmConnect opens a connection to the peer at the given multiaddr, which must end with /p2p/<peer id>.
+func (c *ClientImpl) SwarmConnect(ctx context.Context, addr string) error {
+	info, err := peer.AddrInfoFromString(addr)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPeer, err)
+	}
+	return c.rpc.Swarm().Connect(ctx, *info)
+}
+
+// SwarmDisconnect closes the connections to a peer, given either as a multiaddr ending with /p2p/<peer id>,
+// which only closes the connection on that address, or as a bare peer ID.
+func (c *ClientImpl) SwarmDisconnect(ctx context.Context, addr string) error {
+	if id, err := peer.Decode(addr); err == nil {
+		addr = "/p2p/" + id.String()
+	}
+
+	maddr, err := multiaddr.NewMultiaddr(addr)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPeer, err)
+	}
+	return c.rpc.Swarm().Disconnect(ctx, maddr)
+}
+
+// peeringEntry is an entry of the Peering.Peers list in the IPFS node config.
+type peeringEntry struct {
+	ID    string
+	Addrs []string
+}
+
+// peeringConfig returns the Peering.Peers list of the IPFS node config.
+func (c *ClientImpl) peeringConfig(ctx context.Context) ([]peeringEntry, error) {
+	var res struct {
+		Value []peeringEntry
+	}
+	err := c.rpc.Request("config", "Peering.Peers").
+		Exec(ctx, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Value, nil
+}
+
+// setPeeringConfig replaces the Peering.Peers list of the IPFS node config.
+func (c *ClientImpl) setPeeringConfig(ctx context.Context, entries []peeringEntry) error {
+	if entries == nil {
+		entries = []peeringEntry{}
+	}
+	value, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return c.rpc.Request("config", "Peering.Peers", string(value)).
+		Option("json", true).
+		Exec(ctx, nil)
+}
+
+// ListPeering returns the peers in the IPFS node's persistent Peering.Peers config.
+func (c *ClientImpl) ListPeering(ctx context.Context) ([]PeeringPeer, error) {
+	entries, err := c.peeringConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make([]PeeringPeer, 0, len(entries))
+	for _, entry := range entries {
+		addrs := entry.Addrs
+		if addrs == nil {
+			addrs = []string{}
+		}
+		peers = append(peers, PeeringPeer{ID: entry.ID, Addrs: addrs})
+	}
+	return peers, nil
+}
+
+// AddPeering adds the peer at the given multiaddr, which must end with /p2p/<peer id>, to the IPFS node's
+// persistent Peering.Peers config and to the running peering subsystem, so the node stays connected to it.
+func (c *ClientImpl) AddPeering(ctx context.Context, addr string) error {
+	info, err := peer.AddrInfoFromString(addr)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPeer, err)
+	}
+
+	c.peeringMu.Lock()
+	defer c.peeringMu.Unlock()
+
+	entries, err := c.peeringConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	id := info.ID.String()
+	found := false
+	for i, entry := range entries {
+		if entry.ID != id {
+			continue
+		}
+		found = true
+		for _, a := range info.Addrs {
+			if !slices.Contains(entry.Addrs, a.String()) {
+				entries[i].Addrs = append(entries[i].Addrs, a.String())
+			}
+		}
+	}
+	if !found {
+		entry := peeringEntry{ID: id, Addrs: []string{}}
+		for _, a := range info.Addrs {
+			entry.Addrs = append(entry.Addrs, a.String())
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := c.setPeeringConfig(ctx, entries); err != nil {
+		return err
+	}
+
+	// the config is only read on startup, so add the peer to the running node as well
+	return c.rpc.Request("swarm/peering/add", addr).
+		Exec(ctx, nil)
+}
+
+// RemovePeering removes the peer with the given ID from the IPFS node's persistent Peering.Peers config and
+// from the running peering subsystem.
+func (c *ClientImpl) RemovePeering(ctx context.Context, peerID string) error {
+	id, err := peer.Decode(peerID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPeer, err)
+	}
+
+	c.peeringMu.Lock()
+	defer c.peeringMu.Unlock()
+
+	entries, err := c.peeringConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	remaining := slices.DeleteFunc(entries, func(entry peeringEntry) bool {
+		return entry.ID == id.String()
+	})
+	if len(remaining) == len(entries) {
+		return fmt.Errorf("%w: %s is not a peering peer", ErrPeerNotFound, id)
+	}
+
+	if err := c.setPeeringConfig(ctx, remaining); err != nil {
+		return err
+	}
+
+	return c.rpc.Request("swarm/peering/rm", id.String()).
+		Exec(ctx, nil)
+}
+
+// RepoStat returns the disk usage of the IPFS node's repository.
+func (c *ClientImpl) RepoStat(ctx context.Context) (RepoStat, error) {
+	var res struct {
+		RepoSize   uint64
+		StorageMax uint64
+		NumObjects uint64
+		RepoPath   string
+		Version    string
+	}
+	err := c.rpc.Request("repo/stat").
+		Exec(ctx, &res)
+	if err != nil {
+		return RepoStat{}, err
+	}
+	return RepoStat(res), nil
+}
+
+// BitswapStat returns the transfer statistics of the IPFS node's bitswap exchange.
+func (c *ClientImpl) BitswapStat(ctx context.Context) (BitswapStat, error) {
+	var res struct {
+		ProvideBufLen int
+		Wantlist      []struct {
+			Cid string `json:"/"`
+		}
+		Peers            []string
+		BlocksReceived   uint64
+		DataReceived     uint64
+		DupBlksReceived  uint64
+		DupDataReceived  uint64
+		MessagesReceived uint64
+		BlocksSent       uint64
+		DataSent         uint64
+	}
+	err := c.rpc.Request("stats/bitswap").
+		Exec(ctx, &res)
+	if err != nil {
+		return BitswapStat{}, err
+	}
+
+	stat := BitswapStat{
+		Wantlist:         make([]string, 0, len(res.Wantlist)),
+		Peers:            res.Peers,
+		ProvideBufLen:    res.ProvideBufLen,
+		BlocksReceived:   res.BlocksReceived,
+		DataReceived:     res.DataReceived,
+		DupBlksReceived:  res.DupBlksReceived,
+		DupDataReceived:  res.DupDataReceived,
+		MessagesReceived: res.MessagesReceived,
+		BlocksSent:       res.BlocksSent,
+		DataSent:         res.DataSent,
+	}
+	for _, want := range res.Wantlist {
+		stat.Wantlist = append(stat.Wantlist, want.Cid)
+	}
+	if stat.Peers == nil {
+		stat.Peers = []string{}
+	}
+	return stat, nil
+}
+
+// BandwidthStat returns the bandwidth usage of the IPFS node, restricted to the given libp2p protocol if it is
+// not empty.
+func (c *ClientImpl) BandwidthStat(ctx context.Context, protocol string) (BandwidthStat, error) {
+	req := c.rpc.Request("stats/bw")
+	if protocol != "" {
+		req = req.Option("proto", protocol)
+	}
+
+	var res struct {
+		TotalIn  int64
+		TotalOut int64
+		RateIn   float64
+		RateOut  float64
+	}
+	if err := req.Exec(ctx, &res); err != nil {
+		return BandwidthStat{}, err
+	}
+	return BandwidthStat(res), nil
+}
+
+// DHTStat returns a summary of the routing table of each of the IPFS node's DHTs.
+func (c *ClientImpl) DHTStat(ctx context.Context) ([]DHTStat, error) {
+	response, err := c.rpc.Request("stats/dht").
+		Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	defer response.Close()
+
+	stats := []DHTStat{}
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var res struct {
+			Name    string
+			Buckets []struct {
+				Peers []struct {
+					Connected bool
+				}
+			}
+		}
+		if err := decoder.Decode(&res); err != nil {
+			if err == io.EOF {
+				return stats, nil
+			}
+			return nil, err
+		}
+
+		stat := DHTStat{Name: res.Name, Buckets: len(res.Buckets)}
+		for _, bucket := range res.Buckets {
+			stat.Peers += len(bucket.Peers)
+			for _, p := range bucket.Peers {
+				if p.Connected {
+					stat.ConnectedPeers++
+				}
+			}
+		}
+		stats = append(stats, stat)
+	}
+}
+
+// dagPathFrom converts a CID, optionally followed by a path, into an /ipfs/ content path.
+func dagPathFrom(dagPath string) (path.Path, error) {
+	dagPath = strings.TrimPrefix(strings.TrimPrefix(dagPath, "/ipfs/"), "/")
+	if dagPath == "" {
+		return nil, fmt.Errorf("%w: no path provided", ErrInvalidPath)
+	}
+
+	p, err := path.NewPath("/ipfs/" + dagPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	return p, nil
+}
+
+// writeFile writes the contents of the specified files.File to the specified file path.
+func writeFile(file files.File, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
 	_, err = io.Copy(f, file)
 	return err
@@ -403,3 +1598,172 @@ func writeDirectory(dir files.Directory, path string) error {
 
     return entries.Err()
 }
+
+// ListRemoteServices lists the remote pinning services configured on the IPFS node. If stat is set, every
+// service is contacted for its pin counts.
+func (c *ClientImpl) ListRemoteServices(ctx context.Context, stat bool) ([]RemoteService, error) {
+	var res struct {
+		RemoteServices []struct {
+			Service     string
+			ApiEndpoint string
+			Stat        *struct {
+				Status   string
+				PinCount *struct {
+					Queued, Pinning, Pinned, Failed int
+				}
+			}
+		}
+	}
+	err := c.rpc.Request("pin/remote/service/ls").
+		Option("stat", stat).
+		Exec(ctx, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]RemoteService, 0, len(res.RemoteServices))
+	for _, s := range res.RemoteServices {
+		service := RemoteService{Name: s.Service, Endpoint: s.ApiEndpoint}
+		if s.Stat != nil {
+			service.Stat = &RemoteServiceStat{Status: s.Stat.Status}
+			if count := s.Stat.PinCount; count != nil {
+				service.Stat.PinCount = &RemotePinCount{
+					Queued:  count.Queued,
+					Pinning: count.Pinning,
+					Pinned:  count.Pinned,
+					Failed:  count.Failed,
+				}
+			}
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+// AddRemoteService configures a remote pinning service on the IPFS node under the given name, with the
+// Pinning Service API endpoint and the access token key.
+func (c *ClientImpl) AddRemoteService(ctx context.Context, name, endpoint, key string) error {
+	if name == "" || key == "" {
+		return fmt.Errorf("%w: name and key are required", ErrInvalidService)
+	}
+
+	err := c.rpc.Request("pin/remote/service/add", name, endpoint, key).
+		Exec(ctx, nil)
+	switch {
+	case err == nil:
+		return nil
+	case strings.Contains(err.Error(), "service already present"):
+		return fmt.Errorf("%w: %s", ErrServiceExists, name)
+	case strings.Contains(err.Error(), "service endpoint"):
+		return fmt.Errorf("%w: %v", ErrInvalidService, err)
+	default:
+		return err
+	}
+}
+
+// RemoveRemoteService removes the remote pinning service with the given name from the IPFS node. The pins
+// on the service are left untouched.
+func (c *ClientImpl) RemoveRemoteService(ctx context.Context, name string) error {
+	// the node silently ignores unknown services
+	services, err := c.ListRemoteServices(ctx, false)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(services, func(s RemoteService) bool { return s.Name == name }) {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, name)
+	}
+
+	return c.rpc.Request("pin/remote/service/rm", name).
+		Exec(ctx, nil)
+}
+
+// AddRemotePin asks the remote pinning service to pin the given CID under name. It returns as soon as the
+// request is queued, the progress of the pin is followed with ListRemotePins.
+func (c *ClientImpl) AddRemotePin(ctx context.Context, service, cid, name string) (RemotePin, error) {
+	rootPath, err := path.NewPath("/ipfs/" + cid)
+	if err != nil {
+		return RemotePin{}, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	var res struct {
+		Status, Cid, Name string
+	}
+	err = c.rpc.Request("pin/remote/add", rootPath.String()).
+		Option("service", service).
+		Option("name", name).
+		Option("background", true).
+		Exec(ctx, &res)
+	if err != nil {
+		return RemotePin{}, remoteServiceError(service, err)
+	}
+	return RemotePin{Cid: res.Cid, Name: res.Name, Status: res.Status}, nil
+}
+
+// ListRemotePins lists the pins on the remote pinning service, limited to the given CIDs and statuses if
+// they are not empty.
+func (c *ClientImpl) ListRemotePins(ctx context.Context, service string, cids, statuses []string) ([]RemotePin, error) {
+	if len(statuses) == 0 {
+		statuses = RemotePinStatuses
+	}
+	for _, status := range statuses {
+		if !slices.Contains(RemotePinStatuses, status) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRemoteStatus, status)
+		}
+	}
+
+	req := c.rpc.Request("pin/remote/ls").
+		Option("service", service).
+		Option("status", strings.Join(statuses, ","))
+	if len(cids) > 0 {
+		req = req.Option("cid", strings.Join(cids, ","))
+	}
+	response, err := req.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, remoteServiceError(service, response.Error)
+	}
+	defer response.Close()
+
+	pins := []RemotePin{}
+	decoder := json.NewDecoder(response.Output)
+	for {
+		var res struct {
+			Status, Cid, Name string
+		}
+		if err := decoder.Decode(&res); err != nil {
+			if err == io.EOF {
+				return pins, nil
+			}
+			return nil, err
+		}
+		pins = append(pins, RemotePin{Cid: res.Cid, Name: res.Name, Status: res.Status})
+	}
+}
+
+// RemoveRemotePin removes every pin of the given CID, in any status, from the remote pinning service.
+func (c *ClientImpl) RemoveRemotePin(ctx context.Context, service, cid string) error {
+	if _, err := path.NewPath("/ipfs/" + cid); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	err := c.rpc.Request("pin/remote/rm").
+		Option("service", service).
+		Option("cid", cid).
+		Option("status", strings.Join(RemotePinStatuses, ",")).
+		Option("force", true).
+		Exec(ctx, nil)
+	if err != nil {
+		return remoteServiceError(service, err)
+	}
+	return nil
+}
+
+// remoteServiceError maps the error of a remote pin command to ErrServiceNotFound if the service is unknown.
+func remoteServiceError(service string, err error) error {
+	if strings.Contains(err.Error(), "service not known") || strings.Contains(err.Error(), "service name not specified") {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, service)
+	}
+	return err
+}
@@ -1,17 +1,25 @@
 package ipfs
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/ipfs/go-cid"
 	iface "github.com/ipfs/kubo/core/coreiface"
+	carv2 "github.com/ipld/go-car/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -478,6 +486,63 @@ func TestDownloadFile(t *testing.T) {
 	}
 }
 
+func TestOpenFile(t *testing.T) {
+	ctx := context.Background()
+	path, cid := addFile(ctx, t)
+	defer delete(ctx, path, t)
+
+	file, err := testClient.OpenFile(ctx, "/ipfs/"+cid)
+	require.NoError(t, err)
+	defer file.Close()
+
+	size, err := file.Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(len("test content")), size)
+
+	_, err = file.Seek(5, io.SeekStart)
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.Equal(t, "content", string(content))
+
+	dirPath, _ := addFolder(ctx, t)
+	defer delete(ctx, dirPath, t)
+	_, err = testClient.OpenFile(ctx, dirPath)
+	require.ErrorIs(t, err, ErrNotFile)
+
+	_, err = testClient.OpenFile(ctx, dirPath+"/missing.txt")
+	require.ErrorIs(t, err, ErrPathNotFound)
+
+	_, err = testClient.OpenFile(ctx, "QmInvalidCID")
+	require.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestGetBlockAndExportCar(t *testing.T) {
+	ctx := context.Background()
+	path, c := addFile(ctx, t)
+	defer delete(ctx, path, t)
+
+	data, err := testClient.GetBlock(ctx, c)
+	require.NoError(t, err)
+	root, err := cid.Decode(c)
+	require.NoError(t, err)
+	sum, err := root.Prefix().Sum(data)
+	require.NoError(t, err)
+	require.True(t, sum.Equals(root))
+
+	var car bytes.Buffer
+	require.NoError(t, testClient.ExportCar(ctx, c, &car))
+	reader, err := carv2.NewBlockReader(&car)
+	require.NoError(t, err)
+	require.Equal(t, []cid.Cid{root}, reader.Roots)
+
+	_, err = testClient.GetBlock(ctx, "QmInvalidCID")
+	require.ErrorIs(t, err, ErrInvalidPath)
+	require.ErrorIs(t, testClient.ExportCar(ctx, "QmInvalidCID", &car), ErrInvalidPath)
+	_, err = testClient.ResolveName(ctx, "")
+	require.ErrorIs(t, err, ErrInvalidPath)
+}
+
 func TestDownloadFileLarge(t *testing.T) {
 	ctx := context.Background()
 	tempDir, err := os.MkdirTemp("", "ipfs-test-large-download")
@@ -797,6 +862,16 @@ func TestListPinsWithTimeout(t *testing.T) {
 	require.Contains(t, err.Error(), "context deadline exceeded")
 }
 
+func TestListRecursivePins(t *testing.T) {
+	ctx := context.Background()
+	_, cid := addFile(ctx, t)
+	defer delete(ctx, "/ipfs/"+cid, t)
+
+	pins, err := testClient.ListRecursivePins(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "test.txt", pins[cid])
+}
+
 func TestListDir(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -965,44 +1040,58 @@ func TestListDirWithTimeout(t *testing.T) {
 }
 
 func TestPing(t *testing.T) {
+	self, err := testClient.Self(context.Background())
+	require.NoError(t, err)
+
 	tests := []struct {
-		name    string
-		peerID  string
-		wantErr bool
+		name   string
+		peerID string
+		count  int
+		errIs  error
 	}{
 		// {
-		// 	name:    "Valid peer ID",
-		// 	peerID:  "12D3KooWEEYvcSMGjVyENaUXPkpp7TQWhSbZipnpjPhBEXJVDCZ9", // use a valid  peer ID for this to work
-		// 	wantErr: false,
+		// 	name:   "Valid peer ID",
+		// 	peerID: "12D3KooWEEYvcSMGjVyENaUXPkpp7TQWhSbZipnpjPhBEXJVDCZ9", // use a valid  peer ID for this to work
+		// 	count:  3,
 		// },
 		{
-			name:    "Empty peer ID",
-			peerID:  "",
-			wantErr: true,
+			name:   "Empty peer ID",
+			peerID: "",
+			count:  1,
+			errIs:  ErrInvalidPeer,
 		},
 		{
-			name:    "Invalid peer ID format",
-			peerID:  "invalid-peer-id",
-			wantErr: true,
+			name:   "Invalid peer ID format",
+			peerID: "invalid-peer-id",
+			count:  1,
+			errIs:  ErrInvalidPeer,
+		},
+		{
+			name:   "Self",
+			peerID: self.ID,
+			count:  1,
+			errIs:  ErrPingSelf,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			pingInfo, err := testClient.Ping(ctx, tt.peerID)
-
-			if tt.wantErr {
-				require.Error(t, err)
-				require.Nil(t, pingInfo)
+			var pingInfo []PingInfo
+			err := testClient.Ping(ctx, tt.peerID, tt.count, func(pi PingInfo) error {
+				pingInfo = append(pingInfo, pi)
+				return nil
+			})
+
+			if tt.errIs != nil {
+				require.ErrorIs(t, err, tt.errIs)
+				require.Empty(t, pingInfo)
 			} else {
 				require.NoError(t, err)
-				require.NotNil(t, pingInfo)
-				require.NotEmpty(t, pingInfo)
+				require.Len(t, pingInfo, tt.count)
 				for _, pi := range pingInfo {
-					require.NotEmpty(t, pi.Success)
+					require.True(t, pi.Success)
 					require.NotZero(t, pi.Time)
-					require.NotEmpty(t, pi.Text)
 				}
 			}
 		})
@@ -1155,3 +1244,548 @@ func TestListConnectedNodesConcurrent(t *testing.T) {
 
 	wg.Wait()
 }
+
+func exportCar(ctx context.Context, t *testing.T, cid string) string {
+	response, err := testClient.(*ClientImpl).rpc.Request("dag/export").
+		Arguments(cid).
+		Send(ctx)
+	require.NoError(t, err)
+	require.Nil(t, response.Error)
+	defer response.Output.Close()
+
+	carFile, err := os.CreateTemp("", "ipfs-test-*.car")
+	require.NoError(t, err)
+	defer carFile.Close()
+
+	_, err = io.Copy(carFile, response.Output)
+	require.NoError(t, err)
+	return carFile.Name()
+}
+
+func TestImportCar(t *testing.T) {
+	ctx := context.Background()
+	path, cid := addFile(ctx, t)
+	carPath := exportCar(ctx, t, cid)
+	defer os.Remove(carPath)
+	delete(ctx, path, t)
+
+	carData, err := os.ReadFile(carPath)
+	require.NoError(t, err)
+
+	corruptPath := carPath + ".corrupt"
+	corruptData := append([]byte{}, carData...)
+	corruptData[len(corruptData)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(corruptPath, corruptData, 0644))
+	defer os.Remove(corruptPath)
+
+	garbagePath := carPath + ".garbage"
+	require.NoError(t, os.WriteFile(garbagePath, []byte("not a car"), 0644))
+	defer os.Remove(garbagePath)
+
+	tests := []struct {
+		name       string
+		importName string
+		carPath    string
+		wantErr    bool
+		errIs      error
+	}{
+		{
+			name:       "Valid car",
+			importName: "test-import",
+			carPath:    carPath,
+		},
+		{
+			name:       "Corrupt block",
+			importName: "test-import",
+			carPath:    corruptPath,
+			wantErr:    true,
+			errIs:      ErrInvalidCar,
+		},
+		{
+			name:       "Not a car",
+			importName: "test-import",
+			carPath:    garbagePath,
+			wantErr:    true,
+			errIs:      ErrInvalidCar,
+		},
+		{
+			name:       "Empty name",
+			importName: "",
+			carPath:    carPath,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := testClient.ImportCar(ctx, tt.importName, tt.carPath)
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.errIs != nil {
+					require.ErrorIs(t, err, tt.errIs)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, res.Roots, 1)
+			require.Equal(t, cid, res.Roots[0].Cid)
+			require.Empty(t, res.Roots[0].PinError)
+			require.NotZero(t, res.BlockCount)
+			require.NotZero(t, res.BlockBytes)
+			delete(ctx, path, t)
+		})
+	}
+}
+
+func TestDag(t *testing.T) {
+	ctx := context.Background()
+	node := `{"name":"hive","nested":{"n":1}}`
+
+	cid, err := testClient.DagPut(ctx, strings.NewReader(node), "dag-json", "dag-cbor", true)
+	require.NoError(t, err)
+	require.NotEmpty(t, cid)
+	defer delete(ctx, "/ipfs/"+cid, t)
+
+	tests := []struct {
+		name    string
+		run     func() (any, error)
+		want    any
+		wantErr bool
+	}{
+		{
+			name: "Get node as dag-json",
+			run: func() (any, error) {
+				data, err := testClient.DagGet(ctx, cid, "dag-json")
+				return strings.TrimSpace(string(data)), err
+			},
+			want: node,
+		},
+		{
+			name: "Get field",
+			run: func() (any, error) {
+				data, err := testClient.DagGet(ctx, cid+"/nested/n", "dag-json")
+				return strings.TrimSpace(string(data)), err
+			},
+			want: "1",
+		},
+		{
+			name: "Get invalid path",
+			run: func() (any, error) {
+				return testClient.DagGet(ctx, "not-a-cid", "dag-json")
+			},
+			wantErr: true,
+		},
+		{
+			name: "Resolve field",
+			run: func() (any, error) {
+				return testClient.DagResolve(ctx, cid+"/nested")
+			},
+			want: DagResolveResult{Cid: cid, RemPath: "nested"},
+		},
+		{
+			name: "Stat",
+			run: func() (any, error) {
+				stat, err := testClient.DagStat(ctx, cid)
+				return stat.NumBlocks, err
+			},
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.run()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPubsub(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	topic := fmt.Sprintf("hive-test-%d", time.Now().UnixNano())
+	received := make(chan PubsubMessage, 1)
+	go func() {
+		testClient.PubsubSubscribe(ctx, topic, func(msg PubsubMessage) error {
+			received <- msg
+			return io.EOF
+		})
+	}()
+
+	// the subscription is set up asynchronously, keep publishing until it sees a message
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg := <-received:
+			require.Equal(t, []byte("hello hive"), msg.Data)
+			require.Contains(t, msg.Topics, topic)
+			require.NotEmpty(t, msg.From)
+			require.NotEmpty(t, msg.Seqno)
+			return
+		case <-ticker.C:
+			require.NoError(t, testClient.PubsubPublish(ctx, topic, []byte("hello hive")))
+		case <-ctx.Done():
+			t.Fatal("message was not received")
+		}
+	}
+}
+
+func TestPubsubEmptyTopic(t *testing.T) {
+	ctx := context.Background()
+	require.Error(t, testClient.PubsubPublish(ctx, "", []byte("hello")))
+	require.Error(t, testClient.PubsubSubscribe(ctx, "", func(PubsubMessage) error { return nil }))
+	_, err := testClient.PubsubPeers(ctx, "")
+	require.Error(t, err)
+}
+
+func TestProvideAndFindProviders(t *testing.T) {
+	ctx := context.Background()
+	path, cid := addFile(ctx, t)
+	defer delete(ctx, path, t)
+
+	require.NoError(t, testClient.Provide(ctx, cid, false))
+
+	findCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	var providers []Provider
+	err := testClient.FindProviders(findCtx, cid, 1, func(p Provider) error {
+		providers = append(providers, p)
+		return nil
+	})
+	if err != nil {
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	for _, p := range providers {
+		require.NotEmpty(t, p.ID)
+	}
+}
+
+func TestFindProvidersInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	noop := func(Provider) error { return nil }
+
+	require.ErrorIs(t, testClient.FindProviders(ctx, "not-a-cid", 1, noop), ErrInvalidPath)
+	require.ErrorIs(t, testClient.Provide(ctx, "not-a-cid", false), ErrInvalidPath)
+	require.Error(t, testClient.FindProviders(ctx, "bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy", 0, noop))
+}
+
+func TestPeering(t *testing.T) {
+	ctx := context.Background()
+	const (
+		peerID = "12D3KooWRBy97UB99e3J6hiPesre1MZeuNQvfan4gBziswrRJsNK"
+		addr   = "/ip4/127.0.0.1/tcp/4999/p2p/" + peerID
+	)
+
+	require.NoError(t, testClient.AddPeering(ctx, addr))
+	// adding the same peer twice must not duplicate it
+	require.NoError(t, testClient.AddPeering(ctx, addr))
+
+	peers, err := testClient.ListPeering(ctx)
+	require.NoError(t, err)
+	count := 0
+	for _, p := range peers {
+		if p.ID == peerID {
+			count++
+			require.Equal(t, []string{"/ip4/127.0.0.1/tcp/4999"}, p.Addrs)
+		}
+	}
+	require.Equal(t, 1, count)
+
+	require.NoError(t, testClient.RemovePeering(ctx, peerID))
+	require.ErrorIs(t, testClient.RemovePeering(ctx, peerID), ErrPeerNotFound)
+
+	require.ErrorIs(t, testClient.AddPeering(ctx, "/ip4/127.0.0.1/tcp/4999"), ErrInvalidPeer)
+	require.ErrorIs(t, testClient.RemovePeering(ctx, "not-a-peer"), ErrInvalidPeer)
+}
+
+func TestSwarmInvalidInput(t *testing.T) {
+	ctx := context.Background()
+
+	require.ErrorIs(t, testClient.SwarmConnect(ctx, "not-a-multiaddr"), ErrInvalidPeer)
+	require.ErrorIs(t, testClient.SwarmDisconnect(ctx, "not-a-multiaddr"), ErrInvalidPeer)
+}
+
+func TestSelfAndVersion(t *testing.T) {
+	ctx := context.Background()
+
+	self, err := testClient.Self(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, self.ID)
+	require.NotEmpty(t, self.AgentVersion)
+
+	info, err := testClient.NodeInfo(ctx, self.ID)
+	require.NoError(t, err)
+	require.Equal(t, self.ID, info.ID)
+
+	version, err := testClient.Version(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, version.Version)
+	require.NotEmpty(t, version.Repo)
+}
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := testClient.RepoStat(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, repo.RepoSize)
+	require.NotZero(t, repo.StorageMax)
+	require.NotEmpty(t, repo.Version)
+
+	bitswap, err := testClient.BitswapStat(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, bitswap.Wantlist)
+	require.NotNil(t, bitswap.Peers)
+
+	_, err = testClient.BandwidthStat(ctx, "")
+	require.NoError(t, err)
+	_, err = testClient.BandwidthStat(ctx, "/ipfs/bitswap/1.2.0")
+	require.NoError(t, err)
+
+	dht, err := testClient.DHTStat(ctx)
+	require.NoError(t, err)
+	for _, stat := range dht {
+		require.NotEmpty(t, stat.Name)
+		require.LessOrEqual(t, stat.ConnectedPeers, stat.Peers)
+	}
+}
+
+func TestGarbageCollect(t *testing.T) {
+	ctx := context.Background()
+	path, cid := addFile(ctx, t)
+	delete(ctx, path, t)
+
+	var removed []string
+	err := testClient.GarbageCollect(ctx, func(c string) error {
+		removed = append(removed, c)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Contains(t, removed, cid)
+}
+
+func TestGarbageCollectDuringAdd(t *testing.T) {
+	ctx := context.Background()
+
+	// collections running alongside adds never leave them unpinned
+	collected := make(chan error, 1)
+	go func() {
+		var err error
+		for i := 0; i < 5 && err == nil; i++ {
+			err = testClient.GarbageCollect(ctx, func(string) error { return nil })
+		}
+		collected <- err
+	}()
+	for i := 0; i < 5; i++ {
+		path, cid := addFile(ctx, t)
+		pins, err := testClient.ListRecursivePins(ctx)
+		require.NoError(t, err)
+		require.Contains(t, pins, cid)
+		delete(ctx, path, t)
+	}
+	require.NoError(t, <-collected)
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	path, cid := addFolder(ctx, t)
+	defer delete(ctx, path, t)
+
+	found := false
+	err := testClient.VerifyPins(ctx, func(pin PinVerification) error {
+		if pin.Cid == cid {
+			found = true
+			require.True(t, pin.Ok)
+			require.Empty(t, pin.BadNodes)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, found)
+
+	blocks, err := testClient.VerifyRepo(ctx, func(block BadBlock) error {
+		t.Errorf("unexpected corrupt block %s: %s", block.Cid, block.Error)
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotZero(t, blocks)
+
+	var refs []string
+	err = testClient.ListRefs(ctx, cid, func(ref string) error {
+		refs = append(refs, ref)
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, refs)
+
+	require.NoError(t, testClient.FetchBlock(ctx, refs[0]))
+	require.ErrorIs(t, testClient.ListRefs(ctx, "not-a-cid", func(string) error { return nil }), ErrInvalidPath)
+}
+
+func TestPinObjectProgress(t *testing.T) {
+	ctx := context.Background()
+	path, cid := addFolder(ctx, t)
+	require.NotEmpty(t, cid)
+	defer delete(ctx, path, t)
+
+	blocks := 0
+	err := testClient.PinObjectProgress(ctx, "test-pin", path, func(n int) error {
+		require.GreaterOrEqual(t, n, blocks)
+		blocks = n
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = testClient.PinObjectProgress(ctx, "test-pin", "invalid-path", func(int) error { return nil })
+	require.ErrorIs(t, err, ErrInvalidPath)
+}
+
+// psaPin is a pin held by the Pinning Service API stand-in of newPSAServer.
+type psaPin struct {
+	RequestID string    `json:"requestid"`
+	Status    string    `json:"status"`
+	Created   time.Time `json:"created"`
+	Pin       struct {
+		Cid  string `json:"cid"`
+		Name string `json:"name"`
+	} `json:"pin"`
+	Delegates []string          `json:"delegates"`
+	Info      map[string]string `json:"info"`
+}
+
+// newPSAServer starts a minimal in-memory implementation of the IPFS Pinning Service API, accepting the given
+// access token. New pins are queued and become pinned when they are first listed.
+func newPSAServer(t *testing.T, token string) *httptest.Server {
+	var (
+		mu   sync.Mutex
+		pins []*psaPin
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pins", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		query := r.URL.Query()
+		results := []*psaPin{}
+		for _, pin := range pins {
+			if cids := query.Get("cid"); cids != "" && !slices.Contains(strings.Split(cids, ","), pin.Pin.Cid) {
+				continue
+			}
+			if statuses := query.Get("status"); statuses != "" && !slices.Contains(strings.Split(statuses, ","), pin.Status) {
+				continue
+			}
+			results = append(results, pin)
+			pin.Status = "pinned"
+		}
+		json.NewEncoder(w).Encode(map[string]any{"count": len(results), "results": results})
+	})
+	mux.HandleFunc("POST /pins", func(w http.ResponseWriter, r *http.Request) {
+		pin := &psaPin{Status: "queued", Created: time.Now().UTC(), Delegates: []string{}, Info: map[string]string{}}
+		if err := json.NewDecoder(r.Body).Decode(&pin.Pin); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		pin.RequestID = fmt.Sprintf("req-%d", len(pins))
+		pins = append(pins, pin)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(pin)
+	})
+	mux.HandleFunc("DELETE /pins/{requestid}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		pins = slices.DeleteFunc(pins, func(pin *psaPin) bool { return pin.RequestID == r.PathValue("requestid") })
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, `{"error":{"reason":"UNAUTHORIZED"}}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRemotePinning(t *testing.T) {
+	ctx := context.Background()
+	path, cid := addFile(ctx, t)
+	defer delete(ctx, path, t)
+
+	psa := newPSAServer(t, "secret")
+	service := fmt.Sprintf("hive-test-%d", time.Now().UnixNano())
+
+	require.NoError(t, testClient.AddRemoteService(ctx, service, psa.URL, "secret"))
+	defer testClient.RemoveRemoteService(ctx, service)
+	require.ErrorIs(t, testClient.AddRemoteService(ctx, service, psa.URL, "secret"), ErrServiceExists)
+	require.ErrorIs(t, testClient.AddRemoteService(ctx, service+"-bad", "not-a-url", "secret"), ErrInvalidService)
+
+	services, err := testClient.ListRemoteServices(ctx, true)
+	require.NoError(t, err)
+	idx := slices.IndexFunc(services, func(s RemoteService) bool { return s.Name == service })
+	require.NotEqual(t, -1, idx)
+	require.Equal(t, psa.URL, services[idx].Endpoint)
+	require.Equal(t, "valid", services[idx].Stat.Status)
+
+	pin, err := testClient.AddRemotePin(ctx, service, cid, "test-pin")
+	require.NoError(t, err)
+	require.Equal(t, RemotePin{Cid: cid, Name: "test-pin", Status: "queued"}, pin)
+
+	pins, err := testClient.ListRemotePins(ctx, service, []string{cid}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []RemotePin{{Cid: cid, Name: "test-pin", Status: "queued"}}, pins)
+
+	pins, err = testClient.ListRemotePins(ctx, service, nil, []string{"pinned"})
+	require.NoError(t, err)
+	require.Equal(t, []RemotePin{{Cid: cid, Name: "test-pin", Status: "pinned"}}, pins)
+
+	_, err = testClient.ListRemotePins(ctx, service, nil, []string{"lost"})
+	require.ErrorIs(t, err, ErrInvalidRemoteStatus)
+
+	require.NoError(t, testClient.RemoveRemotePin(ctx, service, cid))
+	pins, err = testClient.ListRemotePins(ctx, service, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, pins)
+
+	_, err = testClient.AddRemotePin(ctx, "unknown-service", cid, "")
+	require.ErrorIs(t, err, ErrServiceNotFound)
+
+	require.NoError(t, testClient.RemoveRemoteService(ctx, service))
+	require.ErrorIs(t, testClient.RemoveRemoteService(ctx, service), ErrServiceNotFound)
+}
+
+func TestUpdatePin(t *testing.T) {
+	ctx := context.Background()
+	_, cid := addFile(ctx, t)
+
+	data := fmt.Sprintf(`{"revision":%d}`, time.Now().UnixNano())
+	newCid, err := testClient.DagPut(ctx, strings.NewReader(data), "dag-json", "dag-cbor", false)
+	require.NoError(t, err)
+	defer delete(ctx, "/ipfs/"+newCid, t)
+
+	name, err := testClient.UpdatePin(ctx, cid, newCid)
+	require.NoError(t, err)
+	require.Equal(t, "test.txt", name)
+
+	_, err = testClient.UpdatePin(ctx, cid, newCid)
+	require.ErrorIs(t, err, ErrNotPinned)
+	_, err = testClient.UpdatePin(ctx, "not-a-cid", newCid)
+	require.ErrorIs(t, err, ErrInvalidPath)
+}
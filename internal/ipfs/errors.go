@@ -0,0 +1,47 @@
+package ipfs
+
+import "errors"
+
+var (
+	// ErrInvalidCar is returned when a CAR stream is malformed or one of its blocks does not match its CID.
+	ErrInvalidCar = errors.New("invalid car")
+
+	// ErrInvalidPath is returned when a content path or CID cannot be parsed.
+	ErrInvalidPath = errors.New("invalid path")
+
+	// ErrInvalidPeer is returned when a peer ID or peer multiaddr cannot be parsed.
+	ErrInvalidPeer = errors.New("invalid peer")
+
+	// ErrPeerNotFound is returned when a peer cannot be found.
+	ErrPeerNotFound = errors.New("peer not found")
+
+	// ErrPeerUnreachable is returned when a peer was found but did not answer any ping.
+	ErrPeerUnreachable = errors.New("peer unreachable")
+
+	// ErrPingSelf is returned when the IPFS node is asked to ping itself.
+	ErrPingSelf = errors.New("cannot ping self")
+
+	// ErrNotFile is returned when a content path is expected to be a file but is a directory or a symlink.
+	ErrNotFile = errors.New("not a file")
+
+	// ErrPathNotFound is returned when a content path names a file or directory that does not exist.
+	ErrPathNotFound = errors.New("path not found")
+
+	// ErrNotPinned is returned when an object is expected to be recursively pinned but is not.
+	ErrNotPinned = errors.New("not pinned")
+
+	// ErrAlreadyPinned is returned when an object is unexpectedly recursively pinned already.
+	ErrAlreadyPinned = errors.New("already pinned")
+
+	// ErrServiceNotFound is returned when a remote pinning service is not configured on the IPFS node.
+	ErrServiceNotFound = errors.New("remote pinning service not found")
+
+	// ErrServiceExists is returned when a remote pinning service with the same name is already configured.
+	ErrServiceExists = errors.New("remote pinning service already exists")
+
+	// ErrInvalidService is returned when the name or endpoint of a remote pinning service is invalid.
+	ErrInvalidService = errors.New("invalid remote pinning service")
+
+	// ErrInvalidRemoteStatus is returned when a remote pin status is not one of RemotePinStatuses.
+	ErrInvalidRemoteStatus = errors.New("invalid remote pin status")
+)
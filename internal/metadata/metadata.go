@@ -0,0 +1,328 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zde37/Hive/internal/encryption"
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/store"
+)
+
+const (
+	bucket = "files" // the store bucket holding the metadata of every file, keyed by CID.
+
+	maxTagLength         = 64   // the maximum length of a tag.
+	maxKeyLength         = 128  // the maximum length of a custom metadata key.
+	maxValueLength       = 1024 // the maximum length of a custom metadata value.
+	maxDescriptionLength = 4096 // the maximum length of a description.
+)
+
+var (
+	ErrNotFound = errors.New("file metadata not found") // returned when a CID has no metadata.
+	ErrInvalid  = errors.New("invalid file metadata")   // returned for tags and metadata that cannot be stored.
+)
+
+// File is the metadata of a file, which the IPFS node does not keep. The upload fields are only set for files
+// uploaded through Hive or indexed by a rebuild, while tags, description and custom metadata can be attached
+// to any CID.
+type File struct {
+	Cid         string            `json:"cid"`                   // the root CID of the file.
+	Name        string            `json:"name"`                  // the name the file is pinned under.
+	Filename    string            `json:"filename"`              // the original filename of the upload.
+	MimeType    string            `json:"mime_type"`             // the MIME type of the file.
+	Size        int64             `json:"size"`                  // the size of the file in bytes.
+	Uploader    string            `json:"uploader,omitempty"`    // the Hive user who uploaded the file, if known.
+	Tags        []string          `json:"tags"`                  // the tags of the file.
+	Description string            `json:"description,omitempty"` // a free-form description of the file.
+	Meta        map[string]string `json:"meta"`                  // custom key/value metadata of the file.
+	UploadedAt  time.Time         `json:"uploaded_at"`           // when the file was uploaded, or indexed by a rebuild.
+	Encrypted   bool              `json:"encrypted,omitempty"`   // whether the file was encrypted at upload.
+
+	// Encryption holds the wrapped data key of a file encrypted at upload, whose CID is of the ciphertext. It is
+	// stored with the metadata but never serialized with it, so API responses carry no key material.
+	Encryption *encryption.Envelope `json:"-"`
+}
+
+// record is the stored form of a File, which also holds its encryption envelope.
+type record struct {
+	File
+	Envelope *encryption.Envelope `json:"encryption,omitempty"`
+}
+
+// newRecord returns the stored form of file.
+func newRecord(file File) record {
+	file.Encrypted = file.Encryption != nil
+	return record{File: file, Envelope: file.Encryption}
+}
+
+// file returns the File stored in r.
+func (r record) file() File {
+	file := r.File
+	file.Encryption, file.Encrypted = r.Envelope, r.Envelope != nil
+	return file
+}
+
+// Patch changes the tags, description and custom metadata of a file. Nil fields are left unchanged.
+type Patch struct {
+	Tags        *[]string          `json:"tags"`        // replaces the tags.
+	Description *string            `json:"description"` // replaces the description.
+	Meta        map[string]*string `json:"meta"`        // sets the values of the keys, deleting those set to null.
+}
+
+// RebuildResult is the outcome of reconciling the index with the pins of the IPFS node.
+type RebuildResult struct {
+	Added   int `json:"added"`   // the pins indexed because they had no metadata.
+	Removed int `json:"removed"` // the entries removed because their CID is no longer pinned.
+	Kept    int `json:"kept"`    // the entries of pinned CIDs left untouched.
+}
+
+// Index is the persistent metadata index of the files uploaded through Hive and of the CIDs users describe.
+type Index struct {
+	store *store.Store
+}
+
+// NewIndex creates a new Index stored in store.
+func NewIndex(store *store.Store) *Index {
+	return &Index{
+		store: store,
+	}
+}
+
+// Put records the metadata of a file, replacing any previous metadata of its CID.
+func (i *Index) Put(file File) error {
+	if file.Tags == nil {
+		file.Tags = []string{}
+	}
+	if file.Meta == nil {
+		file.Meta = map[string]string{}
+	}
+	return i.store.Put(bucket, file.Cid, newRecord(file))
+}
+
+// Get returns the metadata of the file with the given CID.
+func (i *Index) Get(cid string) (File, error) {
+	var rec record
+	err := i.store.Get(bucket, cid, &rec)
+	if errors.Is(err, store.ErrNotFound) {
+		return File{}, fmt.Errorf("%w: %s", ErrNotFound, cid)
+	}
+	return rec.file(), err
+}
+
+// Delete removes the metadata of the file with the given CID.
+func (i *Index) Delete(cid string) error {
+	return i.store.Delete(bucket, cid)
+}
+
+// Update applies patch to the metadata of the file with the given CID, indexing the CID if it has no metadata
+// yet, and returns the updated metadata.
+func (i *Index) Update(cid string, patch Patch) (File, error) {
+	return i.update(cid, true, func(file *File) error {
+		if patch.Tags != nil {
+			tags, err := NormalizeTags(*patch.Tags)
+			if err != nil {
+				return err
+			}
+			file.Tags = tags
+		}
+		if patch.Description != nil {
+			if len(*patch.Description) > maxDescriptionLength {
+				return fmt.Errorf("%w: description is longer than %d characters", ErrInvalid, maxDescriptionLength)
+			}
+			file.Description = *patch.Description
+		}
+		for key, value := range patch.Meta {
+			key = strings.TrimSpace(key)
+			if key == "" || len(key) > maxKeyLength {
+				return fmt.Errorf("%w: keys must have 1 to %d characters", ErrInvalid, maxKeyLength)
+			}
+			if value == nil {
+				delete(file.Meta, key)
+				continue
+			}
+			if len(*value) > maxValueLength {
+				return fmt.Errorf("%w: value of %q is longer than %d characters", ErrInvalid, key, maxValueLength)
+			}
+			file.Meta[key] = *value
+		}
+		return nil
+	})
+}
+
+// AddTag tags the file with the given CID, indexing the CID if it has no metadata yet, and returns its
+// metadata. Adding a tag the file already has changes nothing.
+func (i *Index) AddTag(cid, tag string) (File, error) {
+	return i.update(cid, true, func(file *File) error {
+		tags, err := NormalizeTags(append(file.Tags, tag))
+		if err != nil {
+			return err
+		}
+		file.Tags = tags
+		return nil
+	})
+}
+
+// RemoveTag removes a tag from the file with the given CID and returns its metadata.
+func (i *Index) RemoveTag(cid, tag string) (File, error) {
+	return i.update(cid, false, func(file *File) error {
+		file.Tags = slices.DeleteFunc(file.Tags, func(t string) bool {
+			return strings.EqualFold(t, tag)
+		})
+		return nil
+	})
+}
+
+// Clear removes the tags, description and custom metadata of the file with the given CID, keeping what was
+// recorded at upload, and returns its metadata.
+func (i *Index) Clear(cid string) (File, error) {
+	return i.update(cid, false, func(file *File) error {
+		file.Tags, file.Description, file.Meta = []string{}, "", map[string]string{}
+		return nil
+	})
+}
+
+// update changes the metadata of the file with the given CID with fn in a single transaction. A CID without
+// metadata is indexed if create is set and is an ErrNotFound otherwise.
+func (i *Index) update(cid string, create bool, fn func(file *File) error) (File, error) {
+	var file File
+	err := i.store.Update(func(tx *store.Tx) error {
+		var rec record
+		err := tx.Get(bucket, cid, &rec)
+		file = rec.file()
+		switch {
+		case errors.Is(err, store.ErrNotFound) && create:
+			file = File{Cid: cid}
+		case errors.Is(err, store.ErrNotFound):
+			return fmt.Errorf("%w: %s", ErrNotFound, cid)
+		case err != nil:
+			return err
+		}
+		if file.Tags == nil {
+			file.Tags = []string{}
+		}
+		if file.Meta == nil {
+			file.Meta = map[string]string{}
+		}
+
+		if err := fn(&file); err != nil {
+			return err
+		}
+		return tx.Put(bucket, cid, newRecord(file))
+	})
+	if err != nil {
+		return File{}, err
+	}
+	return file, nil
+}
+
+// List returns the metadata of every indexed file, keyed by CID.
+func (i *Index) List() (map[string]File, error) {
+	files := map[string]File{}
+	err := i.store.ForEach(bucket, func(key string, value []byte) error {
+		var rec record
+		if err := json.Unmarshal(value, &rec); err != nil {
+			return err
+		}
+		files[key] = rec.file()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Rebuild reconciles the index with the recursive pins of the IPFS node: entries of CIDs that are no longer
+// pinned are removed, and pins without an entry are indexed with what the node knows of them, their name.
+func (i *Index) Rebuild(ctx context.Context, client ipfs.Client) (RebuildResult, error) {
+	var result RebuildResult
+	pins, err := client.ListRecursivePins(ctx)
+	if err != nil {
+		return result, err
+	}
+	files, err := i.List()
+	if err != nil {
+		return result, err
+	}
+
+	for cid := range files {
+		if _, ok := pins[cid]; ok {
+			result.Kept++
+			continue
+		}
+		if err := i.Delete(cid); err != nil {
+			return result, err
+		}
+		result.Removed++
+	}
+
+	now := time.Now().UTC()
+	for cid, name := range pins {
+		if _, ok := files[cid]; ok {
+			continue
+		}
+		file := File{
+			Cid:        cid,
+			Name:       name,
+			Filename:   name,
+			MimeType:   DetectType(name, "", nil),
+			UploadedAt: now,
+		}
+		if err := i.Put(file); err != nil {
+			return result, err
+		}
+		result.Added++
+	}
+	return result, nil
+}
+
+// NormalizeTags trims the tags and drops duplicates, which are compared case-insensitively. Tags must have 1 to
+// 64 characters and no commas, which separate the tags of an upload.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || len(tag) > maxTagLength || strings.Contains(tag, ",") {
+			return nil, fmt.Errorf("%w: tags must have 1 to %d characters and no commas, got %q", ErrInvalid, maxTagLength, tag)
+		}
+		if !slices.ContainsFunc(normalized, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// HasTags reports whether the file has every one of the tags, compared case-insensitively.
+func (f File) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(f.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectType returns the MIME type of a file, from the extension of its filename if it is known, otherwise
+// from the declared type unless it is the generic binary type, otherwise by sniffing the head of its content.
+// Files with none of them are binary.
+func DetectType(filename, declared string, head []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(filename)); t != "" {
+		return t
+	}
+	if declared != "" && declared != "application/octet-stream" {
+		return declared
+	}
+	if len(head) > 0 {
+		return http.DetectContentType(head)
+	}
+	return "application/octet-stream"
+}
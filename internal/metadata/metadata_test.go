@@ -0,0 +1,176 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/encryption"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"github.com/zde37/Hive/internal/store"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestIndex(t *testing.T) *Index {
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return NewIndex(st)
+}
+
+func TestIndex(t *testing.T) {
+	i := newTestIndex(t)
+
+	_, err := i.Get("bafy1")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	file := File{
+		Cid:        "bafy1",
+		Name:       "report",
+		Filename:   "report.pdf",
+		MimeType:   "application/pdf",
+		Size:       42,
+		Uploader:   "alice",
+		UploadedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, i.Put(file))
+
+	got, err := i.Get("bafy1")
+	require.NoError(t, err)
+	file.Tags, file.Meta = []string{}, map[string]string{}
+	require.Equal(t, file, got)
+
+	files, err := i.List()
+	require.NoError(t, err)
+	require.Equal(t, map[string]File{"bafy1": file}, files)
+
+	require.NoError(t, i.Delete("bafy1"))
+	_, err = i.Get("bafy1")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestEncryptedFile(t *testing.T) {
+	i := newTestIndex(t)
+
+	envelope := &encryption.Envelope{Mode: encryption.ModeMaster, WrappedKey: []byte("wrapped"), KeyID: "0badc0de"}
+	require.NoError(t, i.Put(File{Cid: "bafy1", Name: "secret", Encryption: envelope}))
+
+	// the envelope is stored with the metadata and survives updates
+	_, err := i.AddTag("bafy1", "launch")
+	require.NoError(t, err)
+	got, err := i.Get("bafy1")
+	require.NoError(t, err)
+	require.True(t, got.Encrypted)
+	require.Equal(t, envelope, got.Encryption)
+	files, err := i.List()
+	require.NoError(t, err)
+	require.Equal(t, envelope, files["bafy1"].Encryption)
+
+	// but is not part of the JSON of the metadata
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"encrypted":true`)
+	require.NotContains(t, string(data), "wrapped_key")
+}
+
+func TestUpdate(t *testing.T) {
+	i := newTestIndex(t)
+	ptr := func(s string) *string { return &s }
+
+	_, err := i.RemoveTag("bafy1", "project-x")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = i.Clear("bafy1")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// describing a CID indexes it
+	file, err := i.Update("bafy1", Patch{
+		Tags:        &[]string{" project-x ", "Confidential", "project-X"},
+		Description: ptr("Survey results"),
+		Meta:        map[string]*string{"owner": ptr("research"), "year": ptr("2024")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, File{
+		Cid:         "bafy1",
+		Tags:        []string{"project-x", "Confidential"},
+		Description: "Survey results",
+		Meta:        map[string]string{"owner": "research", "year": "2024"},
+	}, file)
+
+	file, err = i.Update("bafy1", Patch{Meta: map[string]*string{"year": nil, "status": ptr("final")}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"project-x", "Confidential"}, file.Tags)
+	require.Equal(t, "Survey results", file.Description)
+	require.Equal(t, map[string]string{"owner": "research", "status": "final"}, file.Meta)
+
+	file, err = i.AddTag("bafy1", "public")
+	require.NoError(t, err)
+	require.Equal(t, []string{"project-x", "Confidential", "public"}, file.Tags)
+	file, err = i.RemoveTag("bafy1", "confidential")
+	require.NoError(t, err)
+	require.Equal(t, []string{"project-x", "public"}, file.Tags)
+	require.True(t, file.HasTags([]string{"PUBLIC", "project-x"}))
+	require.False(t, file.HasTags([]string{"public", "confidential"}))
+
+	for _, patch := range []Patch{
+		{Tags: &[]string{""}},
+		{Tags: &[]string{"a,b"}},
+		{Meta: map[string]*string{" ": ptr("value")}},
+		{Description: ptr(strings.Repeat("x", maxDescriptionLength+1))},
+	} {
+		_, err = i.Update("bafy1", patch)
+		require.ErrorIs(t, err, ErrInvalid)
+	}
+	got, err := i.Get("bafy1")
+	require.NoError(t, err)
+	require.Equal(t, file, got)
+
+	file, err = i.Clear("bafy1")
+	require.NoError(t, err)
+	require.Equal(t, File{Cid: "bafy1", Tags: []string{}, Meta: map[string]string{}}, file)
+}
+
+func TestRebuild(t *testing.T) {
+	i := newTestIndex(t)
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+
+	require.NoError(t, i.Put(File{Cid: "bafy1", Name: "kept", Filename: "kept.txt"}))
+	require.NoError(t, i.Put(File{Cid: "bafy2", Name: "unpinned"}))
+	mockClient.EXPECT().ListRecursivePins(gomock.Any()).Return(map[string]string{"bafy1": "kept", "bafy3": "notes.pdf"}, nil)
+
+	result, err := i.Rebuild(context.Background(), mockClient)
+	require.NoError(t, err)
+	require.Equal(t, RebuildResult{Added: 1, Removed: 1, Kept: 1}, result)
+
+	files, err := i.List()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "kept.txt", files["bafy1"].Filename)
+	require.Equal(t, "notes.pdf", files["bafy3"].Filename)
+	require.Equal(t, "application/pdf", files["bafy3"].MimeType)
+	require.False(t, files["bafy3"].UploadedAt.IsZero())
+}
+
+func TestDetectType(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		declared string
+		head     []byte
+		expected string
+	}{
+		{name: "Extension", filename: "photo.png", declared: "text/plain", expected: "image/png"},
+		{name: "Declared", filename: "README", declared: "text/plain", expected: "text/plain"},
+		{name: "Sniffed", filename: "README", declared: "application/octet-stream", head: []byte("%PDF-1.7"), expected: "application/pdf"},
+		{name: "Unknown", filename: "blob", expected: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, DetectType(tt.filename, tt.declared, tt.head))
+		})
+	}
+}
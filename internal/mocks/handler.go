@@ -39,6 +39,20 @@ func (m *MockHandler) EXPECT() *MockHandlerMockRecorder {
 	return m.recorder
 }
 
+// AddDenylistRule mocks base method.
+func (m *MockHandler) AddDenylistRule(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDenylistRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDenylistRule indicates an expected call of AddDenylistRule.
+func (mr *MockHandlerMockRecorder) AddDenylistRule(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDenylistRule", reflect.TypeOf((*MockHandler)(nil).AddDenylistRule), arg0, arg1)
+}
+
 // AddFile mocks base method.
 func (m *MockHandler) AddFile(arg0 http.ResponseWriter, arg1 *http.Request) error {
 	m.ctrl.T.Helper()
@@ -53,6 +67,202 @@ func (mr *MockHandlerMockRecorder) AddFile(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFile", reflect.TypeOf((*MockHandler)(nil).AddFile), arg0, arg1)
 }
 
+// AddFileTag mocks base method.
+func (m *MockHandler) AddFileTag(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFileTag", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFileTag indicates an expected call of AddFileTag.
+func (mr *MockHandlerMockRecorder) AddFileTag(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFileTag", reflect.TypeOf((*MockHandler)(nil).AddFileTag), arg0, arg1)
+}
+
+// AddFolder mocks base method.
+func (m *MockHandler) AddFolder(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFolder", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFolder indicates an expected call of AddFolder.
+func (mr *MockHandlerMockRecorder) AddFolder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFolder", reflect.TypeOf((*MockHandler)(nil).AddFolder), arg0, arg1)
+}
+
+// AddPSAPin mocks base method.
+func (m *MockHandler) AddPSAPin(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPSAPin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPSAPin indicates an expected call of AddPSAPin.
+func (mr *MockHandlerMockRecorder) AddPSAPin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPSAPin", reflect.TypeOf((*MockHandler)(nil).AddPSAPin), arg0, arg1)
+}
+
+// AddPeering mocks base method.
+func (m *MockHandler) AddPeering(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPeering", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPeering indicates an expected call of AddPeering.
+func (mr *MockHandlerMockRecorder) AddPeering(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPeering", reflect.TypeOf((*MockHandler)(nil).AddPeering), arg0, arg1)
+}
+
+// AddRemotePin mocks base method.
+func (m *MockHandler) AddRemotePin(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRemotePin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRemotePin indicates an expected call of AddRemotePin.
+func (mr *MockHandlerMockRecorder) AddRemotePin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRemotePin", reflect.TypeOf((*MockHandler)(nil).AddRemotePin), arg0, arg1)
+}
+
+// AddRemoteService mocks base method.
+func (m *MockHandler) AddRemoteService(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRemoteService", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRemoteService indicates an expected call of AddRemoteService.
+func (mr *MockHandlerMockRecorder) AddRemoteService(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRemoteService", reflect.TypeOf((*MockHandler)(nil).AddRemoteService), arg0, arg1)
+}
+
+// CheckPinHealth mocks base method.
+func (m *MockHandler) CheckPinHealth(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPinHealth", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckPinHealth indicates an expected call of CheckPinHealth.
+func (mr *MockHandlerMockRecorder) CheckPinHealth(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPinHealth", reflect.TypeOf((*MockHandler)(nil).CheckPinHealth), arg0, arg1)
+}
+
+// ClearFileMetadata mocks base method.
+func (m *MockHandler) ClearFileMetadata(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearFileMetadata indicates an expected call of ClearFileMetadata.
+func (mr *MockHandlerMockRecorder) ClearFileMetadata(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearFileMetadata", reflect.TypeOf((*MockHandler)(nil).ClearFileMetadata), arg0, arg1)
+}
+
+// ConnectPeer mocks base method.
+func (m *MockHandler) ConnectPeer(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectPeer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConnectPeer indicates an expected call of ConnectPeer.
+func (mr *MockHandlerMockRecorder) ConnectPeer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectPeer", reflect.TypeOf((*MockHandler)(nil).ConnectPeer), arg0, arg1)
+}
+
+// CreateShare mocks base method.
+func (m *MockHandler) CreateShare(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShare", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateShare indicates an expected call of CreateShare.
+func (mr *MockHandlerMockRecorder) CreateShare(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShare", reflect.TypeOf((*MockHandler)(nil).CreateShare), arg0, arg1)
+}
+
+// DagGet mocks base method.
+func (m *MockHandler) DagGet(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DagGet", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DagGet indicates an expected call of DagGet.
+func (mr *MockHandlerMockRecorder) DagGet(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagGet", reflect.TypeOf((*MockHandler)(nil).DagGet), arg0, arg1)
+}
+
+// DagPut mocks base method.
+func (m *MockHandler) DagPut(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DagPut", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DagPut indicates an expected call of DagPut.
+func (mr *MockHandlerMockRecorder) DagPut(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagPut", reflect.TypeOf((*MockHandler)(nil).DagPut), arg0, arg1)
+}
+
+// DagResolve mocks base method.
+func (m *MockHandler) DagResolve(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DagResolve", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DagResolve indicates an expected call of DagResolve.
+func (mr *MockHandlerMockRecorder) DagResolve(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagResolve", reflect.TypeOf((*MockHandler)(nil).DagResolve), arg0, arg1)
+}
+
+// DagStat mocks base method.
+func (m *MockHandler) DagStat(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DagStat", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DagStat indicates an expected call of DagStat.
+func (mr *MockHandlerMockRecorder) DagStat(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagStat", reflect.TypeOf((*MockHandler)(nil).DagStat), arg0, arg1)
+}
+
 // DeleteFile mocks base method.
 func (m *MockHandler) DeleteFile(arg0 http.ResponseWriter, arg1 *http.Request) error {
 	m.ctrl.T.Helper()
@@ -67,6 +277,48 @@ func (mr *MockHandlerMockRecorder) DeleteFile(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockHandler)(nil).DeleteFile), arg0, arg1)
 }
 
+// DeletePinJob mocks base method.
+func (m *MockHandler) DeletePinJob(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePinJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePinJob indicates an expected call of DeletePinJob.
+func (mr *MockHandlerMockRecorder) DeletePinJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePinJob", reflect.TypeOf((*MockHandler)(nil).DeletePinJob), arg0, arg1)
+}
+
+// DiffVersions mocks base method.
+func (m *MockHandler) DiffVersions(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffVersions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DiffVersions indicates an expected call of DiffVersions.
+func (mr *MockHandlerMockRecorder) DiffVersions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffVersions", reflect.TypeOf((*MockHandler)(nil).DiffVersions), arg0, arg1)
+}
+
+// DisconnectPeer mocks base method.
+func (m *MockHandler) DisconnectPeer(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisconnectPeer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisconnectPeer indicates an expected call of DisconnectPeer.
+func (mr *MockHandlerMockRecorder) DisconnectPeer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisconnectPeer", reflect.TypeOf((*MockHandler)(nil).DisconnectPeer), arg0, arg1)
+}
+
 // DisplayFileContents mocks base method.
 func (m *MockHandler) DisplayFileContents(arg0 http.ResponseWriter, arg1 *http.Request) error {
 	m.ctrl.T.Helper()
@@ -109,6 +361,90 @@ func (mr *MockHandlerMockRecorder) DownloadFolder(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadFolder", reflect.TypeOf((*MockHandler)(nil).DownloadFolder), arg0, arg1)
 }
 
+// DownloadShare mocks base method.
+func (m *MockHandler) DownloadShare(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadShare", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownloadShare indicates an expected call of DownloadShare.
+func (mr *MockHandlerMockRecorder) DownloadShare(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadShare", reflect.TypeOf((*MockHandler)(nil).DownloadShare), arg0, arg1)
+}
+
+// FindProviders mocks base method.
+func (m *MockHandler) FindProviders(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProviders", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FindProviders indicates an expected call of FindProviders.
+func (mr *MockHandlerMockRecorder) FindProviders(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProviders", reflect.TypeOf((*MockHandler)(nil).FindProviders), arg0, arg1)
+}
+
+// GatewayMux mocks base method.
+func (m *MockHandler) GatewayMux() *http.ServeMux {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GatewayMux")
+	ret0, _ := ret[0].(*http.ServeMux)
+	return ret0
+}
+
+// GatewayMux indicates an expected call of GatewayMux.
+func (mr *MockHandlerMockRecorder) GatewayMux() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GatewayMux", reflect.TypeOf((*MockHandler)(nil).GatewayMux))
+}
+
+// GetDenylistAudit mocks base method.
+func (m *MockHandler) GetDenylistAudit(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDenylistAudit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetDenylistAudit indicates an expected call of GetDenylistAudit.
+func (mr *MockHandlerMockRecorder) GetDenylistAudit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDenylistAudit", reflect.TypeOf((*MockHandler)(nil).GetDenylistAudit), arg0, arg1)
+}
+
+// GetFileMetadata mocks base method.
+func (m *MockHandler) GetFileMetadata(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetFileMetadata indicates an expected call of GetFileMetadata.
+func (mr *MockHandlerMockRecorder) GetFileMetadata(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileMetadata", reflect.TypeOf((*MockHandler)(nil).GetFileMetadata), arg0, arg1)
+}
+
+// GetGCHistory mocks base method.
+func (m *MockHandler) GetGCHistory(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGCHistory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetGCHistory indicates an expected call of GetGCHistory.
+func (mr *MockHandlerMockRecorder) GetGCHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGCHistory", reflect.TypeOf((*MockHandler)(nil).GetGCHistory), arg0, arg1)
+}
+
 // GetNodeInfo mocks base method.
 func (m *MockHandler) GetNodeInfo(arg0 http.ResponseWriter, arg1 *http.Request) error {
 	m.ctrl.T.Helper()
@@ -123,6 +459,76 @@ func (mr *MockHandlerMockRecorder) GetNodeInfo(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodeInfo", reflect.TypeOf((*MockHandler)(nil).GetNodeInfo), arg0, arg1)
 }
 
+// GetPSAPin mocks base method.
+func (m *MockHandler) GetPSAPin(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPSAPin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetPSAPin indicates an expected call of GetPSAPin.
+func (mr *MockHandlerMockRecorder) GetPSAPin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPSAPin", reflect.TypeOf((*MockHandler)(nil).GetPSAPin), arg0, arg1)
+}
+
+// GetPinHealth mocks base method.
+func (m *MockHandler) GetPinHealth(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPinHealth", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetPinHealth indicates an expected call of GetPinHealth.
+func (mr *MockHandlerMockRecorder) GetPinHealth(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPinHealth", reflect.TypeOf((*MockHandler)(nil).GetPinHealth), arg0, arg1)
+}
+
+// GetPinJob mocks base method.
+func (m *MockHandler) GetPinJob(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPinJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetPinJob indicates an expected call of GetPinJob.
+func (mr *MockHandlerMockRecorder) GetPinJob(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPinJob", reflect.TypeOf((*MockHandler)(nil).GetPinJob), arg0, arg1)
+}
+
+// GetSelf mocks base method.
+func (m *MockHandler) GetSelf(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSelf", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetSelf indicates an expected call of GetSelf.
+func (mr *MockHandlerMockRecorder) GetSelf(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSelf", reflect.TypeOf((*MockHandler)(nil).GetSelf), arg0, arg1)
+}
+
+// GetStats mocks base method.
+func (m *MockHandler) GetStats(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockHandlerMockRecorder) GetStats(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockHandler)(nil).GetStats), arg0, arg1)
+}
+
 // Health mocks base method.
 func (m *MockHandler) Health(arg0 http.ResponseWriter, arg1 *http.Request) error {
 	m.ctrl.T.Helper()
@@ -137,6 +543,62 @@ func (mr *MockHandlerMockRecorder) Health(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockHandler)(nil).Health), arg0, arg1)
 }
 
+// IPFSGateway mocks base method.
+func (m *MockHandler) IPFSGateway(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IPFSGateway", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IPFSGateway indicates an expected call of IPFSGateway.
+func (mr *MockHandlerMockRecorder) IPFSGateway(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IPFSGateway", reflect.TypeOf((*MockHandler)(nil).IPFSGateway), arg0, arg1)
+}
+
+// IPNSGateway mocks base method.
+func (m *MockHandler) IPNSGateway(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IPNSGateway", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IPNSGateway indicates an expected call of IPNSGateway.
+func (mr *MockHandlerMockRecorder) IPNSGateway(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IPNSGateway", reflect.TypeOf((*MockHandler)(nil).IPNSGateway), arg0, arg1)
+}
+
+// ImportCar mocks base method.
+func (m *MockHandler) ImportCar(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportCar", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportCar indicates an expected call of ImportCar.
+func (mr *MockHandlerMockRecorder) ImportCar(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCar", reflect.TypeOf((*MockHandler)(nil).ImportCar), arg0, arg1)
+}
+
+// ListDenylist mocks base method.
+func (m *MockHandler) ListDenylist(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDenylist", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListDenylist indicates an expected call of ListDenylist.
+func (mr *MockHandlerMockRecorder) ListDenylist(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDenylist", reflect.TypeOf((*MockHandler)(nil).ListDenylist), arg0, arg1)
+}
+
 // ListNodes mocks base method.
 func (m *MockHandler) ListNodes(arg0 http.ResponseWriter, arg1 *http.Request) error {
 	m.ctrl.T.Helper()
@@ -151,6 +613,48 @@ func (mr *MockHandlerMockRecorder) ListNodes(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNodes", reflect.TypeOf((*MockHandler)(nil).ListNodes), arg0, arg1)
 }
 
+// ListPSAPins mocks base method.
+func (m *MockHandler) ListPSAPins(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPSAPins", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListPSAPins indicates an expected call of ListPSAPins.
+func (mr *MockHandlerMockRecorder) ListPSAPins(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPSAPins", reflect.TypeOf((*MockHandler)(nil).ListPSAPins), arg0, arg1)
+}
+
+// ListPeering mocks base method.
+func (m *MockHandler) ListPeering(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPeering", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListPeering indicates an expected call of ListPeering.
+func (mr *MockHandlerMockRecorder) ListPeering(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPeering", reflect.TypeOf((*MockHandler)(nil).ListPeering), arg0, arg1)
+}
+
+// ListPinJobs mocks base method.
+func (m *MockHandler) ListPinJobs(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPinJobs", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListPinJobs indicates an expected call of ListPinJobs.
+func (mr *MockHandlerMockRecorder) ListPinJobs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPinJobs", reflect.TypeOf((*MockHandler)(nil).ListPinJobs), arg0, arg1)
+}
+
 // ListPins mocks base method.
 func (m *MockHandler) ListPins(arg0 http.ResponseWriter, arg1 *http.Request) error {
 	m.ctrl.T.Helper()
@@ -165,6 +669,104 @@ func (mr *MockHandlerMockRecorder) ListPins(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPins", reflect.TypeOf((*MockHandler)(nil).ListPins), arg0, arg1)
 }
 
+// ListPubsubPeers mocks base method.
+func (m *MockHandler) ListPubsubPeers(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubsubPeers", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListPubsubPeers indicates an expected call of ListPubsubPeers.
+func (mr *MockHandlerMockRecorder) ListPubsubPeers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubsubPeers", reflect.TypeOf((*MockHandler)(nil).ListPubsubPeers), arg0, arg1)
+}
+
+// ListPubsubTopics mocks base method.
+func (m *MockHandler) ListPubsubTopics(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubsubTopics", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListPubsubTopics indicates an expected call of ListPubsubTopics.
+func (mr *MockHandlerMockRecorder) ListPubsubTopics(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubsubTopics", reflect.TypeOf((*MockHandler)(nil).ListPubsubTopics), arg0, arg1)
+}
+
+// ListQuarantine mocks base method.
+func (m *MockHandler) ListQuarantine(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQuarantine", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListQuarantine indicates an expected call of ListQuarantine.
+func (mr *MockHandlerMockRecorder) ListQuarantine(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQuarantine", reflect.TypeOf((*MockHandler)(nil).ListQuarantine), arg0, arg1)
+}
+
+// ListRemotePins mocks base method.
+func (m *MockHandler) ListRemotePins(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRemotePins", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListRemotePins indicates an expected call of ListRemotePins.
+func (mr *MockHandlerMockRecorder) ListRemotePins(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRemotePins", reflect.TypeOf((*MockHandler)(nil).ListRemotePins), arg0, arg1)
+}
+
+// ListRemoteServices mocks base method.
+func (m *MockHandler) ListRemoteServices(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRemoteServices", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListRemoteServices indicates an expected call of ListRemoteServices.
+func (mr *MockHandlerMockRecorder) ListRemoteServices(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRemoteServices", reflect.TypeOf((*MockHandler)(nil).ListRemoteServices), arg0, arg1)
+}
+
+// ListShares mocks base method.
+func (m *MockHandler) ListShares(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShares", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListShares indicates an expected call of ListShares.
+func (mr *MockHandlerMockRecorder) ListShares(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShares", reflect.TypeOf((*MockHandler)(nil).ListShares), arg0, arg1)
+}
+
+// ListVersions mocks base method.
+func (m *MockHandler) ListVersions(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListVersions indicates an expected call of ListVersions.
+func (mr *MockHandlerMockRecorder) ListVersions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersions", reflect.TypeOf((*MockHandler)(nil).ListVersions), arg0, arg1)
+}
+
 // Mux mocks base method.
 func (m *MockHandler) Mux() *http.ServeMux {
 	m.ctrl.T.Helper()
@@ -179,6 +781,20 @@ func (mr *MockHandlerMockRecorder) Mux() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mux", reflect.TypeOf((*MockHandler)(nil).Mux))
 }
 
+// OpenShare mocks base method.
+func (m *MockHandler) OpenShare(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenShare", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OpenShare indicates an expected call of OpenShare.
+func (mr *MockHandlerMockRecorder) OpenShare(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenShare", reflect.TypeOf((*MockHandler)(nil).OpenShare), arg0, arg1)
+}
+
 // PinObject mocks base method.
 func (m *MockHandler) PinObject(arg0 http.ResponseWriter, arg1 *http.Request) error {
 	m.ctrl.T.Helper()
@@ -206,3 +822,269 @@ func (mr *MockHandlerMockRecorder) PingNode(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingNode", reflect.TypeOf((*MockHandler)(nil).PingNode), arg0, arg1)
 }
+
+// Preview mocks base method.
+func (m *MockHandler) Preview(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preview", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Preview indicates an expected call of Preview.
+func (mr *MockHandlerMockRecorder) Preview(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preview", reflect.TypeOf((*MockHandler)(nil).Preview), arg0, arg1)
+}
+
+// Provide mocks base method.
+func (m *MockHandler) Provide(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Provide", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Provide indicates an expected call of Provide.
+func (mr *MockHandlerMockRecorder) Provide(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Provide", reflect.TypeOf((*MockHandler)(nil).Provide), arg0, arg1)
+}
+
+// PublishPubsub mocks base method.
+func (m *MockHandler) PublishPubsub(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPubsub", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishPubsub indicates an expected call of PublishPubsub.
+func (mr *MockHandlerMockRecorder) PublishPubsub(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPubsub", reflect.TypeOf((*MockHandler)(nil).PublishPubsub), arg0, arg1)
+}
+
+// PubsubBridge mocks base method.
+func (m *MockHandler) PubsubBridge(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubsubBridge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PubsubBridge indicates an expected call of PubsubBridge.
+func (mr *MockHandlerMockRecorder) PubsubBridge(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubsubBridge", reflect.TypeOf((*MockHandler)(nil).PubsubBridge), arg0, arg1)
+}
+
+// ReloadDenylist mocks base method.
+func (m *MockHandler) ReloadDenylist(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadDenylist", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadDenylist indicates an expected call of ReloadDenylist.
+func (mr *MockHandlerMockRecorder) ReloadDenylist(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadDenylist", reflect.TypeOf((*MockHandler)(nil).ReloadDenylist), arg0, arg1)
+}
+
+// RemoveDenylistRule mocks base method.
+func (m *MockHandler) RemoveDenylistRule(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveDenylistRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDenylistRule indicates an expected call of RemoveDenylistRule.
+func (mr *MockHandlerMockRecorder) RemoveDenylistRule(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDenylistRule", reflect.TypeOf((*MockHandler)(nil).RemoveDenylistRule), arg0, arg1)
+}
+
+// RemoveFileTag mocks base method.
+func (m *MockHandler) RemoveFileTag(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFileTag", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFileTag indicates an expected call of RemoveFileTag.
+func (mr *MockHandlerMockRecorder) RemoveFileTag(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFileTag", reflect.TypeOf((*MockHandler)(nil).RemoveFileTag), arg0, arg1)
+}
+
+// RemovePSAPin mocks base method.
+func (m *MockHandler) RemovePSAPin(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePSAPin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePSAPin indicates an expected call of RemovePSAPin.
+func (mr *MockHandlerMockRecorder) RemovePSAPin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePSAPin", reflect.TypeOf((*MockHandler)(nil).RemovePSAPin), arg0, arg1)
+}
+
+// RemovePeering mocks base method.
+func (m *MockHandler) RemovePeering(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePeering", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePeering indicates an expected call of RemovePeering.
+func (mr *MockHandlerMockRecorder) RemovePeering(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePeering", reflect.TypeOf((*MockHandler)(nil).RemovePeering), arg0, arg1)
+}
+
+// RemoveRemotePin mocks base method.
+func (m *MockHandler) RemoveRemotePin(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRemotePin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRemotePin indicates an expected call of RemoveRemotePin.
+func (mr *MockHandlerMockRecorder) RemoveRemotePin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRemotePin", reflect.TypeOf((*MockHandler)(nil).RemoveRemotePin), arg0, arg1)
+}
+
+// RemoveRemoteService mocks base method.
+func (m *MockHandler) RemoveRemoteService(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRemoteService", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRemoteService indicates an expected call of RemoveRemoteService.
+func (mr *MockHandlerMockRecorder) RemoveRemoteService(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRemoteService", reflect.TypeOf((*MockHandler)(nil).RemoveRemoteService), arg0, arg1)
+}
+
+// ReplacePSAPin mocks base method.
+func (m *MockHandler) ReplacePSAPin(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePSAPin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplacePSAPin indicates an expected call of ReplacePSAPin.
+func (mr *MockHandlerMockRecorder) ReplacePSAPin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePSAPin", reflect.TypeOf((*MockHandler)(nil).ReplacePSAPin), arg0, arg1)
+}
+
+// RevokeShare mocks base method.
+func (m *MockHandler) RevokeShare(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeShare", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeShare indicates an expected call of RevokeShare.
+func (mr *MockHandlerMockRecorder) RevokeShare(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeShare", reflect.TypeOf((*MockHandler)(nil).RevokeShare), arg0, arg1)
+}
+
+// RollbackVersion mocks base method.
+func (m *MockHandler) RollbackVersion(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackVersion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackVersion indicates an expected call of RollbackVersion.
+func (mr *MockHandlerMockRecorder) RollbackVersion(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackVersion", reflect.TypeOf((*MockHandler)(nil).RollbackVersion), arg0, arg1)
+}
+
+// RunGC mocks base method.
+func (m *MockHandler) RunGC(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunGC", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunGC indicates an expected call of RunGC.
+func (mr *MockHandlerMockRecorder) RunGC(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunGC", reflect.TypeOf((*MockHandler)(nil).RunGC), arg0, arg1)
+}
+
+// Search mocks base method.
+func (m *MockHandler) Search(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockHandlerMockRecorder) Search(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockHandler)(nil).Search), arg0, arg1)
+}
+
+// Thumbnail mocks base method.
+func (m *MockHandler) Thumbnail(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Thumbnail", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Thumbnail indicates an expected call of Thumbnail.
+func (mr *MockHandlerMockRecorder) Thumbnail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Thumbnail", reflect.TypeOf((*MockHandler)(nil).Thumbnail), arg0, arg1)
+}
+
+// UpdateFileMetadata mocks base method.
+func (m *MockHandler) UpdateFileMetadata(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFileMetadata indicates an expected call of UpdateFileMetadata.
+func (mr *MockHandlerMockRecorder) UpdateFileMetadata(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileMetadata", reflect.TypeOf((*MockHandler)(nil).UpdateFileMetadata), arg0, arg1)
+}
+
+// UpdatePin mocks base method.
+func (m *MockHandler) UpdatePin(arg0 http.ResponseWriter, arg1 *http.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePin indicates an expected call of UpdatePin.
+func (mr *MockHandlerMockRecorder) UpdatePin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePin", reflect.TypeOf((*MockHandler)(nil).UpdatePin), arg0, arg1)
+}
@@ -0,0 +1,801 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Hive/internal/ipfs (interfaces: Client)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mocks/ipfs.go github.com/zde37/Hive/internal/ipfs Client
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	ipfs "github.com/zde37/Hive/internal/ipfs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockClient) Add(arg0 context.Context, arg1, arg2 string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockClientMockRecorder) Add(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockClient)(nil).Add), arg0, arg1, arg2)
+}
+
+// AddPeering mocks base method.
+func (m *MockClient) AddPeering(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPeering", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPeering indicates an expected call of AddPeering.
+func (mr *MockClientMockRecorder) AddPeering(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPeering", reflect.TypeOf((*MockClient)(nil).AddPeering), arg0, arg1)
+}
+
+// AddRemotePin mocks base method.
+func (m *MockClient) AddRemotePin(arg0 context.Context, arg1, arg2, arg3 string) (ipfs.RemotePin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRemotePin", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(ipfs.RemotePin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddRemotePin indicates an expected call of AddRemotePin.
+func (mr *MockClientMockRecorder) AddRemotePin(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRemotePin", reflect.TypeOf((*MockClient)(nil).AddRemotePin), arg0, arg1, arg2, arg3)
+}
+
+// AddRemoteService mocks base method.
+func (m *MockClient) AddRemoteService(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRemoteService", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRemoteService indicates an expected call of AddRemoteService.
+func (mr *MockClientMockRecorder) AddRemoteService(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRemoteService", reflect.TypeOf((*MockClient)(nil).AddRemoteService), arg0, arg1, arg2, arg3)
+}
+
+// BandwidthStat mocks base method.
+func (m *MockClient) BandwidthStat(arg0 context.Context, arg1 string) (ipfs.BandwidthStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BandwidthStat", arg0, arg1)
+	ret0, _ := ret[0].(ipfs.BandwidthStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BandwidthStat indicates an expected call of BandwidthStat.
+func (mr *MockClientMockRecorder) BandwidthStat(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BandwidthStat", reflect.TypeOf((*MockClient)(nil).BandwidthStat), arg0, arg1)
+}
+
+// BitswapStat mocks base method.
+func (m *MockClient) BitswapStat(arg0 context.Context) (ipfs.BitswapStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BitswapStat", arg0)
+	ret0, _ := ret[0].(ipfs.BitswapStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BitswapStat indicates an expected call of BitswapStat.
+func (mr *MockClientMockRecorder) BitswapStat(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BitswapStat", reflect.TypeOf((*MockClient)(nil).BitswapStat), arg0)
+}
+
+// DHTStat mocks base method.
+func (m *MockClient) DHTStat(arg0 context.Context) ([]ipfs.DHTStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DHTStat", arg0)
+	ret0, _ := ret[0].([]ipfs.DHTStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DHTStat indicates an expected call of DHTStat.
+func (mr *MockClientMockRecorder) DHTStat(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DHTStat", reflect.TypeOf((*MockClient)(nil).DHTStat), arg0)
+}
+
+// DagGet mocks base method.
+func (m *MockClient) DagGet(arg0 context.Context, arg1, arg2 string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DagGet", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DagGet indicates an expected call of DagGet.
+func (mr *MockClientMockRecorder) DagGet(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagGet", reflect.TypeOf((*MockClient)(nil).DagGet), arg0, arg1, arg2)
+}
+
+// DagPut mocks base method.
+func (m *MockClient) DagPut(arg0 context.Context, arg1 io.Reader, arg2, arg3 string, arg4 bool) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DagPut", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DagPut indicates an expected call of DagPut.
+func (mr *MockClientMockRecorder) DagPut(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagPut", reflect.TypeOf((*MockClient)(nil).DagPut), arg0, arg1, arg2, arg3, arg4)
+}
+
+// DagResolve mocks base method.
+func (m *MockClient) DagResolve(arg0 context.Context, arg1 string) (ipfs.DagResolveResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DagResolve", arg0, arg1)
+	ret0, _ := ret[0].(ipfs.DagResolveResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DagResolve indicates an expected call of DagResolve.
+func (mr *MockClientMockRecorder) DagResolve(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagResolve", reflect.TypeOf((*MockClient)(nil).DagResolve), arg0, arg1)
+}
+
+// DagStat mocks base method.
+func (m *MockClient) DagStat(arg0 context.Context, arg1 string) (ipfs.DagStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DagStat", arg0, arg1)
+	ret0, _ := ret[0].(ipfs.DagStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DagStat indicates an expected call of DagStat.
+func (mr *MockClientMockRecorder) DagStat(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DagStat", reflect.TypeOf((*MockClient)(nil).DagStat), arg0, arg1)
+}
+
+// DeleteFile mocks base method.
+func (m *MockClient) DeleteFile(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFile indicates an expected call of DeleteFile.
+func (mr *MockClientMockRecorder) DeleteFile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockClient)(nil).DeleteFile), arg0, arg1)
+}
+
+// DisplayFileContent mocks base method.
+func (m *MockClient) DisplayFileContent(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisplayFileContent", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisplayFileContent indicates an expected call of DisplayFileContent.
+func (mr *MockClientMockRecorder) DisplayFileContent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisplayFileContent", reflect.TypeOf((*MockClient)(nil).DisplayFileContent), arg0, arg1)
+}
+
+// DownloadDir mocks base method.
+func (m *MockClient) DownloadDir(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadDir", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownloadDir indicates an expected call of DownloadDir.
+func (mr *MockClientMockRecorder) DownloadDir(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadDir", reflect.TypeOf((*MockClient)(nil).DownloadDir), arg0, arg1, arg2)
+}
+
+// DownloadFile mocks base method.
+func (m *MockClient) DownloadFile(arg0 context.Context, arg1 string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadFile", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadFile indicates an expected call of DownloadFile.
+func (mr *MockClientMockRecorder) DownloadFile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadFile", reflect.TypeOf((*MockClient)(nil).DownloadFile), arg0, arg1)
+}
+
+// ExportCar mocks base method.
+func (m *MockClient) ExportCar(arg0 context.Context, arg1 string, arg2 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportCar", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportCar indicates an expected call of ExportCar.
+func (mr *MockClientMockRecorder) ExportCar(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportCar", reflect.TypeOf((*MockClient)(nil).ExportCar), arg0, arg1, arg2)
+}
+
+// FetchBlock mocks base method.
+func (m *MockClient) FetchBlock(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchBlock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FetchBlock indicates an expected call of FetchBlock.
+func (mr *MockClientMockRecorder) FetchBlock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchBlock", reflect.TypeOf((*MockClient)(nil).FetchBlock), arg0, arg1)
+}
+
+// FindProviders mocks base method.
+func (m *MockClient) FindProviders(arg0 context.Context, arg1 string, arg2 int, arg3 func(ipfs.Provider) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProviders", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FindProviders indicates an expected call of FindProviders.
+func (mr *MockClientMockRecorder) FindProviders(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProviders", reflect.TypeOf((*MockClient)(nil).FindProviders), arg0, arg1, arg2, arg3)
+}
+
+// GarbageCollect mocks base method.
+func (m *MockClient) GarbageCollect(arg0 context.Context, arg1 func(string) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GarbageCollect", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GarbageCollect indicates an expected call of GarbageCollect.
+func (mr *MockClientMockRecorder) GarbageCollect(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GarbageCollect", reflect.TypeOf((*MockClient)(nil).GarbageCollect), arg0, arg1)
+}
+
+// GetBlock mocks base method.
+func (m *MockClient) GetBlock(arg0 context.Context, arg1 string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlock", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlock indicates an expected call of GetBlock.
+func (mr *MockClientMockRecorder) GetBlock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlock", reflect.TypeOf((*MockClient)(nil).GetBlock), arg0, arg1)
+}
+
+// ImportCar mocks base method.
+func (m *MockClient) ImportCar(arg0 context.Context, arg1, arg2 string) (ipfs.CarImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportCar", arg0, arg1, arg2)
+	ret0, _ := ret[0].(ipfs.CarImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportCar indicates an expected call of ImportCar.
+func (mr *MockClientMockRecorder) ImportCar(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCar", reflect.TypeOf((*MockClient)(nil).ImportCar), arg0, arg1, arg2)
+}
+
+// ListConnectedNodes mocks base method.
+func (m *MockClient) ListConnectedNodes(arg0 context.Context) ([]ipfs.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConnectedNodes", arg0)
+	ret0, _ := ret[0].([]ipfs.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConnectedNodes indicates an expected call of ListConnectedNodes.
+func (mr *MockClientMockRecorder) ListConnectedNodes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConnectedNodes", reflect.TypeOf((*MockClient)(nil).ListConnectedNodes), arg0)
+}
+
+// ListDir mocks base method.
+func (m *MockClient) ListDir(arg0 context.Context, arg1 string) ([]ipfs.DirFileDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDir", arg0, arg1)
+	ret0, _ := ret[0].([]ipfs.DirFileDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDir indicates an expected call of ListDir.
+func (mr *MockClientMockRecorder) ListDir(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDir", reflect.TypeOf((*MockClient)(nil).ListDir), arg0, arg1)
+}
+
+// ListPeering mocks base method.
+func (m *MockClient) ListPeering(arg0 context.Context) ([]ipfs.PeeringPeer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPeering", arg0)
+	ret0, _ := ret[0].([]ipfs.PeeringPeer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPeering indicates an expected call of ListPeering.
+func (mr *MockClientMockRecorder) ListPeering(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPeering", reflect.TypeOf((*MockClient)(nil).ListPeering), arg0)
+}
+
+// ListPins mocks base method.
+func (m *MockClient) ListPins(arg0 context.Context) (any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPins", arg0)
+	ret0, _ := ret[0].(any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPins indicates an expected call of ListPins.
+func (mr *MockClientMockRecorder) ListPins(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPins", reflect.TypeOf((*MockClient)(nil).ListPins), arg0)
+}
+
+// ListRecursivePins mocks base method.
+func (m *MockClient) ListRecursivePins(arg0 context.Context) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecursivePins", arg0)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecursivePins indicates an expected call of ListRecursivePins.
+func (mr *MockClientMockRecorder) ListRecursivePins(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecursivePins", reflect.TypeOf((*MockClient)(nil).ListRecursivePins), arg0)
+}
+
+// ListRefs mocks base method.
+func (m *MockClient) ListRefs(arg0 context.Context, arg1 string, arg2 func(string) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRefs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListRefs indicates an expected call of ListRefs.
+func (mr *MockClientMockRecorder) ListRefs(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRefs", reflect.TypeOf((*MockClient)(nil).ListRefs), arg0, arg1, arg2)
+}
+
+// ListRemotePins mocks base method.
+func (m *MockClient) ListRemotePins(arg0 context.Context, arg1 string, arg2, arg3 []string) ([]ipfs.RemotePin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRemotePins", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]ipfs.RemotePin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRemotePins indicates an expected call of ListRemotePins.
+func (mr *MockClientMockRecorder) ListRemotePins(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRemotePins", reflect.TypeOf((*MockClient)(nil).ListRemotePins), arg0, arg1, arg2, arg3)
+}
+
+// ListRemoteServices mocks base method.
+func (m *MockClient) ListRemoteServices(arg0 context.Context, arg1 bool) ([]ipfs.RemoteService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRemoteServices", arg0, arg1)
+	ret0, _ := ret[0].([]ipfs.RemoteService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRemoteServices indicates an expected call of ListRemoteServices.
+func (mr *MockClientMockRecorder) ListRemoteServices(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRemoteServices", reflect.TypeOf((*MockClient)(nil).ListRemoteServices), arg0, arg1)
+}
+
+// NodeInfo mocks base method.
+func (m *MockClient) NodeInfo(arg0 context.Context, arg1 string) (ipfs.NodeInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeInfo", arg0, arg1)
+	ret0, _ := ret[0].(ipfs.NodeInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodeInfo indicates an expected call of NodeInfo.
+func (mr *MockClientMockRecorder) NodeInfo(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeInfo", reflect.TypeOf((*MockClient)(nil).NodeInfo), arg0, arg1)
+}
+
+// OpenFile mocks base method.
+func (m *MockClient) OpenFile(arg0 context.Context, arg1 string) (ipfs.FileReader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenFile", arg0, arg1)
+	ret0, _ := ret[0].(ipfs.FileReader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenFile indicates an expected call of OpenFile.
+func (mr *MockClientMockRecorder) OpenFile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenFile", reflect.TypeOf((*MockClient)(nil).OpenFile), arg0, arg1)
+}
+
+// PinObject mocks base method.
+func (m *MockClient) PinObject(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinObject", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinObject indicates an expected call of PinObject.
+func (mr *MockClientMockRecorder) PinObject(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinObject", reflect.TypeOf((*MockClient)(nil).PinObject), arg0, arg1, arg2)
+}
+
+// PinObjectProgress mocks base method.
+func (m *MockClient) PinObjectProgress(arg0 context.Context, arg1, arg2 string, arg3 func(int) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinObjectProgress", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinObjectProgress indicates an expected call of PinObjectProgress.
+func (mr *MockClientMockRecorder) PinObjectProgress(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinObjectProgress", reflect.TypeOf((*MockClient)(nil).PinObjectProgress), arg0, arg1, arg2, arg3)
+}
+
+// Ping mocks base method.
+func (m *MockClient) Ping(arg0 context.Context, arg1 string, arg2 int, arg3 func(ipfs.PingInfo) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockClientMockRecorder) Ping(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockClient)(nil).Ping), arg0, arg1, arg2, arg3)
+}
+
+// Provide mocks base method.
+func (m *MockClient) Provide(arg0 context.Context, arg1 string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Provide", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Provide indicates an expected call of Provide.
+func (mr *MockClientMockRecorder) Provide(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Provide", reflect.TypeOf((*MockClient)(nil).Provide), arg0, arg1, arg2)
+}
+
+// PubsubPeers mocks base method.
+func (m *MockClient) PubsubPeers(arg0 context.Context, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubsubPeers", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PubsubPeers indicates an expected call of PubsubPeers.
+func (mr *MockClientMockRecorder) PubsubPeers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubsubPeers", reflect.TypeOf((*MockClient)(nil).PubsubPeers), arg0, arg1)
+}
+
+// PubsubPublish mocks base method.
+func (m *MockClient) PubsubPublish(arg0 context.Context, arg1 string, arg2 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubsubPublish", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PubsubPublish indicates an expected call of PubsubPublish.
+func (mr *MockClientMockRecorder) PubsubPublish(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubsubPublish", reflect.TypeOf((*MockClient)(nil).PubsubPublish), arg0, arg1, arg2)
+}
+
+// PubsubSubscribe mocks base method.
+func (m *MockClient) PubsubSubscribe(arg0 context.Context, arg1 string, arg2 func(ipfs.PubsubMessage) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubsubSubscribe", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PubsubSubscribe indicates an expected call of PubsubSubscribe.
+func (mr *MockClientMockRecorder) PubsubSubscribe(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubsubSubscribe", reflect.TypeOf((*MockClient)(nil).PubsubSubscribe), arg0, arg1, arg2)
+}
+
+// PubsubTopics mocks base method.
+func (m *MockClient) PubsubTopics(arg0 context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PubsubTopics", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PubsubTopics indicates an expected call of PubsubTopics.
+func (mr *MockClientMockRecorder) PubsubTopics(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PubsubTopics", reflect.TypeOf((*MockClient)(nil).PubsubTopics), arg0)
+}
+
+// RemoveBlock mocks base method.
+func (m *MockClient) RemoveBlock(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveBlock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveBlock indicates an expected call of RemoveBlock.
+func (mr *MockClientMockRecorder) RemoveBlock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveBlock", reflect.TypeOf((*MockClient)(nil).RemoveBlock), arg0, arg1)
+}
+
+// RemovePeering mocks base method.
+func (m *MockClient) RemovePeering(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePeering", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePeering indicates an expected call of RemovePeering.
+func (mr *MockClientMockRecorder) RemovePeering(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePeering", reflect.TypeOf((*MockClient)(nil).RemovePeering), arg0, arg1)
+}
+
+// RemoveRemotePin mocks base method.
+func (m *MockClient) RemoveRemotePin(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRemotePin", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRemotePin indicates an expected call of RemoveRemotePin.
+func (mr *MockClientMockRecorder) RemoveRemotePin(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRemotePin", reflect.TypeOf((*MockClient)(nil).RemoveRemotePin), arg0, arg1, arg2)
+}
+
+// RemoveRemoteService mocks base method.
+func (m *MockClient) RemoveRemoteService(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRemoteService", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRemoteService indicates an expected call of RemoveRemoteService.
+func (mr *MockClientMockRecorder) RemoveRemoteService(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRemoteService", reflect.TypeOf((*MockClient)(nil).RemoveRemoteService), arg0, arg1)
+}
+
+// RepoStat mocks base method.
+func (m *MockClient) RepoStat(arg0 context.Context) (ipfs.RepoStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepoStat", arg0)
+	ret0, _ := ret[0].(ipfs.RepoStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepoStat indicates an expected call of RepoStat.
+func (mr *MockClientMockRecorder) RepoStat(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepoStat", reflect.TypeOf((*MockClient)(nil).RepoStat), arg0)
+}
+
+// ResolveName mocks base method.
+func (m *MockClient) ResolveName(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveName", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveName indicates an expected call of ResolveName.
+func (mr *MockClientMockRecorder) ResolveName(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveName", reflect.TypeOf((*MockClient)(nil).ResolveName), arg0, arg1)
+}
+
+// Self mocks base method.
+func (m *MockClient) Self(arg0 context.Context) (ipfs.NodeInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Self", arg0)
+	ret0, _ := ret[0].(ipfs.NodeInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Self indicates an expected call of Self.
+func (mr *MockClientMockRecorder) Self(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Self", reflect.TypeOf((*MockClient)(nil).Self), arg0)
+}
+
+// SwarmConnect mocks base method.
+func (m *MockClient) SwarmConnect(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwarmConnect", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SwarmConnect indicates an expected call of SwarmConnect.
+func (mr *MockClientMockRecorder) SwarmConnect(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwarmConnect", reflect.TypeOf((*MockClient)(nil).SwarmConnect), arg0, arg1)
+}
+
+// SwarmDisconnect mocks base method.
+func (m *MockClient) SwarmDisconnect(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwarmDisconnect", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SwarmDisconnect indicates an expected call of SwarmDisconnect.
+func (mr *MockClientMockRecorder) SwarmDisconnect(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwarmDisconnect", reflect.TypeOf((*MockClient)(nil).SwarmDisconnect), arg0, arg1)
+}
+
+// UpdatePin mocks base method.
+func (m *MockClient) UpdatePin(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePin", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePin indicates an expected call of UpdatePin.
+func (mr *MockClientMockRecorder) UpdatePin(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePin", reflect.TypeOf((*MockClient)(nil).UpdatePin), arg0, arg1, arg2)
+}
+
+// VerifyPins mocks base method.
+func (m *MockClient) VerifyPins(arg0 context.Context, arg1 func(ipfs.PinVerification) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPins", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyPins indicates an expected call of VerifyPins.
+func (mr *MockClientMockRecorder) VerifyPins(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPins", reflect.TypeOf((*MockClient)(nil).VerifyPins), arg0, arg1)
+}
+
+// VerifyRepo mocks base method.
+func (m *MockClient) VerifyRepo(arg0 context.Context, arg1 func(ipfs.BadBlock) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyRepo", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyRepo indicates an expected call of VerifyRepo.
+func (mr *MockClientMockRecorder) VerifyRepo(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRepo", reflect.TypeOf((*MockClient)(nil).VerifyRepo), arg0, arg1)
+}
+
+// Version mocks base method.
+func (m *MockClient) Version(arg0 context.Context) (ipfs.VersionInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version", arg0)
+	ret0, _ := ret[0].(ipfs.VersionInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockClientMockRecorder) Version(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockClient)(nil).Version), arg0)
+}
@@ -0,0 +1,244 @@
+package pinhealth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/zde37/Hive/internal/ipfs"
+)
+
+// fetchTimeout bounds how long a repair waits for a block from the network.
+const fetchTimeout = 30 * time.Second
+
+// ErrRunning is returned when a check is requested while another one is in progress.
+var ErrRunning = errors.New("pin verification already running")
+
+// Block is a missing or corrupt block of a pin.
+type Block struct {
+	Cid         string `json:"cid"`                    // the CID of the block.
+	Error       string `json:"error"`                  // why the block could not be read.
+	Corrupt     bool   `json:"corrupt"`                // whether the block is present but does not match its CID.
+	Repaired    bool   `json:"repaired"`               // whether the block was fetched again from the network.
+	RepairError string `json:"repair_error,omitempty"` // why the block could not be repaired.
+}
+
+// Pin is the health of a pin with missing or corrupt blocks.
+type Pin struct {
+	Cid       string  `json:"cid"`        // the CID of the pin.
+	BadBlocks []Block `json:"bad_blocks"` // the missing or corrupt blocks of the pin.
+}
+
+// Report is the result of a pin verification run.
+type Report struct {
+	StartedAt     time.Time       `json:"started_at"`      // when the run started.
+	FinishedAt    time.Time       `json:"finished_at"`     // when the run finished.
+	Repair        bool            `json:"repair"`          // whether bad blocks were repaired.
+	PinsChecked   int             `json:"pins_checked"`    // the number of recursive pins verified.
+	BlocksChecked int             `json:"blocks_checked"`  // the number of blocks in the repository that were hashed.
+	Unhealthy     []Pin           `json:"unhealthy"`       // the pins with missing or corrupt blocks.
+	CorruptBlocks []ipfs.BadBlock `json:"corrupt_blocks"`  // every corrupt block in the repository, pinned or not.
+	Error         string          `json:"error,omitempty"` // why the run failed.
+}
+
+// Checker verifies that pinned content is fully present and uncorrupted, and optionally repairs it by
+// fetching bad blocks again from the network.
+type Checker struct {
+	ipfs    ipfs.Client
+	running sync.Mutex // held while a check runs.
+
+	mu     sync.Mutex // guards latest.
+	latest *Report    // the report of the last finished check.
+}
+
+// NewChecker creates a new Checker for the pins of the given IPFS node.
+func NewChecker(client ipfs.Client) *Checker {
+	return &Checker{
+		ipfs: client,
+	}
+}
+
+// Run verifies every recursive pin and every block of the repository, repairing bad blocks if repair is set,
+// and records the report as the latest one. It returns ErrRunning without checking if another check is in
+// progress.
+func (c *Checker) Run(ctx context.Context, repair bool) (Report, error) {
+	if !c.running.TryLock() {
+		return Report{}, ErrRunning
+	}
+	defer c.running.Unlock()
+
+	return c.run(ctx, repair)
+}
+
+// RunAsync is Run in the background. It returns ErrRunning if another check is in progress.
+func (c *Checker) RunAsync(ctx context.Context, repair bool) error {
+	if !c.running.TryLock() {
+		return ErrRunning
+	}
+
+	go func() {
+		defer c.running.Unlock()
+
+		if _, err := c.run(ctx, repair); err != nil {
+			log.Printf("pinhealth: pin verification failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// run performs a check while the running lock is held and records its report.
+func (c *Checker) run(ctx context.Context, repair bool) (Report, error) {
+	report := Report{
+		StartedAt:     time.Now(),
+		Repair:        repair,
+		Unhealthy:     []Pin{},
+		CorruptBlocks: []ipfs.BadBlock{},
+	}
+	err := c.check(ctx, &report)
+	report.FinishedAt = time.Now()
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	c.mu.Lock()
+	c.latest = &report
+	c.mu.Unlock()
+
+	return report, err
+}
+
+// check fills in the report of a run.
+func (c *Checker) check(ctx context.Context, report *Report) error {
+	// hash every block first, pin/verify only detects missing blocks
+	corrupt := make(map[string]string)
+	blocks, err := c.ipfs.VerifyRepo(ctx, func(block ipfs.BadBlock) error {
+		corrupt[block.Cid] = block.Error
+		report.CorruptBlocks = append(report.CorruptBlocks, block)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.BlocksChecked = blocks
+
+	var pins []ipfs.PinVerification
+	err = c.ipfs.VerifyPins(ctx, func(pin ipfs.PinVerification) error {
+		pins = append(pins, pin)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.PinsChecked = len(pins)
+
+	for _, pin := range pins {
+		bad, err := c.badBlocks(ctx, pin, corrupt)
+		if err != nil {
+			return err
+		}
+		if len(bad) == 0 {
+			continue
+		}
+
+		if report.Repair {
+			for i := range bad {
+				c.repair(ctx, &bad[i])
+			}
+		}
+		report.Unhealthy = append(report.Unhealthy, Pin{Cid: pin.Cid, BadBlocks: bad})
+	}
+	return nil
+}
+
+// badBlocks returns the missing blocks of a pin and those of its blocks that are corrupt.
+func (c *Checker) badBlocks(ctx context.Context, pin ipfs.PinVerification, corrupt map[string]string) ([]Block, error) {
+	var bad []Block
+	for _, node := range pin.BadNodes {
+		bad = append(bad, Block{Cid: node.Cid, Error: node.Error})
+	}
+	if len(corrupt) == 0 {
+		return bad, nil
+	}
+
+	refs := []string{pin.Cid}
+	err := c.ipfs.ListRefs(ctx, pin.Cid, func(ref string) error {
+		refs = append(refs, ref)
+		return nil
+	})
+	if err != nil && pin.Ok {
+		return nil, err
+	}
+	for _, ref := range refs {
+		if reason, ok := corrupt[ref]; ok {
+			bad = append(bad, Block{Cid: ref, Error: reason, Corrupt: true})
+		}
+	}
+	return bad, nil
+}
+
+// repair fetches a bad block again from the network, after removing the local copy of a corrupt block.
+func (c *Checker) repair(ctx context.Context, block *Block) {
+	if block.Corrupt {
+		if err := c.ipfs.RemoveBlock(ctx, block.Cid); err != nil {
+			block.RepairError = err.Error()
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	if err := c.ipfs.FetchBlock(ctx, block.Cid); err != nil {
+		block.RepairError = err.Error()
+		return
+	}
+	block.Repaired = true
+}
+
+// Latest returns the report of the last finished check, or nil if no check has finished yet.
+func (c *Checker) Latest() *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latest
+}
+
+// Running reports whether a check is in progress.
+func (c *Checker) Running() bool {
+	if !c.running.TryLock() {
+		return true
+	}
+	c.running.Unlock()
+	return false
+}
+
+// Start runs a check every interval in the background until ctx is done, repairing bad blocks if repair is
+// set. A zero interval disables the background checks.
+func (c *Checker) Start(ctx context.Context, interval time.Duration, repair bool) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			report, err := c.Run(ctx, repair)
+			switch {
+			case errors.Is(err, ErrRunning):
+			case err != nil:
+				log.Printf("pinhealth: pin verification failed: %v", err)
+			case len(report.Unhealthy) > 0:
+				log.Printf("pinhealth: %d of %d pins have missing or corrupt blocks", len(report.Unhealthy), report.PinsChecked)
+			}
+		}
+	}()
+}
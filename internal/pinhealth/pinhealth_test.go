@@ -0,0 +1,155 @@
+package pinhealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+// verifyRepo returns a mock VerifyRepo implementation checking blocks blocks and reporting the corrupt ones.
+func verifyRepo(blocks int, corrupt ...ipfs.BadBlock) func(context.Context, func(ipfs.BadBlock) error) (int, error) {
+	return func(_ context.Context, fn func(ipfs.BadBlock) error) (int, error) {
+		for _, block := range corrupt {
+			if err := fn(block); err != nil {
+				return 0, err
+			}
+		}
+		return blocks, nil
+	}
+}
+
+// verifyPins returns a mock VerifyPins implementation reporting the given pins.
+func verifyPins(pins ...ipfs.PinVerification) func(context.Context, func(ipfs.PinVerification) error) error {
+	return func(_ context.Context, fn func(ipfs.PinVerification) error) error {
+		for _, pin := range pins {
+			if err := fn(pin); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// listRefs returns a mock ListRefs implementation listing the given refs.
+func listRefs(refs ...string) func(context.Context, string, func(string) error) error {
+	return func(_ context.Context, _ string, fn func(string) error) error {
+		for _, ref := range refs {
+			if err := fn(ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	healthy := ipfs.PinVerification{Cid: "bafyhealthy", Ok: true, BadNodes: []ipfs.BadBlock{}}
+	missing := ipfs.PinVerification{Cid: "bafymissing", Ok: false, BadNodes: []ipfs.BadBlock{{Cid: "bafyleaf", Error: "block was not found locally"}}}
+	corrupt := ipfs.BadBlock{Cid: "bafycorrupt", Error: "data in file did not match"}
+
+	tests := []struct {
+		name              string
+		repair            bool
+		setupMock         func(mockClient *mocked.MockClient)
+		expectedUnhealthy []Pin
+		expectedPins      int
+		wantErr           bool
+	}{
+		{
+			name: "All pins healthy",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().VerifyRepo(gomock.Any(), gomock.Any()).DoAndReturn(verifyRepo(10))
+				mockClient.EXPECT().VerifyPins(gomock.Any(), gomock.Any()).DoAndReturn(verifyPins(healthy))
+			},
+			expectedUnhealthy: []Pin{},
+			expectedPins:      1,
+		},
+		{
+			name: "Missing and corrupt blocks",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().VerifyRepo(gomock.Any(), gomock.Any()).DoAndReturn(verifyRepo(10, corrupt))
+				mockClient.EXPECT().VerifyPins(gomock.Any(), gomock.Any()).DoAndReturn(verifyPins(healthy, missing))
+				mockClient.EXPECT().ListRefs(gomock.Any(), "bafyhealthy", gomock.Any()).DoAndReturn(listRefs("bafycorrupt"))
+				mockClient.EXPECT().ListRefs(gomock.Any(), "bafymissing", gomock.Any()).Return(errors.New("block was not found locally"))
+			},
+			expectedUnhealthy: []Pin{
+				{Cid: "bafyhealthy", BadBlocks: []Block{{Cid: "bafycorrupt", Error: "data in file did not match", Corrupt: true}}},
+				{Cid: "bafymissing", BadBlocks: []Block{{Cid: "bafyleaf", Error: "block was not found locally"}}},
+			},
+			expectedPins: 2,
+		},
+		{
+			name:   "Repair",
+			repair: true,
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().VerifyRepo(gomock.Any(), gomock.Any()).DoAndReturn(verifyRepo(10, corrupt))
+				mockClient.EXPECT().VerifyPins(gomock.Any(), gomock.Any()).DoAndReturn(verifyPins(healthy, missing))
+				mockClient.EXPECT().ListRefs(gomock.Any(), "bafyhealthy", gomock.Any()).DoAndReturn(listRefs("bafycorrupt"))
+				mockClient.EXPECT().ListRefs(gomock.Any(), "bafymissing", gomock.Any()).Return(errors.New("block was not found locally"))
+				mockClient.EXPECT().RemoveBlock(gomock.Any(), "bafycorrupt").Return(errors.New("pinned: recursive"))
+				mockClient.EXPECT().FetchBlock(gomock.Any(), "bafyleaf").Return(nil)
+			},
+			expectedUnhealthy: []Pin{
+				{Cid: "bafyhealthy", BadBlocks: []Block{{Cid: "bafycorrupt", Error: "data in file did not match", Corrupt: true, RepairError: "pinned: recursive"}}},
+				{Cid: "bafymissing", BadBlocks: []Block{{Cid: "bafyleaf", Error: "block was not found locally", Repaired: true}}},
+			},
+			expectedPins: 2,
+		},
+		{
+			name: "Verification fails",
+			setupMock: func(mockClient *mocked.MockClient) {
+				mockClient.EXPECT().VerifyRepo(gomock.Any(), gomock.Any()).Return(0, errors.New("repo locked"))
+			},
+			expectedUnhealthy: []Pin{},
+			wantErr:           true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocked.NewMockClient(gomock.NewController(t))
+			tt.setupMock(mockClient)
+			checker := NewChecker(mockClient)
+			require.Nil(t, checker.Latest())
+
+			report, err := checker.Run(context.Background(), tt.repair)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantErr, report.Error != "")
+			require.Equal(t, tt.repair, report.Repair)
+			require.Equal(t, tt.expectedPins, report.PinsChecked)
+			require.Equal(t, tt.expectedUnhealthy, report.Unhealthy)
+			require.Equal(t, &report, checker.Latest())
+		})
+	}
+}
+
+func TestRunAsync(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	release := make(chan struct{})
+	mockClient.EXPECT().VerifyRepo(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, func(ipfs.BadBlock) error) (int, error) {
+			<-release
+			return 3, nil
+		})
+	mockClient.EXPECT().VerifyPins(gomock.Any(), gomock.Any()).Return(nil)
+
+	checker := NewChecker(mockClient)
+	require.NoError(t, checker.RunAsync(context.Background(), false))
+	require.True(t, checker.Running())
+	require.ErrorIs(t, checker.RunAsync(context.Background(), false), ErrRunning)
+
+	_, err := checker.Run(context.Background(), false)
+	require.ErrorIs(t, err, ErrRunning)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return !checker.Running() && checker.Latest() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 3, checker.Latest().BlocksChecked)
+}
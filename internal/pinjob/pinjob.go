@@ -0,0 +1,320 @@
+package pinjob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/store"
+)
+
+// bucket is the store bucket holding the jobs.
+const bucket = "pin_jobs"
+
+var (
+	// ErrNotFound is returned when a job does not exist.
+	ErrNotFound = errors.New("pin job not found")
+
+	// ErrFinished is returned when a job that has already finished is cancelled.
+	ErrFinished = errors.New("pin job already finished")
+
+	// ErrUnfinished is returned when a job that has not finished yet is deleted.
+	ErrUnfinished = errors.New("pin job not finished")
+
+	// errCancelled is the cause of the context of a job cancelled through Cancel.
+	errCancelled = errors.New("pin job cancelled")
+)
+
+// State is the state of a pin job.
+type State string
+
+const (
+	StateQueued    State = "queued"    // waiting for a free worker.
+	StatePinning   State = "pinning"   // fetching and pinning the blocks.
+	StatePinned    State = "pinned"    // pinned successfully.
+	StateFailed    State = "failed"    // the pin failed.
+	StateCancelled State = "cancelled" // cancelled before the pin finished.
+)
+
+// finished reports whether a job in the state will not change anymore.
+func (s State) finished() bool {
+	return s == StatePinned || s == StateFailed || s == StateCancelled
+}
+
+// Job is a request to pin a CID in the background.
+type Job struct {
+	ID        string    `json:"id"`              // the ID of the job.
+	Cid       string    `json:"cid"`             // the CID to pin.
+	Name      string    `json:"name"`            // the name of the pin.
+	State     State     `json:"state"`           // the state of the job.
+	Blocks    int       `json:"blocks"`          // the number of blocks fetched so far.
+	Error     string    `json:"error,omitempty"` // why the pin failed.
+	CreatedAt time.Time `json:"created_at"`      // when the job was submitted.
+	UpdatedAt time.Time `json:"updated_at"`      // when the job last changed.
+}
+
+// Manager runs pin jobs in the background with a bounded number of workers, persisting them in the store so
+// that unfinished jobs resume when Hive restarts.
+type Manager struct {
+	ipfs    ipfs.Client
+	store   *store.Store
+	workers chan struct{} // holds a token for every running pin.
+
+	mu    sync.Mutex      // guards ctx and tasks, and serializes job updates.
+	ctx   context.Context // the context of every job, done when the manager stops.
+	tasks map[string]task // the unfinished jobs running in the background.
+}
+
+// task is an unfinished job running in the background.
+type task struct {
+	cancel context.CancelCauseFunc // cancels the job.
+	done   chan struct{}           // closed when the job stops running.
+}
+
+// NewManager creates a new Manager running at most workers pins at a time.
+func NewManager(client ipfs.Client, store *store.Store, workers int) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Manager{
+		ipfs:    client,
+		store:   store,
+		workers: make(chan struct{}, workers),
+		ctx:     context.Background(),
+		tasks:   make(map[string]task),
+	}
+}
+
+// Start resumes the jobs that were unfinished when Hive stopped. Jobs run until they finish or ctx is done; a
+// job interrupted by ctx keeps its state so that it resumes on the next Start.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	jobs, err := m.List()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if !job.State.finished() {
+			m.run(job)
+		}
+	}
+	return nil
+}
+
+// Submit enqueues a job to pin the given CID under name.
+func (m *Manager) Submit(cid, name string) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	now := time.Now().UTC()
+	job := Job{
+		ID:        id,
+		Cid:       cid,
+		Name:      name,
+		State:     StateQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := m.store.Put(bucket, job.ID, job); err != nil {
+		return Job{}, err
+	}
+
+	m.run(job)
+	return job, nil
+}
+
+// Get returns the job with the given ID.
+func (m *Manager) Get(id string) (Job, error) {
+	var job Job
+	err := m.store.Get(bucket, id, &job)
+	if errors.Is(err, store.ErrNotFound) {
+		return Job{}, ErrNotFound
+	}
+	return job, err
+}
+
+// List returns every job, newest first.
+func (m *Manager) List() ([]Job, error) {
+	jobs := []Job{}
+	err := m.store.ForEach(bucket, func(_ string, value []byte) error {
+		var job Job
+		if err := json.Unmarshal(value, &job); err != nil {
+			return err
+		}
+		jobs = append(jobs, job)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// Cancel stops the job with the given ID. A job whose pin the node completed before it stopped is recorded as
+// pinned rather than cancelled. It returns ErrFinished if the job has already finished.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	t, ok := m.tasks[id]
+	m.mu.Unlock()
+
+	if ok {
+		t.cancel(errCancelled)
+		<-t.done
+	}
+	job, err := m.Get(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.State.finished() {
+		// the job finished before it could be cancelled
+		return job, ErrFinished
+	}
+
+	state := StateCancelled
+	if m.pinned(job.Cid) {
+		state = StatePinned
+	}
+	return m.update(id, func(job *Job) { job.State = state })
+}
+
+// Delete removes the finished job with the given ID. It returns ErrUnfinished if the job has not finished yet.
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.store.Update(func(tx *store.Tx) error {
+		var job Job
+		err := tx.Get(bucket, id, &job)
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !job.State.finished() {
+			return ErrUnfinished
+		}
+		return tx.Delete(bucket, id)
+	})
+}
+
+// pinned reports whether the node recursively pins the CID. Failed checks are logged and report false.
+func (m *Manager) pinned(cid string) bool {
+	m.mu.Lock()
+	ctx := m.ctx
+	m.mu.Unlock()
+
+	pins, err := m.ipfs.ListRecursivePins(ctx)
+	if err != nil {
+		log.Printf("pinjob: failed to check whether %s is pinned: %v", cid, err)
+		return false
+	}
+	_, ok := pins[cid]
+	return ok
+}
+
+// run starts the job in the background.
+func (m *Manager) run(job Job) {
+	m.mu.Lock()
+	ctx, cancel := context.WithCancelCause(m.ctx)
+	t := task{cancel: cancel, done: make(chan struct{})}
+	m.tasks[job.ID] = t
+	m.mu.Unlock()
+
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			delete(m.tasks, job.ID)
+			m.mu.Unlock()
+			cancel(nil)
+			close(t.done)
+		}()
+
+		select {
+		case m.workers <- struct{}{}:
+			defer func() { <-m.workers }()
+		case <-ctx.Done():
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if _, err := m.update(job.ID, func(job *Job) { job.State = StatePinning }); err != nil {
+			log.Printf("pinjob: failed to update job %s: %v", job.ID, err)
+			return
+		}
+
+		pinErr := m.ipfs.PinObjectProgress(ctx, job.Name, "/ipfs/"+job.Cid, func(blocks int) error {
+			_, err := m.update(job.ID, func(job *Job) { job.Blocks = blocks })
+			return err
+		})
+		if ctx.Err() != nil {
+			// cancelled jobs are marked by Cancel, interrupted ones resume on the next start
+			return
+		}
+
+		_, err := m.update(job.ID, func(job *Job) {
+			if pinErr != nil {
+				job.State = StateFailed
+				job.Error = pinErr.Error()
+				return
+			}
+			job.State = StatePinned
+		})
+		if err != nil {
+			log.Printf("pinjob: failed to update job %s: %v", job.ID, err)
+		}
+	}()
+}
+
+// update applies fn to the stored job with the given ID and returns the updated job. Finished jobs are not
+// changed.
+func (m *Manager) update(id string, fn func(job *Job)) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var job Job
+	err := m.store.Update(func(tx *store.Tx) error {
+		if err := tx.Get(bucket, id, &job); err != nil {
+			return err
+		}
+		if job.State.finished() {
+			return nil
+		}
+
+		fn(&job)
+		job.UpdatedAt = time.Now().UTC()
+		return tx.Put(bucket, id, job)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return Job{}, ErrNotFound
+	}
+	return job, err
+}
+
+// newID returns a random job ID.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,201 @@
+package pinjob
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"github.com/zde37/Hive/internal/store"
+	"go.uber.org/mock/gomock"
+)
+
+const testCid = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+
+func openTestStore(t *testing.T, path string) *store.Store {
+	s, err := store.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// waitState waits until the job with the given ID reaches state and returns it.
+func waitState(t *testing.T, m *Manager, id string, state State) Job {
+	var job Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(id)
+		require.NoError(t, err)
+		return job.State == state
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestSubmit(t *testing.T) {
+	tests := []struct {
+		name          string
+		pinErr        error
+		expectedState State
+		expectedError string
+	}{
+		{
+			name:          "Pinned",
+			expectedState: StatePinned,
+		},
+		{
+			name:          "Failed",
+			pinErr:        errors.New("context deadline exceeded"),
+			expectedState: StateFailed,
+			expectedError: "context deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocked.NewMockClient(gomock.NewController(t))
+			mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs", "/ipfs/"+testCid, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _ string, fn func(int) error) error {
+					for _, blocks := range []int{1, 3} {
+						if err := fn(blocks); err != nil {
+							return err
+						}
+					}
+					return tt.pinErr
+				})
+
+			m := NewManager(mockClient, openTestStore(t, filepath.Join(t.TempDir(), "hive.db")), 1)
+			require.NoError(t, m.Start(context.Background()))
+
+			job, err := m.Submit(testCid, "docs")
+			require.NoError(t, err)
+			require.Len(t, job.ID, 32)
+			require.Equal(t, StateQueued, job.State)
+
+			job = waitState(t, m, job.ID, tt.expectedState)
+			require.Equal(t, 3, job.Blocks)
+			require.Equal(t, tt.expectedError, job.Error)
+
+			_, err = m.Cancel(job.ID)
+			require.ErrorIs(t, err, ErrFinished)
+
+			jobs, err := m.List()
+			require.NoError(t, err)
+			require.Equal(t, []Job{job}, jobs)
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	started := make(chan struct{})
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "", "/ipfs/"+testCid, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ string, _ func(int) error) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	mockClient.EXPECT().ListRecursivePins(gomock.Any()).Return(map[string]string{}, nil).Times(2)
+
+	m := NewManager(mockClient, openTestStore(t, filepath.Join(t.TempDir(), "hive.db")), 1)
+	running, err := m.Submit(testCid, "")
+	require.NoError(t, err)
+	<-started
+
+	// the second job waits for the only worker
+	queued, err := m.Submit(testCid, "")
+	require.NoError(t, err)
+
+	job, err := m.Cancel(queued.ID)
+	require.NoError(t, err)
+	require.Equal(t, StateCancelled, job.State)
+
+	job, err = m.Cancel(running.ID)
+	require.NoError(t, err)
+	require.Equal(t, StateCancelled, job.State)
+	waitState(t, m, running.ID, StateCancelled)
+
+	_, err = m.Cancel("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = m.Get("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// only finished jobs are deleted
+	require.NoError(t, m.Delete(queued.ID))
+	_, err = m.Get(queued.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, m.Delete(queued.ID), ErrNotFound)
+}
+
+func TestCancelCompletedPin(t *testing.T) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	started := make(chan struct{})
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs", "/ipfs/"+testCid, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ string, _ func(int) error) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	// the node completed the pin although its request was cancelled
+	mockClient.EXPECT().ListRecursivePins(gomock.Any()).Return(map[string]string{testCid: "docs"}, nil)
+
+	m := NewManager(mockClient, openTestStore(t, filepath.Join(t.TempDir(), "hive.db")), 1)
+	job, err := m.Submit(testCid, "docs")
+	require.NoError(t, err)
+	<-started
+
+	job, err = m.Cancel(job.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatePinned, job.State)
+	job, err = m.Get(job.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatePinned, job.State)
+}
+
+func TestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hive.db")
+	s, err := store.Open(path)
+	require.NoError(t, err)
+
+	// stopping the manager interrupts the running pin
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	interrupted := make(chan struct{})
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs", "/ipfs/"+testCid, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ string, fn func(int) error) error {
+			require.NoError(t, fn(2))
+			<-ctx.Done()
+			close(interrupted)
+			return ctx.Err()
+		})
+
+	ctx, stop := context.WithCancel(context.Background())
+	m := NewManager(mockClient, s, 1)
+	require.NoError(t, m.Start(ctx))
+	job, err := m.Submit(testCid, "docs")
+	require.NoError(t, err)
+	waitState(t, m, job.ID, StatePinning)
+	require.Eventually(t, func() bool {
+		job, err := m.Get(job.ID)
+		return err == nil && job.Blocks == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stop()
+	<-interrupted
+	require.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.tasks) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, s.Close())
+
+	// the job is pinned after a restart
+	mockClient = mocked.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs", "/ipfs/"+testCid, gomock.Any()).Return(nil)
+
+	m = NewManager(mockClient, openTestStore(t, path), 1)
+	require.NoError(t, m.Start(context.Background()))
+	job = waitState(t, m, job.ID, StatePinned)
+	require.Equal(t, 2, job.Blocks)
+}
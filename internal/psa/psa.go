@@ -0,0 +1,442 @@
+package psa
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/metadata"
+	"github.com/zde37/Hive/internal/pinjob"
+	"github.com/zde37/Hive/internal/store"
+)
+
+const (
+	bucket         = "psa_pins"       // the store bucket holding the pin requests.
+	connectTimeout = 30 * time.Second // bounds how long the node tries to connect to the origins of a pin.
+)
+
+// ErrNotFound is returned when a pin request does not exist or belongs to another user.
+var ErrNotFound = errors.New("pin request not found")
+
+// Status is the status of a pin request, as defined by the Pinning Service API.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"  // waiting for the node to start pinning.
+	StatusPinning Status = "pinning" // the node is fetching the blocks.
+	StatusPinned  Status = "pinned"  // the content is pinned.
+	StatusFailed  Status = "failed"  // the pin failed or was cancelled.
+)
+
+// Statuses are the valid pin request statuses.
+var Statuses = []Status{StatusQueued, StatusPinning, StatusPinned, StatusFailed}
+
+// Pin is the object a pin request asks to pin.
+type Pin struct {
+	Cid     string            `json:"cid"`               // the CID to pin.
+	Name    string            `json:"name,omitempty"`    // an optional name for the pin.
+	Origins []string          `json:"origins,omitempty"` // the multiaddrs of peers known to provide the content.
+	Meta    map[string]string `json:"meta,omitempty"`    // optional metadata of the pin.
+}
+
+// PinStatus is the status of a pin request.
+type PinStatus struct {
+	RequestID string            `json:"requestid"`      // the ID of the pin request.
+	Status    Status            `json:"status"`         // the status of the pin.
+	Created   time.Time         `json:"created"`        // when the pin request was made.
+	Pin       Pin               `json:"pin"`            // the pinned object.
+	Delegates []string          `json:"delegates"`      // the multiaddrs of the node pinning the content.
+	Info      map[string]string `json:"info,omitempty"` // why the pin failed, if it did.
+}
+
+// Query filters the pin requests of a user. Zero fields do not filter.
+type Query struct {
+	Cids     []string          // the CIDs of the pins.
+	Name     string            // the name of the pins, compared as given by Match.
+	Match    string            // "exact" (the default), "iexact", "partial" or "ipartial".
+	Statuses []Status          // the statuses of the pins.
+	Before   time.Time         // pins requested before this time.
+	After    time.Time         // pins requested after this time.
+	Meta     map[string]string // metadata every pin must have.
+	Limit    int               // the maximum number of pins returned, every pin if it is zero.
+}
+
+// request is a stored pin request.
+type request struct {
+	RequestID string    `json:"requestid"`
+	User      string    `json:"user"`
+	JobID     string    `json:"job_id"`
+	Created   time.Time `json:"created"`
+	Pin       Pin       `json:"pin"`
+	Existing  bool      `json:"existing,omitempty"` // whether the CID was pinned outside the service when requested.
+}
+
+// Service implements the pins of the IPFS Pinning Service API on top of the node, pinning in the background
+// with pin jobs. Every pin request belongs to a user and is invisible to the other users.
+type Service struct {
+	ipfs  ipfs.Client
+	jobs  *pinjob.Manager
+	files *metadata.Index // the files uploaded through Hive, whose CIDs are never unpinned.
+
+	mu    sync.Mutex // serializes changes, so that shared CIDs are only unpinned by their last request.
+	store *store.Store
+}
+
+// NewService creates a new Service storing its pin requests in store and pinning with jobs. The CIDs of files
+// are never unpinned when their pin requests are removed.
+func NewService(client ipfs.Client, store *store.Store, jobs *pinjob.Manager, files *metadata.Index) *Service {
+	return &Service{
+		ipfs:  client,
+		jobs:  jobs,
+		files: files,
+		store: store,
+	}
+}
+
+// Add requests the pin of the given object for user.
+func (s *Service) Add(ctx context.Context, user string, pin Pin) (PinStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, err := s.add(ctx, user, pin)
+	if err != nil {
+		return PinStatus{}, err
+	}
+	return s.status(ctx, req), nil
+}
+
+// Get returns the status of the pin request of user with the given ID.
+func (s *Service) Get(ctx context.Context, user, requestID string) (PinStatus, error) {
+	req, err := s.get(user, requestID)
+	if err != nil {
+		return PinStatus{}, err
+	}
+	return s.status(ctx, req), nil
+}
+
+// List returns the number of pin requests of user matching the query and the most recent of them, up to the
+// query limit.
+func (s *Service) List(ctx context.Context, user string, query Query) (int, []PinStatus, error) {
+	var requests []request
+	err := s.store.ForEach(bucket, func(_ string, value []byte) error {
+		var req request
+		if err := json.Unmarshal(value, &req); err != nil {
+			return err
+		}
+		if req.User == user && query.matchPin(req) {
+			requests = append(requests, req)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Created.After(requests[j].Created)
+	})
+
+	delegates := s.delegates(ctx)
+	results := []PinStatus{}
+	for _, req := range requests {
+		status := s.statusWithDelegates(req, delegates)
+		if len(query.Statuses) > 0 && !slices.Contains(query.Statuses, status.Status) {
+			continue
+		}
+		results = append(results, status)
+	}
+
+	count := len(results)
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return count, results, nil
+}
+
+// Replace replaces the pin request of user with the given ID by a new request for pin. The object of the old
+// request is unpinned right away, unless it was pinned before it was requested or another request still pins it.
+func (s *Service) Replace(ctx context.Context, user, requestID string, pin Pin) (PinStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.get(user, requestID)
+	if err != nil {
+		return PinStatus{}, err
+	}
+
+	req, err := s.add(ctx, user, pin)
+	if err != nil {
+		return PinStatus{}, err
+	}
+	if err := s.remove(ctx, old); err != nil {
+		return PinStatus{}, err
+	}
+	return s.status(ctx, req), nil
+}
+
+// Remove removes the pin request of user with the given ID, cancelling it if it is not pinned yet and
+// unpinning its object unless it was pinned before it was requested or another request still pins it.
+func (s *Service) Remove(ctx context.Context, user, requestID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, err := s.get(user, requestID)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, req)
+}
+
+// add stores a new pin request and starts pinning its object. The lock must be held.
+func (s *Service) add(ctx context.Context, user string, pin Pin) (request, error) {
+	id, err := newID()
+	if err != nil {
+		return request{}, err
+	}
+	existing, err := s.pinnedOutside(ctx, pin.Cid)
+	if err != nil {
+		return request{}, err
+	}
+
+	job, err := s.jobs.Submit(pin.Cid, pin.Name)
+	if err != nil {
+		return request{}, err
+	}
+	if len(pin.Origins) > 0 {
+		go s.connect(pin.Origins)
+	}
+
+	req := request{
+		RequestID: id,
+		User:      user,
+		JobID:     job.ID,
+		Created:   time.Now().UTC(),
+		Pin:       pin,
+		Existing:  existing,
+	}
+	if err := s.store.Put(bucket, req.RequestID, req); err != nil {
+		return request{}, err
+	}
+	return req, nil
+}
+
+// get returns the pin request of user with the given ID.
+func (s *Service) get(user, requestID string) (request, error) {
+	var req request
+	err := s.store.Get(bucket, requestID, &req)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && req.User != user) {
+		return request{}, fmt.Errorf("%w: %s", ErrNotFound, requestID)
+	}
+	return req, err
+}
+
+// remove deletes a pin request, cancelling its job and unpinning its object if the service pinned it and
+// nothing else pins the same CID. The lock must be held.
+func (s *Service) remove(ctx context.Context, req request) error {
+	if err := s.store.Delete(bucket, req.RequestID); err != nil {
+		return err
+	}
+
+	job, err := s.jobs.Cancel(req.JobID)
+	if errors.Is(err, pinjob.ErrNotFound) {
+		return nil
+	}
+	if err != nil && !errors.Is(err, pinjob.ErrFinished) {
+		return err
+	}
+	// the job of the request would otherwise keep its CID referenced
+	if err := s.jobs.Delete(req.JobID); err != nil && !errors.Is(err, pinjob.ErrNotFound) {
+		return err
+	}
+	// the pins the service did not create are left alone
+	if job.State != pinjob.StatePinned || req.Existing {
+		return nil
+	}
+
+	shared, err := s.referenced(req.Pin.Cid)
+	if err != nil || shared {
+		return err
+	}
+	return s.ipfs.DeleteFile(ctx, "/ipfs/"+req.Pin.Cid)
+}
+
+// pinnedOutside reports whether the CID is pinned by something other than the service: by the node before the
+// first pin request of the CID, as the other requests of the CID recorded, or by the node now if there are none.
+// The lock must be held.
+func (s *Service) pinnedOutside(ctx context.Context, cid string) (bool, error) {
+	requested, existing := false, false
+	err := s.store.ForEach(bucket, func(_ string, value []byte) error {
+		var other request
+		if err := json.Unmarshal(value, &other); err != nil {
+			return err
+		}
+		if other.Pin.Cid == cid {
+			requested, existing = true, existing || other.Existing
+		}
+		return nil
+	})
+	if err != nil || requested {
+		return existing, err
+	}
+
+	pins, err := s.ipfs.ListRecursivePins(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := pins[cid]
+	return ok, nil
+}
+
+// referenced reports whether the CID is still pinned by another pin request, by a pin job or by a file uploaded
+// through Hive.
+func (s *Service) referenced(cid string) (bool, error) {
+	shared := false
+	err := s.store.ForEach(bucket, func(_ string, value []byte) error {
+		var other request
+		if err := json.Unmarshal(value, &other); err != nil {
+			return err
+		}
+		shared = shared || other.Pin.Cid == cid
+		return nil
+	})
+	if err != nil || shared {
+		return shared, err
+	}
+
+	jobs, err := s.jobs.List()
+	if err != nil {
+		return false, err
+	}
+	for _, job := range jobs {
+		if job.Cid == cid && job.State != pinjob.StateFailed && job.State != pinjob.StateCancelled {
+			return true, nil
+		}
+	}
+
+	if s.files == nil {
+		return false, nil
+	}
+	_, err = s.files.Get(cid)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// status returns the status of a pin request.
+func (s *Service) status(ctx context.Context, req request) PinStatus {
+	return s.statusWithDelegates(req, s.delegates(ctx))
+}
+
+// statusWithDelegates returns the status of a pin request, derived from the state of its job.
+func (s *Service) statusWithDelegates(req request, delegates []string) PinStatus {
+	status := PinStatus{
+		RequestID: req.RequestID,
+		Status:    StatusFailed,
+		Created:   req.Created,
+		Pin:       req.Pin,
+		Delegates: delegates,
+	}
+
+	job, err := s.jobs.Get(req.JobID)
+	if err != nil {
+		status.Info = map[string]string{"error": err.Error()}
+		return status
+	}
+	switch job.State {
+	case pinjob.StateQueued:
+		status.Status = StatusQueued
+	case pinjob.StatePinning:
+		status.Status = StatusPinning
+	case pinjob.StatePinned:
+		status.Status = StatusPinned
+	case pinjob.StateCancelled:
+		status.Info = map[string]string{"error": "pin cancelled"}
+	default:
+		status.Info = map[string]string{"error": job.Error}
+	}
+	return status
+}
+
+// delegates returns the multiaddrs of the node, which clients connect to in order to send the content.
+func (s *Service) delegates(ctx context.Context) []string {
+	self, err := s.ipfs.Self(ctx)
+	if err != nil {
+		log.Printf("psa: failed to get the node addresses: %v", err)
+		return []string{}
+	}
+
+	delegates := make([]string, 0, len(self.Addresses))
+	for _, addr := range self.Addresses {
+		if !strings.Contains(addr, "/p2p/") {
+			addr += "/p2p/" + self.ID
+		}
+		delegates = append(delegates, addr)
+	}
+	return delegates
+}
+
+// connect connects the node to the origins of a pin, so that the content is fetched from them without
+// waiting for a provider lookup.
+func (s *Service) connect(origins []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	for _, origin := range origins {
+		if err := s.ipfs.SwarmConnect(ctx, origin); err != nil {
+			log.Printf("psa: failed to connect to origin %s: %v", origin, err)
+		}
+	}
+}
+
+// matchPin reports whether a pin request matches every filter of the query but the statuses, which are
+// derived from the pin jobs.
+func (q Query) matchPin(req request) bool {
+	if len(q.Cids) > 0 && !slices.Contains(q.Cids, req.Pin.Cid) {
+		return false
+	}
+	if !q.Before.IsZero() && !req.Created.Before(q.Before) {
+		return false
+	}
+	if !q.After.IsZero() && !req.Created.After(q.After) {
+		return false
+	}
+	for key, value := range q.Meta {
+		if v, ok := req.Pin.Meta[key]; !ok || v != value {
+			return false
+		}
+	}
+	if q.Name == "" {
+		return true
+	}
+
+	switch q.Match {
+	case "iexact":
+		return strings.EqualFold(req.Pin.Name, q.Name)
+	case "partial":
+		return strings.Contains(req.Pin.Name, q.Name)
+	case "ipartial":
+		return strings.Contains(strings.ToLower(req.Pin.Name), strings.ToLower(q.Name))
+	default:
+		return req.Pin.Name == q.Name
+	}
+}
+
+// newID returns a random pin request ID.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate request id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
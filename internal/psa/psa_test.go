@@ -0,0 +1,302 @@
+package psa
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/metadata"
+	mocked "github.com/zde37/Hive/internal/mocks"
+	"github.com/zde37/Hive/internal/pinjob"
+	"github.com/zde37/Hive/internal/store"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testCid      = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	otherCid     = "bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy"
+	testSelfID   = "12D3KooWSelf"
+	testSelfAddr = "/ip4/127.0.0.1/tcp/4001"
+)
+
+func newTestService(t *testing.T) (*mocked.MockClient, *Service) {
+	mockClient, s, _ := newTestServiceWithFiles(t)
+	return mockClient, s
+}
+
+// newTestServiceWithFiles returns a Service and the index of the files whose CIDs it never unpins.
+func newTestServiceWithFiles(t *testing.T) (*mocked.MockClient, *Service, *metadata.Index) {
+	return newTestServiceWithPins(t, nil)
+}
+
+// newTestServiceWithPins returns a Service on a node whose recursive pins are pins, and the index of the files
+// whose CIDs it never unpins.
+func newTestServiceWithPins(t *testing.T, pins map[string]string) (*mocked.MockClient, *Service, *metadata.Index) {
+	mockClient := mocked.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().Self(gomock.Any()).Return(ipfs.NodeInfo{ID: testSelfID, Addresses: []string{testSelfAddr}}, nil).AnyTimes()
+	mockClient.EXPECT().ListRecursivePins(gomock.Any()).Return(pins, nil).AnyTimes()
+
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+
+	jobs := pinjob.NewManager(mockClient, st, 1)
+	files := metadata.NewIndex(st)
+	return mockClient, NewService(mockClient, st, jobs, files), files
+}
+
+// waitStatus waits until the pin request of user with the given ID reaches status and returns it.
+func waitStatus(t *testing.T, s *Service, user, requestID string, status Status) PinStatus {
+	var pin PinStatus
+	require.Eventually(t, func() bool {
+		var err error
+		pin, err = s.Get(context.Background(), user, requestID)
+		require.NoError(t, err)
+		return pin.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return pin
+}
+
+func TestAddAndRemove(t *testing.T) {
+	mockClient, s := newTestService(t)
+	ctx := context.Background()
+
+	connected := make(chan struct{})
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "docs", "/ipfs/"+testCid, gomock.Any()).Return(nil).Times(2)
+	mockClient.EXPECT().SwarmConnect(gomock.Any(), "/ip4/10.0.0.1/tcp/4001/p2p/12D3KooWOrigin").DoAndReturn(
+		func(context.Context, string) error {
+			close(connected)
+			return errors.New("dial backoff")
+		})
+
+	pin := Pin{Cid: testCid, Name: "docs", Origins: []string{"/ip4/10.0.0.1/tcp/4001/p2p/12D3KooWOrigin"}, Meta: map[string]string{"app": "hive"}}
+	first, err := s.Add(ctx, "alice", pin)
+	require.NoError(t, err)
+	require.Len(t, first.RequestID, 32)
+	require.Equal(t, pin, first.Pin)
+	require.Equal(t, []string{testSelfAddr + "/p2p/" + testSelfID}, first.Delegates)
+	<-connected
+
+	first = waitStatus(t, s, "alice", first.RequestID, StatusPinned)
+	require.Nil(t, first.Info)
+
+	// the pin requests of a user are invisible to the others
+	_, err = s.Get(ctx, "bob", first.RequestID)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, s.Remove(ctx, "bob", first.RequestID), ErrNotFound)
+
+	// a CID is only unpinned by its last request
+	second, err := s.Add(ctx, "alice", Pin{Cid: testCid, Name: "docs"})
+	require.NoError(t, err)
+	waitStatus(t, s, "alice", second.RequestID, StatusPinned)
+
+	require.NoError(t, s.Remove(ctx, "alice", first.RequestID))
+	mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+testCid).Return(nil)
+	require.NoError(t, s.Remove(ctx, "alice", second.RequestID))
+
+	_, err = s.Get(ctx, "alice", first.RequestID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRemoveKeepsReferencedPins(t *testing.T) {
+	mockClient, s, files := newTestServiceWithFiles(t)
+	ctx := context.Background()
+
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "", gomock.Any(), gomock.Any()).Return(nil).Times(4)
+
+	// a file uploaded through Hive keeps its pin
+	require.NoError(t, files.Put(metadata.File{Cid: testCid, Name: "upload"}))
+	pin, err := s.Add(ctx, "alice", Pin{Cid: testCid})
+	require.NoError(t, err)
+	waitStatus(t, s, "alice", pin.RequestID, StatusPinned)
+	require.NoError(t, s.Remove(ctx, "alice", pin.RequestID))
+
+	// so does a pin job
+	job, err := s.jobs.Submit(otherCid, "")
+	require.NoError(t, err)
+	pin, err = s.Add(ctx, "bob", Pin{Cid: otherCid})
+	require.NoError(t, err)
+	waitStatus(t, s, "bob", pin.RequestID, StatusPinned)
+	require.NoError(t, s.Remove(ctx, "bob", pin.RequestID))
+
+	// without the job, the last request unpins the CID
+	require.Eventually(t, func() bool { return s.jobs.Delete(job.ID) == nil }, 5*time.Second, 10*time.Millisecond)
+	mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+otherCid).Return(nil)
+	pin, err = s.Add(ctx, "bob", Pin{Cid: otherCid})
+	require.NoError(t, err)
+	waitStatus(t, s, "bob", pin.RequestID, StatusPinned)
+	require.NoError(t, s.Remove(ctx, "bob", pin.RequestID))
+}
+
+func TestRemoveKeepsExistingPins(t *testing.T) {
+	// the CID was pinned outside the service, by a CAR import or directly in Kubo
+	mockClient, s, _ := newTestServiceWithPins(t, map[string]string{testCid: "imported"})
+	ctx := context.Background()
+
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "", "/ipfs/"+testCid, gomock.Any()).Return(nil).Times(3)
+
+	// neither the request nor those made while it exists unpin it
+	first, err := s.Add(ctx, "alice", Pin{Cid: testCid})
+	require.NoError(t, err)
+	waitStatus(t, s, "alice", first.RequestID, StatusPinned)
+	second, err := s.Add(ctx, "bob", Pin{Cid: testCid})
+	require.NoError(t, err)
+	waitStatus(t, s, "bob", second.RequestID, StatusPinned)
+	require.NoError(t, s.Remove(ctx, "alice", first.RequestID))
+	require.NoError(t, s.Remove(ctx, "bob", second.RequestID))
+
+	// nor does replacing a request
+	third, err := s.Add(ctx, "alice", Pin{Cid: testCid})
+	require.NoError(t, err)
+	waitStatus(t, s, "alice", third.RequestID, StatusPinned)
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "", "/ipfs/"+otherCid, gomock.Any()).Return(nil)
+	replaced, err := s.Replace(ctx, "alice", third.RequestID, Pin{Cid: otherCid})
+	require.NoError(t, err)
+	waitStatus(t, s, "alice", replaced.RequestID, StatusPinned)
+}
+
+func TestRemoveCancels(t *testing.T) {
+	mockClient, s := newTestService(t)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "", "/ipfs/"+testCid, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ string, _ func(int) error) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	pin, err := s.Add(ctx, "alice", Pin{Cid: testCid})
+	require.NoError(t, err)
+	<-started
+	require.Equal(t, StatusPinning, waitStatus(t, s, "alice", pin.RequestID, StatusPinning).Status)
+
+	// the pin is cancelled, not unpinned
+	require.NoError(t, s.Remove(ctx, "alice", pin.RequestID))
+	_, err = s.Get(ctx, "alice", pin.RequestID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestReplace(t *testing.T) {
+	mockClient, s := newTestService(t)
+	ctx := context.Background()
+
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "v1", "/ipfs/"+testCid, gomock.Any()).Return(nil)
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), "v2", "/ipfs/"+otherCid, gomock.Any()).Return(errors.New("not found"))
+
+	old, err := s.Add(ctx, "alice", Pin{Cid: testCid, Name: "v1"})
+	require.NoError(t, err)
+	waitStatus(t, s, "alice", old.RequestID, StatusPinned)
+
+	_, err = s.Replace(ctx, "bob", old.RequestID, Pin{Cid: otherCid, Name: "v2"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	mockClient.EXPECT().DeleteFile(gomock.Any(), "/ipfs/"+testCid).Return(nil)
+	pin, err := s.Replace(ctx, "alice", old.RequestID, Pin{Cid: otherCid, Name: "v2"})
+	require.NoError(t, err)
+	require.NotEqual(t, old.RequestID, pin.RequestID)
+
+	failed := waitStatus(t, s, "alice", pin.RequestID, StatusFailed)
+	require.Equal(t, map[string]string{"error": "not found"}, failed.Info)
+
+	_, err = s.Get(ctx, "alice", old.RequestID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestList(t *testing.T) {
+	mockClient, s := newTestService(t)
+	ctx := context.Background()
+	mockClient.EXPECT().PinObjectProgress(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	add := func(user string, pin Pin) PinStatus {
+		status, err := s.Add(ctx, user, pin)
+		require.NoError(t, err)
+		waitStatus(t, s, user, status.RequestID, StatusPinned)
+		time.Sleep(time.Millisecond) // keep the creation times apart
+		return status
+	}
+	photos := add("alice", Pin{Cid: testCid, Name: "Photos", Meta: map[string]string{"album": "2024"}})
+	docs := add("alice", Pin{Cid: otherCid, Name: "docs"})
+	add("bob", Pin{Cid: otherCid, Name: "docs"})
+
+	ids := func(pins []PinStatus) []string {
+		var ids []string
+		for _, pin := range pins {
+			ids = append(ids, pin.RequestID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name          string
+		query         Query
+		expectedCount int
+		expectedIDs   []string
+	}{
+		{
+			name:          "Every pin, newest first",
+			expectedCount: 2,
+			expectedIDs:   []string{docs.RequestID, photos.RequestID},
+		},
+		{
+			name:          "Limit",
+			query:         Query{Limit: 1},
+			expectedCount: 2,
+			expectedIDs:   []string{docs.RequestID},
+		},
+		{
+			name:          "Cid",
+			query:         Query{Cids: []string{testCid}},
+			expectedCount: 1,
+			expectedIDs:   []string{photos.RequestID},
+		},
+		{
+			name:          "Exact name",
+			query:         Query{Name: "photos"},
+			expectedCount: 0,
+		},
+		{
+			name:          "Case insensitive partial name",
+			query:         Query{Name: "PHOT", Match: "ipartial"},
+			expectedCount: 1,
+			expectedIDs:   []string{photos.RequestID},
+		},
+		{
+			name:          "Status",
+			query:         Query{Statuses: []Status{StatusQueued, StatusFailed}},
+			expectedCount: 0,
+		},
+		{
+			name:          "Before",
+			query:         Query{Before: docs.Created},
+			expectedCount: 1,
+			expectedIDs:   []string{photos.RequestID},
+		},
+		{
+			name:          "After",
+			query:         Query{After: photos.Created},
+			expectedCount: 1,
+			expectedIDs:   []string{docs.RequestID},
+		},
+		{
+			name:          "Meta",
+			query:         Query{Meta: map[string]string{"album": "2024"}},
+			expectedCount: 1,
+			expectedIDs:   []string{photos.RequestID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, pins, err := s.List(ctx, "alice", tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedCount, count)
+			require.Equal(t, tt.expectedIDs, ids(pins))
+		})
+	}
+}
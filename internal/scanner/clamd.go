@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const chunkSize = 32 << 10 // the size of the chunks a file is streamed to clamd in, 32KB.
+
+// Clamd scans files with the INSTREAM command of a ClamAV daemon, which does not need access to them.
+type Clamd struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamd creates a new Clamd scanner for the daemon at addr, either "tcp://host:port" or "unix:///path/to/socket".
+// A bare "host:port" is a TCP address and a bare path a unix socket. A scan taking longer than timeout, or
+// DefaultTimeout if it is not positive, fails.
+func NewClamd(addr string, timeout time.Duration) (*Clamd, error) {
+	network, address, ok := strings.Cut(addr, "://")
+	if !ok {
+		network, address = "tcp", addr
+		if strings.HasPrefix(addr, "/") {
+			network = "unix"
+		}
+	}
+	if (network != "tcp" && network != "unix") || address == "" {
+		return nil, fmt.Errorf("invalid clamd address %q", addr)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Clamd{
+		network: network,
+		address: address,
+		timeout: timeout,
+	}, nil
+}
+
+// Scan streams the file at path to clamd.
+func (c *Clamd) Scan(ctx context.Context, path string) (Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	// clamd stops reading once the stream exceeds its StreamMaxLength and replies with an error, so the reply is
+	// read even if the stream fails
+	streamErr := stream(conn, f)
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && (err != io.EOF || reply == "") {
+		if streamErr != nil {
+			err = streamErr
+		}
+		return Result{}, fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+	return parseReply(strings.TrimSuffix(reply, "\x00"))
+}
+
+// stream sends the INSTREAM command with the content of r, in chunks prefixed by their length and ended by an
+// empty chunk.
+func stream(w io.Writer, r io.Reader) error {
+	if _, err := io.WriteString(w, "zINSTREAM\x00"); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := r.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, err := w.Write(buf[:4+n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// parseReply parses the reply of clamd to INSTREAM: "stream: OK", "stream: {signature} FOUND" or
+// "{message} ERROR".
+func parseReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(reply)
+	switch {
+	case reply == "stream: OK":
+		return Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		sig := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return Result{Infected: true, Signature: sig}, nil
+	default:
+		return Result{}, fmt.Errorf("%w: clamd: %s", ErrScanFailed, strings.TrimSuffix(reply, " ERROR"))
+	}
+}
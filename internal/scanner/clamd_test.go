@@ -0,0 +1,158 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// eicar is the EICAR test file, which antivirus software detects as malware.
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd serves the INSTREAM command of clamd on l, finding the EICAR test file and refusing streams longer
+// than maxLength as clamd does.
+func fakeClamd(t *testing.T, l net.Listener, maxLength int) {
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if cmd, err := r.ReadString(0); err != nil || cmd != "zINSTREAM\x00" {
+					io.WriteString(conn, "UNKNOWN COMMAND\x00")
+					return
+				}
+
+				var content []byte
+				for {
+					var size uint32
+					if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if len(content)+int(size) > maxLength {
+						io.WriteString(conn, "INSTREAM size limit exceeded. ERROR\x00")
+						return
+					}
+					chunk := make([]byte, size)
+					if _, err := io.ReadFull(r, chunk); err != nil {
+						return
+					}
+					content = append(content, chunk...)
+				}
+
+				if bytes.Contains(content, []byte(eicar)) {
+					io.WriteString(conn, "stream: Eicar-Signature FOUND\x00")
+					return
+				}
+				io.WriteString(conn, "stream: OK\x00")
+			}()
+		}
+	}()
+}
+
+// writeTestFile writes content to a file in a temporary directory, returning its path.
+func writeTestFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "upload")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestNewClamd(t *testing.T) {
+	tests := []struct {
+		addr    string
+		network string
+		address string
+	}{
+		{addr: "tcp://localhost:3310", network: "tcp", address: "localhost:3310"},
+		{addr: "unix:///run/clamav/clamd.ctl", network: "unix", address: "/run/clamav/clamd.ctl"},
+		{addr: "localhost:3310", network: "tcp", address: "localhost:3310"},
+		{addr: "/run/clamav/clamd.ctl", network: "unix", address: "/run/clamav/clamd.ctl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			c, err := NewClamd(tt.addr, 0)
+			require.NoError(t, err)
+			require.Equal(t, tt.network, c.network)
+			require.Equal(t, tt.address, c.address)
+			require.Equal(t, DefaultTimeout, c.timeout)
+		})
+	}
+
+	for _, addr := range []string{"udp://localhost:3310", "tcp://", ""} {
+		_, err := NewClamd(addr, time.Second)
+		require.Error(t, err, addr)
+	}
+}
+
+func TestClamd(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	fakeClamd(t, tcp, 1<<20)
+
+	socket := filepath.Join(t.TempDir(), "clamd.sock")
+	unix, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	fakeClamd(t, unix, 1<<20)
+
+	for _, addr := range []string{"tcp://" + tcp.Addr().String(), "unix://" + socket} {
+		t.Run(addr, func(t *testing.T) {
+			c, err := NewClamd(addr, time.Second)
+			require.NoError(t, err)
+
+			result, err := c.Scan(context.Background(), writeTestFile(t, "hello world"))
+			require.NoError(t, err)
+			require.Equal(t, Result{}, result)
+
+			result, err = c.Scan(context.Background(), writeTestFile(t, eicar))
+			require.NoError(t, err)
+			require.Equal(t, Result{Infected: true, Signature: "Eicar-Signature"}, result)
+
+			// streamed in several chunks
+			result, err = c.Scan(context.Background(), writeTestFile(t, strings.Repeat("a", 3*chunkSize)+eicar))
+			require.NoError(t, err)
+			require.True(t, result.Infected)
+		})
+	}
+}
+
+func TestClamdErrors(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	fakeClamd(t, l, 1024)
+
+	c, err := NewClamd(l.Addr().String(), time.Second)
+	require.NoError(t, err)
+
+	_, err = c.Scan(context.Background(), writeTestFile(t, strings.Repeat("a", 4*chunkSize)))
+	require.ErrorIs(t, err, ErrScanFailed)
+	require.Contains(t, err.Error(), "INSTREAM size limit exceeded")
+
+	_, err = c.Scan(context.Background(), filepath.Join(t.TempDir(), "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// nothing listens on a closed listener
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed.Close()
+	c, err = NewClamd(closed.Addr().String(), time.Second)
+	require.NoError(t, err)
+	_, err = c.Scan(context.Background(), writeTestFile(t, "hello world"))
+	require.ErrorIs(t, err, ErrScanFailed)
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/zde37/Hive/internal/store"
+)
+
+const bucket = "quarantine" // the store bucket holding the quarantined files, keyed by ID.
+
+// Record is a quarantined upload.
+type Record struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`                // the name it was uploaded under.
+	Filename      string    `json:"filename"`            // its original filename.
+	Size          int64     `json:"size"`                // its size in bytes.
+	Signature     string    `json:"signature,omitempty"` // what the scanner found in it.
+	Uploader      string    `json:"uploader,omitempty"`  // the Hive user who uploaded it, empty if anonymous.
+	RemoteAddr    string    `json:"remote_addr"`         // the address it was uploaded from.
+	QuarantinedAt time.Time `json:"quarantined_at"`
+}
+
+// Quarantine keeps the uploads rejected by the scanner out of IPFS, in a directory readable by its owner only,
+// and records them in the store.
+type Quarantine struct {
+	store *store.Store
+	dir   string
+}
+
+// NewQuarantine creates a new Quarantine keeping the files in dir, creating it if needed.
+func NewQuarantine(st *store.Store, dir string) (*Quarantine, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &Quarantine{
+		store: st,
+		dir:   dir,
+	}, nil
+}
+
+// Add copies the file at path into the quarantine and records it, returning the record with its ID and time.
+func (q *Quarantine) Add(path string, rec Record) (Record, error) {
+	id, err := newID()
+	if err != nil {
+		return Record{}, err
+	}
+	rec.ID, rec.QuarantinedAt = id, time.Now().UTC()
+
+	if err := copyFile(filepath.Join(q.dir, id), path); err != nil {
+		return Record{}, err
+	}
+	if err := q.store.Put(bucket, id, rec); err != nil {
+		return Record{}, err
+	}
+	return rec, nil
+}
+
+// List returns the quarantined files, newest first.
+func (q *Quarantine) List() ([]Record, error) {
+	records := []Record{}
+	err := q.store.ForEach(bucket, func(_ string, value []byte) error {
+		var rec Record
+		if err := json.Unmarshal(value, &rec); err != nil {
+			return err
+		}
+		records = append(records, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].QuarantinedAt.After(records[j].QuarantinedAt)
+	})
+	return records, nil
+}
+
+// Path returns the path of the quarantined file with the given ID.
+func (q *Quarantine) Path(id string) string {
+	return filepath.Join(q.dir, id)
+}
+
+// copyFile copies the file at src to dst, which only its owner may read.
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// newID returns a random quarantine ID.
+func newID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate quarantine id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a scan if no timeout is given.
+const DefaultTimeout = 2 * time.Minute
+
+var (
+	// ErrInfected is returned when an upload is rejected by the scanner.
+	ErrInfected = errors.New("file is infected")
+
+	// ErrScanFailed is returned when a file could not be scanned.
+	ErrScanFailed = errors.New("failed to scan file")
+)
+
+// Result is the verdict of a scan.
+type Result struct {
+	Infected  bool   `json:"infected"`            // whether malware was found.
+	Signature string `json:"signature,omitempty"` // the name of what was found, if the scanner reports it.
+}
+
+// Scanner scans files for malware.
+type Scanner interface {
+	// Scan scans the file at path. It only fails if the file could not be scanned, which is not a verdict.
+	Scan(ctx context.Context, path string) (Result, error)
+}
+
+// Exec scans files by running a command with the path of the file as its last argument. The command exits with
+// 0 if the file is clean and 1 if it is infected, as clamscan does, and any other exit status is a failure. The
+// last line of its output names what was found, such as "/tmp/upload: Eicar-Signature FOUND".
+type Exec struct {
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// NewExec creates a new Exec scanner running the command, its name followed by its arguments. A scan taking
+// longer than timeout, or DefaultTimeout if it is not positive, is killed and fails.
+func NewExec(command []string, timeout time.Duration) (*Exec, error) {
+	if len(command) == 0 {
+		return nil, errors.New("empty scanner command")
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Exec{
+		command: command[0],
+		args:    command[1:],
+		timeout: timeout,
+	}, nil
+}
+
+// Scan runs the command on the file at path.
+func (e *Exec) Scan(ctx context.Context, path string) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.command, append(append([]string{}, e.args...), path)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// children of a killed command may keep its output open, which is not waited for long
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return Result{}, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return Result{Infected: true, Signature: signature(stdout.String(), path)}, nil
+	default:
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return Result{}, fmt.Errorf("%w: %s: %v: %s", ErrScanFailed, e.command, err, msg)
+		}
+		return Result{}, fmt.Errorf("%w: %s: %v", ErrScanFailed, e.command, err)
+	}
+}
+
+// signature extracts the name of what was found from the last line of the output of a scan of path.
+func signature(output, path string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	line = strings.TrimPrefix(line, path+":")
+	return strings.TrimSpace(strings.TrimSuffix(line, " FOUND"))
+}
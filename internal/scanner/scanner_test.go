@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+// writeScript writes an executable shell script to a temporary directory, returning its path.
+func writeScript(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "scan.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700))
+	return path
+}
+
+func TestExec(t *testing.T) {
+	// a scanner finding the EICAR test file, in the output format of clamscan
+	script := writeScript(t, `if grep -q EICAR "$2"; then echo "$2: Eicar-Signature FOUND"; exit 1; fi; echo "$2: OK"`)
+	e, err := NewExec([]string{script, "--no-summary"}, time.Second)
+	require.NoError(t, err)
+
+	result, err := e.Scan(context.Background(), writeTestFile(t, "hello world"))
+	require.NoError(t, err)
+	require.Equal(t, Result{}, result)
+
+	result, err = e.Scan(context.Background(), writeTestFile(t, eicar))
+	require.NoError(t, err)
+	require.Equal(t, Result{Infected: true, Signature: "Eicar-Signature"}, result)
+
+	// any other exit status is a failure
+	e, err = NewExec([]string{writeScript(t, `echo "cannot read database" >&2; exit 2`)}, 0)
+	require.NoError(t, err)
+	_, err = e.Scan(context.Background(), writeTestFile(t, "hello world"))
+	require.ErrorIs(t, err, ErrScanFailed)
+	require.Contains(t, err.Error(), "cannot read database")
+
+	e, err = NewExec([]string{filepath.Join(t.TempDir(), "missing")}, 0)
+	require.NoError(t, err)
+	_, err = e.Scan(context.Background(), writeTestFile(t, "hello world"))
+	require.ErrorIs(t, err, ErrScanFailed)
+
+	// scans taking too long are killed
+	e, err = NewExec([]string{writeScript(t, "sleep 10")}, 100*time.Millisecond)
+	require.NoError(t, err)
+	_, err = e.Scan(context.Background(), writeTestFile(t, "hello world"))
+	require.ErrorIs(t, err, ErrScanFailed)
+
+	_, err = NewExec(nil, 0)
+	require.Error(t, err)
+}
+
+func TestQuarantine(t *testing.T) {
+	q, err := NewQuarantine(newTestStore(t), filepath.Join(t.TempDir(), "quarantine"))
+	require.NoError(t, err)
+
+	records, err := q.List()
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	first, err := q.Add(writeTestFile(t, eicar), Record{Name: "report", Filename: "report.pdf", Size: int64(len(eicar)), Signature: "Eicar-Signature", Uploader: "alice"})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.ID)
+	require.False(t, first.QuarantinedAt.IsZero())
+	second, err := q.Add(writeTestFile(t, eicar), Record{Name: "other"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(q.Path(first.ID))
+	require.NoError(t, err)
+	require.Equal(t, eicar, string(content))
+	info, err := os.Stat(q.Path(first.ID))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	records, err = q.List()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, second.ID, records[0].ID)
+	require.Equal(t, first, records[1])
+}
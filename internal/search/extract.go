@@ -0,0 +1,311 @@
+package search
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	maxTextSize = 1 << 20   // bounds the text indexed for a file, 1MB.
+	maxPDFSize  = 100 << 20 // bounds the PDFs whose text is extracted, 100MB.
+)
+
+// textTypes are the MIME types outside of text/* that hold text.
+var textTypes = map[string]bool{
+	"application/json":       true,
+	"application/xml":        true,
+	"application/javascript": true,
+	"application/x-yaml":     true,
+	"application/yaml":       true,
+	"application/toml":       true,
+	"application/sql":        true,
+	"application/x-sh":       true,
+}
+
+// sourceExtensions are the extensions of source code and configuration files, whose MIME type is often unknown.
+var sourceExtensions = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".ts": true, ".jsx": true, ".tsx": true, ".java": true, ".kt": true,
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".hpp": true, ".cs": true, ".rs": true, ".rb": true,
+	".php": true, ".swift": true, ".scala": true, ".sh": true, ".sql": true, ".md": true, ".rst": true,
+	".txt": true, ".csv": true, ".tsv": true, ".json": true, ".yaml": true, ".yml": true, ".toml": true,
+	".ini": true, ".cfg": true, ".xml": true, ".html": true, ".css": true, ".proto": true, ".sol": true,
+}
+
+// Extract returns the text of a file with the given filename and MIME type, and whether it is a text-like
+// document that can be indexed at all. Text files are read up to 1MB, and the text of PDFs is extracted from
+// their content streams, which works for the PDFs whose fonts use a standard encoding.
+func Extract(r io.Reader, filename, mimeType string) (string, bool, error) {
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	switch {
+	case mediaType == "application/pdf" || ext == ".pdf":
+		data, err := io.ReadAll(io.LimitReader(r, maxPDFSize))
+		if err != nil {
+			return "", false, err
+		}
+		return extractPDF(data), true, nil
+	case strings.HasPrefix(mediaType, "text/") || textTypes[mediaType] || sourceExtensions[ext]:
+		data, err := io.ReadAll(io.LimitReader(r, maxTextSize))
+		if err != nil {
+			return "", false, err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return "", false, nil // binary content behind a text name
+		}
+		return strings.ToValidUTF8(string(data), ""), true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+// extractPDF returns the text shown by the content streams of a PDF, compressed with FlateDecode or not.
+// Streams that are not content streams, such as images, show no text.
+func extractPDF(data []byte) string {
+	var text strings.Builder
+	for offset := 0; offset < len(data) && text.Len() < maxTextSize; {
+		i := bytes.Index(data[offset:], []byte("stream"))
+		if i < 0 {
+			break
+		}
+		start := offset + i + len("stream")
+		offset = start
+		if bytes.HasSuffix(data[:start], []byte("endstream")) {
+			continue
+		}
+
+		// the stream data starts after the end of line following the keyword
+		if bytes.HasPrefix(data[start:], []byte("\r\n")) {
+			start += 2
+		} else if bytes.HasPrefix(data[start:], []byte("\n")) {
+			start++
+		} else {
+			continue
+		}
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		stream := data[start : start+end]
+		offset = start + end + len("endstream")
+
+		dict := data[:start]
+		if obj := bytes.LastIndex(dict, []byte("obj")); obj >= 0 {
+			dict = dict[obj:]
+		}
+		switch {
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			zr, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			// truncated streams still hold text up to the corruption
+			stream, _ = io.ReadAll(io.LimitReader(zr, maxPDFSize))
+		case bytes.Contains(dict, []byte("/Filter")):
+			continue // other filters compress images
+		}
+		pdfContentText(stream, &text)
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// pdfContentText appends the text shown by the operators of a PDF content stream to text.
+func pdfContentText(content []byte, text *strings.Builder) {
+	var pending []string // the strings operands of the next operator
+	inText, depth := false, 0
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, n := pdfLiteral(content[i:])
+			pending = append(pending, s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return
+			}
+			pending = append(pending, pdfHex(content[i+1:i+end]))
+			i += end + 1
+		case c == '[':
+			depth++
+			i++
+		case c == ']':
+			depth--
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '/':
+			i++
+			for i < len(content) && pdfRegular(content[i]) {
+				i++
+			}
+		case pdfRegular(c):
+			start := i
+			for i < len(content) && pdfRegular(content[i]) {
+				i++
+			}
+			token := string(content[start:i])
+			if n, err := strconv.ParseFloat(token, 64); err == nil {
+				// a large negative kerning within a TJ array separates words
+				if depth > 0 && n < -200 {
+					pending = append(pending, " ")
+				}
+				continue
+			}
+
+			switch token {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				text.WriteByte('\n')
+			case "Td", "TD", "T*", "Tm":
+				if inText {
+					text.WriteByte(' ')
+				}
+			case "Tj", "TJ", "'", "\"":
+				if inText {
+					if token == "'" || token == "\"" {
+						text.WriteByte('\n')
+					}
+					for _, s := range pending {
+						text.WriteString(s)
+					}
+				}
+			}
+			pending = nil
+		default:
+			i++
+		}
+	}
+}
+
+// pdfRegular reports whether c is a regular character of PDF syntax, neither white-space nor a delimiter.
+func pdfRegular(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return true
+}
+
+// pdfLiteral decodes the PDF literal string at the start of data and returns it with the number of bytes it
+// spans.
+func pdfLiteral(data []byte) (string, int) {
+	var b []byte
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '(':
+			if depth > 0 {
+				b = append(b, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return pdfText(b), i + 1
+			}
+			b = append(b, c)
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch e := data[i]; e {
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'b', 'f':
+			case '\r', '\n': // a line continuation
+				if e == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+					i++
+				}
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				n := 0
+				for j := 0; j < 3 && i < len(data) && data[i] >= '0' && data[i] <= '7'; j++ {
+					n = n*8 + int(data[i]-'0')
+					i++
+				}
+				i--
+				b = append(b, byte(n))
+			default:
+				b = append(b, e)
+			}
+		default:
+			b = append(b, c)
+		}
+	}
+	return pdfText(b), len(data)
+}
+
+// pdfHex decodes the digits of a PDF hexadecimal string.
+func pdfHex(digits []byte) string {
+	var b []byte
+	var hi byte
+	odd := false
+	for _, c := range digits {
+		var v byte
+		switch {
+		case c >= '0' && c <= '9':
+			v = c - '0'
+		case c >= 'a' && c <= 'f':
+			v = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			v = c - 'A' + 10
+		default:
+			continue
+		}
+		if odd {
+			b = append(b, hi<<4|v)
+		} else {
+			hi = v
+		}
+		odd = !odd
+	}
+	if odd {
+		b = append(b, hi<<4)
+	}
+	return pdfText(b)
+}
+
+// pdfText decodes the bytes of a PDF string, UTF-16BE if it starts with a byte order mark and Latin-1
+// otherwise, dropping control characters.
+func pdfText(b []byte) string {
+	var runes []rune
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		units := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		runes = utf16.Decode(units)
+	} else {
+		runes = make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+	}
+
+	var s strings.Builder
+	for _, r := range runes {
+		if r == '\n' || r == '\t' || (!unicode.IsControl(r) && r != utf8.RuneError) {
+			s.WriteRune(r)
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newPDF returns a PDF whose single page shows content, compressed with FlateDecode if compress is set, along
+// with an image stream.
+func newPDF(t *testing.T, content string, compress bool) []byte {
+	stream, filter := []byte(content), ""
+	if compress {
+		var b bytes.Buffer
+		zw := zlib.NewWriter(&b)
+		_, err := zw.Write(stream)
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		stream, filter = b.Bytes(), " /Filter /FlateDecode"
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	fmt.Fprintf(&pdf, "4 0 obj\n<< /Length %d%s >>\nstream\n", len(stream), filter)
+	pdf.Write(stream)
+	pdf.WriteString("\nendstream\nendobj\n")
+	pdf.WriteString("5 0 obj\n<< /Type /XObject /Subtype /Image /Length 4 /Filter /DCTDecode >>\nstream\n\xff\xd8BT\nendstream\nendobj\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	content := `BT /F1 12 Tf 72 712 Td (Quarterly \(Q2\) revenue) Tj 0 -14 Td [(grew) -250 (by) -250 (12%)] TJ ET
+BT <FEFF00500072006F006A006500630074> Tj (\101lpha) ' ET`
+
+	tests := []struct {
+		name          string
+		filename      string
+		mimeType      string
+		data          []byte
+		expectedText  string
+		expectedIndex bool
+	}{
+		{
+			name:          "Text",
+			filename:      "notes.txt",
+			mimeType:      "text/plain; charset=utf-8",
+			data:          []byte("hello world"),
+			expectedText:  "hello world",
+			expectedIndex: true,
+		},
+		{
+			name:          "Source code without a known type",
+			filename:      "main.go",
+			mimeType:      "application/octet-stream",
+			data:          []byte("package main"),
+			expectedText:  "package main",
+			expectedIndex: true,
+		},
+		{
+			name:          "JSON",
+			filename:      "data",
+			mimeType:      "application/json",
+			data:          []byte(`{"key":"value"}`),
+			expectedText:  `{"key":"value"}`,
+			expectedIndex: true,
+		},
+		{
+			name:          "Binary behind a text name",
+			filename:      "notes.txt",
+			mimeType:      "text/plain",
+			data:          []byte("\x00\x01\x02"),
+			expectedIndex: false,
+		},
+		{
+			name:          "Image",
+			filename:      "photo.png",
+			mimeType:      "image/png",
+			data:          []byte("\x89PNG"),
+			expectedIndex: false,
+		},
+		{
+			name:          "Compressed PDF",
+			filename:      "report.pdf",
+			mimeType:      "application/pdf",
+			data:          newPDF(t, content, true),
+			expectedText:  "Quarterly (Q2) revenue grew by 12%\nProject\nAlpha",
+			expectedIndex: true,
+		},
+		{
+			name:          "Uncompressed PDF",
+			filename:      "report.pdf",
+			mimeType:      "application/pdf",
+			data:          newPDF(t, content, false),
+			expectedText:  "Quarterly (Q2) revenue grew by 12%\nProject\nAlpha",
+			expectedIndex: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, ok, err := Extract(bytes.NewReader(tt.data), tt.filename, tt.mimeType)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedIndex, ok)
+			require.Equal(t, tt.expectedText, text)
+		})
+	}
+}
+
+func TestExtractLimit(t *testing.T) {
+	text, ok, err := Extract(strings.NewReader(strings.Repeat("a", maxTextSize+10)), "big.txt", "text/plain")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, text, maxTextSize)
+}
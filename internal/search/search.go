@@ -0,0 +1,340 @@
+package search
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zde37/Hive/internal/store"
+)
+
+const (
+	docsBucket  = "search_docs"  // the store bucket holding the indexed documents, keyed by CID.
+	termsBucket = "search_terms" // the store bucket holding the postings of every term.
+	textBucket  = "search_text"  // the store bucket holding the text of the documents, for snippets.
+	statsBucket = "search_stats" // the store bucket holding the statistics of the index.
+	statsKey    = "stats"        // the key of the statistics in their bucket.
+
+	maxTermLength  = 64   // longer words, such as encoded data, are not indexed.
+	snippetContext = 80   // the bytes of text shown around the first match of a snippet.
+	bm25K1         = 1.2  // the term frequency saturation of the BM25 ranking.
+	bm25B          = 0.75 // how much the BM25 ranking favors short documents.
+)
+
+// Document is a file to index.
+type Document struct {
+	Cid      string // the root CID of the file.
+	Name     string // the name the file is pinned under.
+	Filename string // the original filename of the upload.
+	Owner    string // the Hive user who uploaded the file, empty for anonymous uploads.
+	Text     string // the text of the file.
+}
+
+// Result is a document matching a search.
+type Result struct {
+	Cid      string  `json:"cid"`      // the root CID of the file.
+	Name     string  `json:"name"`     // the name the file is pinned under.
+	Filename string  `json:"filename"` // the original filename of the upload.
+	Score    float64 `json:"score"`    // the BM25 relevance of the file, higher is better.
+	Snippet  string  `json:"snippet"`  // the text around the first match.
+}
+
+// document is a stored indexed document.
+type document struct {
+	Cid      string   `json:"cid"`
+	Name     string   `json:"name"`
+	Filename string   `json:"filename"`
+	Owners   []string `json:"owners"` // the users who uploaded the file, empty for anonymous uploads.
+	Length   int      `json:"length"` // the number of terms of the text.
+	Terms    []string `json:"terms"`  // the distinct terms of the text, whose postings hold the document.
+}
+
+// stats are the statistics of the index the ranking depends on.
+type stats struct {
+	Docs  int `json:"docs"`  // the number of indexed documents.
+	Terms int `json:"terms"` // the number of terms of every document.
+}
+
+// token is a term of a text and where it is in the text.
+type token struct {
+	term       string
+	start, end int
+}
+
+// Index is a persistent full-text inverted index of the uploaded text documents. A document is visible to
+// the users who uploaded it, and to everyone if it was uploaded anonymously.
+type Index struct {
+	store *store.Store
+}
+
+// NewIndex creates a new Index stored in store.
+func NewIndex(store *store.Store) *Index {
+	return &Index{
+		store: store,
+	}
+}
+
+// Add indexes a document. A CID that is already indexed has the same text, so adding it again only makes it
+// visible to its new owner.
+func (i *Index) Add(doc Document) error {
+	return i.store.Update(func(tx *store.Tx) error {
+		var existing document
+		err := tx.Get(docsBucket, doc.Cid, &existing)
+		if err == nil {
+			if !slices.Contains(existing.Owners, doc.Owner) {
+				existing.Owners = append(existing.Owners, doc.Owner)
+			}
+			return tx.Put(docsBucket, doc.Cid, existing)
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+
+		tokens := tokenize(doc.Text)
+		freqs := map[string]int{}
+		for _, t := range tokens {
+			freqs[t.term]++
+		}
+		indexed := document{
+			Cid:      doc.Cid,
+			Name:     doc.Name,
+			Filename: doc.Filename,
+			Owners:   []string{doc.Owner},
+			Length:   len(tokens),
+			Terms:    make([]string, 0, len(freqs)),
+		}
+		for term, freq := range freqs {
+			postings, err := getPostings(tx, term)
+			if err != nil {
+				return err
+			}
+			postings[doc.Cid] = freq
+			if err := tx.Put(termsBucket, term, postings); err != nil {
+				return err
+			}
+			indexed.Terms = append(indexed.Terms, term)
+		}
+		sort.Strings(indexed.Terms)
+
+		st, err := getStats(tx)
+		if err != nil {
+			return err
+		}
+		st.Docs++
+		st.Terms += indexed.Length
+		if err := tx.Put(statsBucket, statsKey, st); err != nil {
+			return err
+		}
+		if err := tx.Put(textBucket, doc.Cid, doc.Text); err != nil {
+			return err
+		}
+		return tx.Put(docsBucket, doc.Cid, indexed)
+	})
+}
+
+// Remove removes the document with the given CID from the index. Removing a CID that is not indexed is not an
+// error.
+func (i *Index) Remove(cid string) error {
+	return i.store.Update(func(tx *store.Tx) error {
+		var doc document
+		err := tx.Get(docsBucket, cid, &doc)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, term := range doc.Terms {
+			postings, err := getPostings(tx, term)
+			if err != nil {
+				return err
+			}
+			delete(postings, cid)
+			if len(postings) == 0 {
+				err = tx.Delete(termsBucket, term)
+			} else {
+				err = tx.Put(termsBucket, term, postings)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		st, err := getStats(tx)
+		if err != nil {
+			return err
+		}
+		st.Docs--
+		st.Terms -= doc.Length
+		if err := tx.Put(statsBucket, statsKey, st); err != nil {
+			return err
+		}
+		if err := tx.Delete(textBucket, cid); err != nil {
+			return err
+		}
+		return tx.Delete(docsBucket, cid)
+	})
+}
+
+// Search returns the documents visible to user that contain any word of the query, the most relevant first,
+// up to limit. An anonymous user has an empty name and only sees the anonymous uploads.
+func (i *Index) Search(query, user string, limit int) ([]Result, error) {
+	results := []Result{}
+	var terms []string
+	for _, t := range tokenize(query) {
+		if !slices.Contains(terms, t.term) {
+			terms = append(terms, t.term)
+		}
+	}
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	err := i.store.View(func(tx *store.Tx) error {
+		st, err := getStats(tx)
+		if err != nil || st.Docs == 0 {
+			return err
+		}
+		avgLength := float64(st.Terms) / float64(st.Docs)
+
+		docs := map[string]document{}
+		scores := map[string]float64{}
+		for _, term := range terms {
+			postings, err := getPostings(tx, term)
+			if err != nil {
+				return err
+			}
+			idf := math.Log(1 + (float64(st.Docs)-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+
+			for cid, freq := range postings {
+				doc, ok := docs[cid]
+				if !ok {
+					if err := tx.Get(docsBucket, cid, &doc); err != nil {
+						return err
+					}
+					docs[cid] = doc
+				}
+				if !visible(doc, user) {
+					continue
+				}
+				tf := float64(freq)
+				scores[cid] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.Length)/avgLength))
+			}
+		}
+
+		for cid, score := range scores {
+			doc := docs[cid]
+			results = append(results, Result{Cid: cid, Name: doc.Name, Filename: doc.Filename, Score: score})
+		}
+		sort.Slice(results, func(a, b int) bool {
+			if results[a].Score != results[b].Score {
+				return results[a].Score > results[b].Score
+			}
+			return results[a].Cid < results[b].Cid
+		})
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+
+		for n := range results {
+			var text string
+			if err := tx.Get(textBucket, results[n].Cid, &text); err != nil {
+				return err
+			}
+			results[n].Snippet = snippet(text, terms)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// visible reports whether a document is visible to user.
+func visible(doc document, user string) bool {
+	return slices.Contains(doc.Owners, "") || (user != "" && slices.Contains(doc.Owners, user))
+}
+
+// getPostings returns the number of times every document holding term holds it, keyed by CID.
+func getPostings(tx *store.Tx, term string) (map[string]int, error) {
+	postings := map[string]int{}
+	err := tx.Get(termsBucket, term, &postings)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	return postings, nil
+}
+
+// getStats returns the statistics of the index.
+func getStats(tx *store.Tx) (stats, error) {
+	var st stats
+	err := tx.Get(statsBucket, statsKey, &st)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return stats{}, err
+	}
+	return st, nil
+}
+
+// tokenize splits a text into lower case terms, the runs of letters and digits. Single characters and very long
+// runs are not terms.
+func tokenize(text string) []token {
+	var tokens []token
+	add := func(start, end int) {
+		term := strings.ToLower(text[start:end])
+		if utf8.RuneCountInString(term) >= 2 && len(term) <= maxTermLength {
+			tokens = append(tokens, token{term: term, start: start, end: end})
+		}
+	}
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			add(start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		add(start, len(text))
+	}
+	return tokens
+}
+
+// snippet returns the text around the first occurrence of any of the terms, on a single line.
+func snippet(text string, terms []string) string {
+	match := token{}
+	for _, t := range tokenize(text) {
+		if slices.Contains(terms, t.term) {
+			match = t
+			break
+		}
+	}
+
+	start, end := max(match.start-snippetContext, 0), min(match.end+snippetContext, len(text))
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	s := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(text) {
+		s += "…"
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/store"
+)
+
+func newTestIndex(t *testing.T) *Index {
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return NewIndex(st)
+}
+
+// cids returns the CIDs of the results.
+func cids(results []Result) []string {
+	cids := []string{}
+	for _, result := range results {
+		cids = append(cids, result.Cid)
+	}
+	return cids
+}
+
+func TestSearch(t *testing.T) {
+	i := newTestIndex(t)
+
+	require.NoError(t, i.Add(Document{Cid: "bafy1", Name: "budget", Filename: "budget.csv", Owner: "alice",
+		Text: "project,cost\nhive,1200\nswarm,300"}))
+	require.NoError(t, i.Add(Document{Cid: "bafy2", Name: "notes", Filename: "notes.md",
+		Text: "# Meeting notes\n\nThe Hive project budget was approved. Hive ships in June."}))
+	require.NoError(t, i.Add(Document{Cid: "bafy3", Name: "secret", Filename: "secret.txt", Owner: "bob",
+		Text: "Hive launch codes"}))
+
+	tests := []struct {
+		name     string
+		query    string
+		user     string
+		expected []string
+	}{
+		{name: "Anonymous users only see anonymous uploads", query: "hive", expected: []string{"bafy2"}},
+		{name: "Users see their own uploads", query: "hive", user: "alice", expected: []string{"bafy2", "bafy1"}},
+		{name: "Case insensitive", query: "HIVE", user: "bob", expected: []string{"bafy3", "bafy2"}},
+		{name: "Any word matches, rarer words rank higher", query: "approved project", user: "alice", expected: []string{"bafy2", "bafy1"}},
+		{name: "No match", query: "kubo", user: "alice", expected: []string{}},
+		{name: "No terms", query: "!", user: "alice", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := i.Search(tt.query, tt.user, 10)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cids(results))
+		})
+	}
+
+	results, err := i.Search("approved", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, []Result{{
+		Cid:      "bafy2",
+		Name:     "notes",
+		Filename: "notes.md",
+		Score:    results[0].Score,
+		Snippet:  "# Meeting notes The Hive project budget was approved. Hive ships in June.",
+	}}, results)
+	require.Positive(t, results[0].Score)
+
+	results, err = i.Search("hive", "alice", 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy2"}, cids(results))
+}
+
+func TestAddAndRemove(t *testing.T) {
+	i := newTestIndex(t)
+
+	require.NoError(t, i.Add(Document{Cid: "bafy1", Owner: "alice", Text: "shared dataset"}))
+	// uploading the same content again shares it with its new owner
+	require.NoError(t, i.Add(Document{Cid: "bafy1", Owner: "bob", Text: "shared dataset"}))
+	require.NoError(t, i.Add(Document{Cid: "bafy2", Owner: "bob", Text: "another dataset"}))
+
+	results, err := i.Search("dataset", "alice", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy1"}, cids(results))
+	results, err = i.Search("dataset", "bob", 10)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"bafy1", "bafy2"}, cids(results))
+
+	require.NoError(t, i.Remove("bafy1"))
+	require.NoError(t, i.Remove("bafy1"))
+	results, err = i.Search("dataset shared", "bob", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy2"}, cids(results))
+
+	require.NoError(t, i.Remove("bafy2"))
+	results, err = i.Search("dataset", "bob", 10)
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 20) + "the needle\n\tin   the " + strings.Repeat("haystack ", 20)
+
+	s := snippet(text, []string{"needle"})
+	require.True(t, strings.HasPrefix(s, "…"))
+	require.True(t, strings.HasSuffix(s, "…"))
+	require.Contains(t, s, "the needle in the haystack")
+
+	require.Equal(t, "short text", snippet("short text", []string{"short"}))
+	require.Equal(t, "héllo wörld", snippet("héllo wörld", []string{"wörld"}))
+}
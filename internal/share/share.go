@@ -0,0 +1,285 @@
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zde37/Hive/internal/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	bucket       = "shares"       // the store bucket holding the share links, keyed by ID.
+	secretBucket = "share_secret" // the store bucket holding the generated signing secret.
+	secretKey    = "secret"       // the key of the signing secret in its bucket.
+
+	signatureLength = 16 // the bytes of the HMAC-SHA256 of a token that are kept.
+)
+
+var (
+	// ErrNotFound is returned when a share link does not exist, was revoked or its token is not genuine.
+	ErrNotFound = errors.New("share link not found")
+
+	// ErrExpired is returned when a share link has expired.
+	ErrExpired = errors.New("share link expired")
+
+	// ErrExhausted is returned when a share link has been downloaded as many times as it allows.
+	ErrExhausted = errors.New("share link download limit reached")
+
+	// ErrPassword is returned when the password of a protected share link is missing or wrong.
+	ErrPassword = errors.New("invalid share link password")
+
+	// ErrInvalid is returned when the options of a new share link are invalid.
+	ErrInvalid = errors.New("invalid share link")
+)
+
+// Share is a link that lets anyone who has it download a file, until it expires, is downloaded as many times
+// as it allows or is revoked.
+type Share struct {
+	ID           string     `json:"id"`                      // the ID of the link.
+	Cid          string     `json:"cid"`                     // the CID of the shared file.
+	CreatedBy    string     `json:"created_by,omitempty"`    // the Hive user who created the link, empty if anonymous.
+	CreatedAt    time.Time  `json:"created_at"`              // when the link was created.
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`    // when the link expires, never if nil.
+	MaxDownloads int        `json:"max_downloads,omitempty"` // how many times the file can be downloaded, unlimited if zero.
+	Downloads    int        `json:"downloads"`               // how many times the file was downloaded.
+	Protected    bool       `json:"protected"`               // whether a password is needed to download the file.
+}
+
+// Options are the options of a new share link.
+type Options struct {
+	Cid          string        // the CID of the shared file.
+	CreatedBy    string        // the Hive user creating the link, empty if anonymous.
+	ExpiresIn    time.Duration // how long the link is valid for, forever if zero.
+	MaxDownloads int           // how many times the file can be downloaded, unlimited if zero.
+	Password     string        // the password needed to download the file, none if empty.
+}
+
+// stored is a share link as it is stored.
+type stored struct {
+	Share
+	PasswordHash []byte `json:"password_hash,omitempty"` // the bcrypt hash of the password.
+}
+
+// Manager issues share links and checks their tokens. A token holds the ID and expiry of its link, signed with
+// HMAC-SHA256, so that tokens cannot be guessed or extended.
+type Manager struct {
+	store  *store.Store
+	secret []byte
+}
+
+// NewManager creates a new Manager signing tokens with secret. If secret is empty, a random secret is generated
+// once and kept in store, so that tokens stay valid when Hive restarts.
+func NewManager(st *store.Store, secret []byte) (*Manager, error) {
+	if len(secret) == 0 {
+		err := st.Update(func(tx *store.Tx) error {
+			err := tx.Get(secretBucket, secretKey, &secret)
+			if !errors.Is(err, store.ErrNotFound) {
+				return err
+			}
+
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return fmt.Errorf("failed to generate share link secret: %w", err)
+			}
+			return tx.Put(secretBucket, secretKey, secret)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Manager{
+		store:  st,
+		secret: secret,
+	}, nil
+}
+
+// Create creates a share link and returns it with its token.
+func (m *Manager) Create(opts Options) (Share, string, error) {
+	if opts.ExpiresIn < 0 {
+		return Share{}, "", fmt.Errorf("%w: the expiry must not be negative", ErrInvalid)
+	}
+	if opts.MaxDownloads < 0 {
+		return Share{}, "", fmt.Errorf("%w: the download limit must not be negative", ErrInvalid)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Share{}, "", err
+	}
+	now := time.Now().UTC()
+	s := stored{Share: Share{
+		ID:           id,
+		Cid:          opts.Cid,
+		CreatedBy:    opts.CreatedBy,
+		CreatedAt:    now,
+		MaxDownloads: opts.MaxDownloads,
+		Protected:    opts.Password != "",
+	}}
+	if opts.ExpiresIn > 0 {
+		// tokens hold the expiry in seconds
+		expiresAt := now.Add(opts.ExpiresIn).Truncate(time.Second)
+		s.ExpiresAt = &expiresAt
+	}
+	if opts.Password != "" {
+		if s.PasswordHash, err = bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost); err != nil {
+			return Share{}, "", fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	}
+
+	if err := m.store.Put(bucket, s.ID, s); err != nil {
+		return Share{}, "", err
+	}
+	return s.Share, m.Token(s.Share), nil
+}
+
+// Get returns the share link with the given ID.
+func (m *Manager) Get(id string) (Share, error) {
+	s, err := m.get(id)
+	return s.Share, err
+}
+
+// List returns the share links of the given CID, or every share link if cid is empty, newest first.
+func (m *Manager) List(cid string) ([]Share, error) {
+	shares := []Share{}
+	err := m.store.ForEach(bucket, func(_ string, value []byte) error {
+		var s stored
+		if err := json.Unmarshal(value, &s); err != nil {
+			return err
+		}
+		if cid == "" || s.Cid == cid {
+			shares = append(shares, s.Share)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].CreatedAt.After(shares[j].CreatedAt)
+	})
+	return shares, nil
+}
+
+// Revoke revokes the share link with the given ID, whose token stops working.
+func (m *Manager) Revoke(id string) error {
+	return m.store.Update(func(tx *store.Tx) error {
+		var s stored
+		if err := tx.Get(bucket, id, &s); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("%w: %s", ErrNotFound, id)
+			}
+			return err
+		}
+		return tx.Delete(bucket, id)
+	})
+}
+
+// Token returns the token of a share link.
+func (m *Manager) Token(s Share) string {
+	var expiry int64
+	if s.ExpiresAt != nil {
+		expiry = s.ExpiresAt.Unix()
+	}
+	payload := s.ID + "." + strconv.FormatInt(expiry, 10)
+	return payload + "." + m.sign(payload)
+}
+
+// Resolve returns the share link of a token, which must be genuine and not expired. It does not check the
+// password or the download limit of the link.
+func (m *Manager) Resolve(token string) (Share, error) {
+	s, err := m.resolve(token)
+	return s.Share, err
+}
+
+// Authorize returns the share link of a token if the file can be downloaded with it and the given password.
+func (m *Manager) Authorize(token, password string) (Share, error) {
+	s, err := m.resolve(token)
+	if err != nil {
+		return Share{}, err
+	}
+	if s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads {
+		return Share{}, ErrExhausted
+	}
+	if s.Protected && bcrypt.CompareHashAndPassword(s.PasswordHash, []byte(password)) != nil {
+		return Share{}, ErrPassword
+	}
+	return s.Share, nil
+}
+
+// Consume counts a download of the share link with the given ID, failing with ErrExhausted if it has been
+// downloaded as many times as it allows.
+func (m *Manager) Consume(id string) (Share, error) {
+	var s stored
+	err := m.store.Update(func(tx *store.Tx) error {
+		if err := tx.Get(bucket, id, &s); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads {
+			return ErrExhausted
+		}
+		s.Downloads++
+		return tx.Put(bucket, id, s)
+	})
+	if err != nil {
+		return Share{}, err
+	}
+	return s.Share, nil
+}
+
+// resolve verifies a token and returns its stored share link.
+func (m *Manager) resolve(token string) (stored, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 || !hmac.Equal([]byte(token[i+1:]), []byte(m.sign(token[:i]))) {
+		return stored{}, ErrNotFound
+	}
+	id, expiry, _ := strings.Cut(token[:i], ".")
+	seconds, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return stored{}, ErrNotFound
+	}
+	if seconds > 0 && !time.Now().Before(time.Unix(seconds, 0)) {
+		return stored{}, ErrExpired
+	}
+	return m.get(id)
+}
+
+// get returns the stored share link with the given ID.
+func (m *Manager) get(id string) (stored, error) {
+	var s stored
+	err := m.store.Get(bucket, id, &s)
+	if errors.Is(err, store.ErrNotFound) {
+		return stored{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return s, err
+}
+
+// sign returns the signature of the payload of a token.
+func (m *Manager) sign(payload string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLength])
+}
+
+// newID returns a random share link ID.
+func newID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share link id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
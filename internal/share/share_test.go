@@ -0,0 +1,134 @@
+package share
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func newTestManager(t *testing.T) *Manager {
+	m, err := NewManager(newTestStore(t), []byte("test-secret"))
+	require.NoError(t, err)
+	return m
+}
+
+func TestCreateAndAuthorize(t *testing.T) {
+	m := newTestManager(t)
+
+	s, token, err := m.Create(Options{Cid: "bafy1", CreatedBy: "alice", ExpiresIn: time.Hour, MaxDownloads: 2, Password: "hunter2"})
+	require.NoError(t, err)
+	require.Equal(t, "bafy1", s.Cid)
+	require.Equal(t, "alice", s.CreatedBy)
+	require.True(t, s.Protected)
+	require.NotNil(t, s.ExpiresAt)
+	require.WithinDuration(t, time.Now().Add(time.Hour), *s.ExpiresAt, 2*time.Second)
+	require.Equal(t, token, m.Token(s))
+
+	resolved, err := m.Resolve(token)
+	require.NoError(t, err)
+	require.Equal(t, s.ID, resolved.ID)
+
+	_, err = m.Authorize(token, "")
+	require.ErrorIs(t, err, ErrPassword)
+	_, err = m.Authorize(token, "hunter3")
+	require.ErrorIs(t, err, ErrPassword)
+
+	for downloads := 1; downloads <= 2; downloads++ {
+		_, err = m.Authorize(token, "hunter2")
+		require.NoError(t, err)
+		s, err = m.Consume(s.ID)
+		require.NoError(t, err)
+		require.Equal(t, downloads, s.Downloads)
+	}
+	_, err = m.Authorize(token, "hunter2")
+	require.ErrorIs(t, err, ErrExhausted)
+	_, err = m.Consume(s.ID)
+	require.ErrorIs(t, err, ErrExhausted)
+
+	// the password hash is never part of a share link
+	shares, err := m.List("bafy1")
+	require.NoError(t, err)
+	require.Equal(t, []Share{s}, shares)
+}
+
+func TestTokens(t *testing.T) {
+	m := newTestManager(t)
+
+	s, token, err := m.Create(Options{Cid: "bafy1"})
+	require.NoError(t, err)
+	require.Nil(t, s.ExpiresAt)
+	_, err = m.Authorize(token, "anything")
+	require.NoError(t, err)
+
+	id, rest, _ := strings.Cut(token, ".")
+	_, signature, _ := strings.Cut(rest, ".")
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Empty", token: ""},
+		{name: "Garbage", token: "nonsense"},
+		{name: "Forged signature", token: id + ".0.AAAAAAAAAAAAAAAAAAAAAA"},
+		{name: "Extended expiry", token: id + ".1.0." + signature},
+		{name: "Another secret", token: func() string {
+			other, err := NewManager(newTestStore(t), []byte("other-secret"))
+			require.NoError(t, err)
+			return other.Token(s)
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.Resolve(tt.token)
+			require.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+
+	require.NoError(t, m.Revoke(s.ID))
+	_, err = m.Resolve(token)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, m.Revoke(s.ID), ErrNotFound)
+}
+
+func TestExpiry(t *testing.T) {
+	m := newTestManager(t)
+
+	s, _, err := m.Create(Options{Cid: "bafy1", ExpiresIn: time.Hour})
+	require.NoError(t, err)
+	expired := time.Now().Add(-time.Minute)
+	s.ExpiresAt = &expired
+
+	_, err = m.Resolve(m.Token(s))
+	require.ErrorIs(t, err, ErrExpired)
+
+	_, _, err = m.Create(Options{Cid: "bafy1", ExpiresIn: -time.Hour})
+	require.ErrorIs(t, err, ErrInvalid)
+	_, _, err = m.Create(Options{Cid: "bafy1", MaxDownloads: -1})
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestGeneratedSecret(t *testing.T) {
+	st := newTestStore(t)
+
+	m, err := NewManager(st, nil)
+	require.NoError(t, err)
+	_, token, err := m.Create(Options{Cid: "bafy1"})
+	require.NoError(t, err)
+
+	// the generated secret is kept, so tokens stay valid across restarts
+	m, err = NewManager(st, nil)
+	require.NoError(t, err)
+	_, err = m.Resolve(token)
+	require.NoError(t, err)
+}
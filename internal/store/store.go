@@ -0,0 +1,148 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNotFound is returned when a key does not exist in a bucket.
+var ErrNotFound = errors.New("not found")
+
+// Store is Hive's own persistent key/value store, an embedded bbolt database holding JSON encoded values in
+// named buckets.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the database file at the given path, creating it if it does not exist.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put stores the JSON encoding of value under key in bucket.
+func (s *Store) Put(bucket, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// Get decodes the value stored under key in bucket into value. It returns ErrNotFound if there is none.
+func (s *Store) Get(bucket, key string, value any) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return ErrNotFound
+		}
+
+		data := b.Get([]byte(key))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, value)
+	})
+}
+
+// Delete removes the value stored under key in bucket. Deleting a key that does not exist is not an error.
+func (s *Store) Delete(bucket, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// ForEach calls fn with every key of bucket, in byte order, and its JSON encoded value. The value is only
+// valid during the call.
+func (s *Store) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
+
+// Update runs fn in a read-write transaction, so that several values can be read and changed atomically. The
+// transaction is rolled back if fn returns an error.
+func (s *Store) Update(fn func(tx *Tx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&Tx{tx: tx})
+	})
+}
+
+// View runs fn in a read-only transaction, so that several values can be read consistently without blocking
+// the writers. Changes made by fn fail.
+func (s *Store) View(fn func(tx *Tx) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(&Tx{tx: tx})
+	})
+}
+
+// Tx is a transaction of the store, read-write in Update and read-only in View.
+type Tx struct {
+	tx *bolt.Tx
+}
+
+// Put stores the JSON encoding of value under key in bucket.
+func (t *Tx) Put(bucket, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	b, err := t.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
+
+// Get decodes the value stored under key in bucket into value. It returns ErrNotFound if there is none.
+func (t *Tx) Get(bucket, key string, value any) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return ErrNotFound
+	}
+
+	data := b.Get([]byte(key))
+	if data == nil {
+		return ErrNotFound
+	}
+	return json.Unmarshal(data, value)
+}
+
+// Delete removes the value stored under key in bucket. Deleting a key that does not exist is not an error.
+func (t *Tx) Delete(bucket, key string) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.Delete([]byte(key))
+}
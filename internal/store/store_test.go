@@ -0,0 +1,131 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+func openTestStore(t *testing.T) *Store {
+	s, err := Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestPutGetDelete(t *testing.T) {
+	s := openTestStore(t)
+
+	var got record
+	require.ErrorIs(t, s.Get("files", "a", &got), ErrNotFound)
+
+	require.NoError(t, s.Put("files", "a", record{Name: "a.txt", Size: 1}))
+	require.NoError(t, s.Get("files", "a", &got))
+	require.Equal(t, record{Name: "a.txt", Size: 1}, got)
+	require.ErrorIs(t, s.Get("files", "b", &got), ErrNotFound)
+
+	require.NoError(t, s.Delete("files", "a"))
+	require.ErrorIs(t, s.Get("files", "a", &got), ErrNotFound)
+	require.NoError(t, s.Delete("files", "a"))
+	require.NoError(t, s.Delete("missing", "a"))
+}
+
+func TestForEach(t *testing.T) {
+	s := openTestStore(t)
+	require.NoError(t, s.ForEach("files", func(string, []byte) error {
+		t.Fatal("empty bucket must not be iterated")
+		return nil
+	}))
+
+	require.NoError(t, s.Put("files", "b", record{Name: "b"}))
+	require.NoError(t, s.Put("files", "a", record{Name: "a"}))
+
+	var names []string
+	err := s.ForEach("files", func(key string, value []byte) error {
+		var r record
+		if err := json.Unmarshal(value, &r); err != nil {
+			return err
+		}
+		names = append(names, key+"="+r.Name)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a=a", "b=b"}, names)
+}
+
+func TestUpdate(t *testing.T) {
+	s := openTestStore(t)
+	require.NoError(t, s.Put("files", "a", record{Size: 1}))
+
+	err := s.Update(func(tx *Tx) error {
+		var r record
+		if err := tx.Get("files", "a", &r); err != nil {
+			return err
+		}
+		r.Size++
+		if err := tx.Put("files", "a", r); err != nil {
+			return err
+		}
+		return tx.Delete("files", "b")
+	})
+	require.NoError(t, err)
+
+	var got record
+	require.NoError(t, s.Get("files", "a", &got))
+	require.Equal(t, 2, got.Size)
+
+	// a failed transaction leaves the store unchanged
+	err = s.Update(func(tx *Tx) error {
+		if err := tx.Put("files", "a", record{Size: 10}); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	require.Error(t, err)
+	require.NoError(t, s.Get("files", "a", &got))
+	require.Equal(t, 2, got.Size)
+}
+
+func TestView(t *testing.T) {
+	s := openTestStore(t)
+	require.NoError(t, s.Put("files", "a", record{Size: 1}))
+
+	err := s.View(func(tx *Tx) error {
+		var r record
+		if err := tx.Get("files", "a", &r); err != nil {
+			return err
+		}
+		require.Equal(t, 1, r.Size)
+		require.ErrorIs(t, tx.Get("files", "b", &r), ErrNotFound)
+
+		// a read-only transaction cannot change the store
+		require.Error(t, tx.Put("files", "a", record{Size: 2}))
+		require.Error(t, tx.Delete("files", "a"))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hive.db")
+	s, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Put("files", "a", record{Name: "a"}))
+	require.NoError(t, s.Close())
+
+	s, err = Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	var got record
+	require.NoError(t, s.Get("files", "a", &got))
+	require.Equal(t, "a", got.Name)
+}
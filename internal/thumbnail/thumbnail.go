@@ -0,0 +1,184 @@
+package thumbnail
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+)
+
+const (
+	DefaultWidth = 128 // the width of a thumbnail if none is asked for.
+
+	maxImageSize   = 50 << 20 // bounds the images thumbnails are generated for, 50MB.
+	maxImagePixels = 50e6     // bounds the decoded size of an image, against decompression bombs.
+	jpegQuality    = 80
+)
+
+// Widths are the widths in pixels thumbnails are generated at, in increasing order.
+var Widths = []int{64, 128, 256}
+
+// supportedTypes are the MIME types of the images thumbnails are generated for.
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+var (
+	// ErrNotFound is returned when the thumbnails of a CID have not been generated.
+	ErrNotFound = errors.New("thumbnail not found")
+
+	// ErrUnsupported is returned when thumbnails cannot be generated for a file, which is not a JPEG, PNG,
+	// GIF or WebP image or is too large.
+	ErrUnsupported = errors.New("unsupported image")
+)
+
+// Supported reports whether thumbnails are generated for files of the given MIME type.
+func Supported(mimeType string) bool {
+	return supportedTypes[mimeType]
+}
+
+// Cache generates the thumbnails of images and keeps them on disk, in a directory per CID. The content of a CID
+// never changes, so its thumbnails never expire.
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a new Cache that keeps the thumbnails in dir, creating it if needed.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Cache{
+		dir: dir,
+	}, nil
+}
+
+// Generate decodes the image with the given CID from r and caches its thumbnails at every width of Widths.
+// Images narrower than a width are not scaled up. It returns ErrUnsupported for anything but a JPEG, PNG, GIF or
+// WebP image of at most 50MB and 50 megapixels; only the first frame of animated images is kept.
+func (c *Cache) Generate(cid string, r io.Reader) error {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	if mimeType := http.DetectContentType(head); !Supported(mimeType) {
+		return fmt.Errorf("%w: %s", ErrUnsupported, mimeType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(br, maxImageSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxImageSize {
+		return fmt.Errorf("%w: larger than %d bytes", ErrUnsupported, maxImageSize)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return fmt.Errorf("%w: %dx%d pixels", ErrUnsupported, config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+
+	dir, err := c.cidDir(cid)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, width := range Widths {
+		if err := writeThumbnail(filepath.Join(dir, thumbnailName(width)), scale(img, width)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open opens the cached thumbnail of the given width of a CID, a JPEG image. It returns ErrNotFound if its
+// thumbnails have not been generated.
+func (c *Cache) Open(cid string, width int) (*os.File, error) {
+	dir, err := c.cidDir(cid)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(dir, thumbnailName(width)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, cid)
+	}
+	return f, err
+}
+
+// Remove removes the cached thumbnails of a CID. Removing the thumbnails of a CID that has none is not an error.
+func (c *Cache) Remove(cid string) error {
+	dir, err := c.cidDir(cid)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// cidDir returns the directory of the thumbnails of a CID, refusing CIDs that would escape the cache.
+func (c *Cache) cidDir(cid string) (string, error) {
+	if !filepath.IsLocal(cid) || filepath.Base(cid) != cid {
+		return "", fmt.Errorf("invalid cid %q", cid)
+	}
+	return filepath.Join(c.dir, cid), nil
+}
+
+// thumbnailName returns the name of the file of a thumbnail of the given width.
+func thumbnailName(width int) string {
+	return strconv.Itoa(width) + ".jpg"
+}
+
+// scale returns img scaled down to the given width, keeping its aspect ratio, on a white background for the
+// images with transparency.
+func scale(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() < width {
+		width = bounds.Dx()
+	}
+	height := max(bounds.Dy()*width/bounds.Dx(), 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// writeThumbnail encodes a thumbnail as a JPEG image at path, replacing it atomically so that concurrent
+// readers never see a partial thumbnail.
+func writeThumbnail(path string, img image.Image) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".thumb-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := jpeg.Encode(tmp, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
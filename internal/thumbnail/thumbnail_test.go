@@ -0,0 +1,138 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// webp1x1 is a lossless 1x1 WebP image.
+const webp1x1 = "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA=="
+
+func newTestCache(t *testing.T) *Cache {
+	c, err := NewCache(t.TempDir())
+	require.NoError(t, err)
+	return c
+}
+
+// newImage returns a width x height image filled with c.
+func newImage(width, height int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// thumbnailSize decodes the cached thumbnail of a CID at width and returns its size.
+func thumbnailSize(t *testing.T, c *Cache, cid string, width int) image.Point {
+	f, err := c.Open(cid, width)
+	require.NoError(t, err)
+	defer f.Close()
+
+	img, format, err := image.Decode(f)
+	require.NoError(t, err)
+	require.Equal(t, "jpeg", format)
+	return img.Bounds().Size()
+}
+
+func TestGenerate(t *testing.T) {
+	c := newTestCache(t)
+
+	var pngData, jpegData, gifData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, newImage(1000, 500, color.NRGBA{R: 255, A: 128})))
+	require.NoError(t, jpeg.Encode(&jpegData, newImage(100, 400, color.Black), nil))
+	require.NoError(t, gif.Encode(&gifData, newImage(300, 300, color.White), nil))
+	webpData, err := base64.StdEncoding.DecodeString(webp1x1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		cid      string
+		data     []byte
+		expected []image.Point // the sizes of the thumbnails, by width
+	}{
+		{name: "PNG", cid: "bafy1", data: pngData.Bytes(), expected: []image.Point{{64, 32}, {128, 64}, {256, 128}}},
+		{name: "Narrow JPEG is not scaled up", cid: "bafy2", data: jpegData.Bytes(), expected: []image.Point{{64, 256}, {100, 400}, {100, 400}}},
+		{name: "GIF", cid: "bafy3", data: gifData.Bytes(), expected: []image.Point{{64, 64}, {128, 128}, {256, 256}}},
+		{name: "WebP", cid: "bafy4", data: webpData, expected: []image.Point{{1, 1}, {1, 1}, {1, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, c.Generate(tt.cid, bytes.NewReader(tt.data)))
+			for n, width := range Widths {
+				require.Equal(t, tt.expected[n], thumbnailSize(t, c, tt.cid, width))
+			}
+		})
+	}
+
+	// transparency is flattened on white
+	f, err := c.Open("bafy1", 64)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	require.NoError(t, err)
+	r, g, b, _ := img.At(32, 16).RGBA()
+	require.InDelta(t, 0xffff, r, 0x800)
+	require.InDelta(t, 0x8080, g, 0x800)
+	require.InDelta(t, 0x8080, b, 0x800)
+
+	require.NoError(t, c.Remove("bafy1"))
+	require.NoError(t, c.Remove("bafy1"))
+	_, err = c.Open("bafy1", 64)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Error(t, c.Remove(".."))
+	require.Error(t, c.Remove(""))
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	c := newTestCache(t)
+
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, newImage(10, 10, color.White)))
+	truncated := pngData.Bytes()[:pngData.Len()/2]
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Text", data: []byte("not an image")},
+		{name: "SVG", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)},
+		{name: "Truncated PNG", data: truncated},
+		{name: "Too many pixels", data: pngHeader(100000, 100000)},
+		{name: "Too large", data: append([]byte("\x89PNG\r\n\x1a\n"), strings.Repeat("x", maxImageSize)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, c.Generate("bafy1", bytes.NewReader(tt.data)), ErrUnsupported)
+			_, err := c.Open("bafy1", DefaultWidth)
+			require.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+// pngHeader returns the start of a PNG image of the given size, enough to decode its configuration.
+func pngHeader(width, height int) []byte {
+	var data bytes.Buffer
+	png.Encode(&data, newImage(1, 1, color.White))
+	b := data.Bytes()
+	// the IHDR chunk starts after the 8 byte signature and the chunk length and type
+	b[16], b[17], b[18], b[19] = byte(width>>24), byte(width>>16), byte(width>>8), byte(width)
+	b[20], b[21], b[22], b[23] = byte(height>>24), byte(height>>16), byte(height>>8), byte(height)
+	binary.BigEndian.PutUint32(b[29:33], crc32.ChecksumIEEE(b[12:29]))
+	return b
+}
@@ -0,0 +1,235 @@
+package versions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/store"
+)
+
+const (
+	bucket        = "versions"         // the store bucket holding the version history of every name.
+	currentBucket = "current_versions" // the store bucket holding the rolled back current version of names.
+)
+
+// ErrNotFound is returned when a name has no versions, or no version with a given CID.
+var ErrNotFound = errors.New("version not found")
+
+// Version is a version of a named file.
+type Version struct {
+	Cid       string    `json:"cid"`                // the CID of the version.
+	Uploader  string    `json:"uploader,omitempty"` // the Hive user who uploaded the version, if known.
+	CreatedAt time.Time `json:"created_at"`         // when the version was recorded.
+}
+
+// Entry is an entry of a directory version compared by Diff.
+type Entry struct {
+	Name   string `json:"name"`              // the name of the entry in the directory.
+	OldCid string `json:"old_cid,omitempty"` // the CID of the entry in the old version.
+	NewCid string `json:"new_cid,omitempty"` // the CID of the entry in the new version.
+	Size   uint64 `json:"size"`              // the size of the entry, in the new version if it has one.
+}
+
+// Diff is the difference between two versions of a directory.
+type Diff struct {
+	Added     []Entry `json:"added"`     // the entries only in the new version.
+	Removed   []Entry `json:"removed"`   // the entries only in the old version.
+	Modified  []Entry `json:"modified"`  // the entries whose CID changed.
+	Unchanged int     `json:"unchanged"` // the number of entries in both versions with the same CID.
+}
+
+// History records the successive CIDs of a logical file name, whether uploaded again or moved by a pin update,
+// and which of them is current. The latest version is current until a rollback points at an older one.
+type History struct {
+	store *store.Store
+}
+
+// NewHistory creates a new History stored in store.
+func NewHistory(store *store.Store) *History {
+	return &History{
+		store: store,
+	}
+}
+
+// List returns the versions of name, oldest first.
+func (h *History) List(name string) ([]Version, error) {
+	versions := []Version{}
+	err := h.store.Get(bucket, name, &versions)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Current returns the current version of name.
+func (h *History) Current(name string) (Version, error) {
+	var current Version
+	err := h.store.View(func(tx *store.Tx) error {
+		var err error
+		current, err = currentVersion(tx, name)
+		return err
+	})
+	return current, err
+}
+
+// Record records cid, uploaded by uploader, as the latest and current version of name. Uploading the content
+// of the latest version again records nothing, but still makes it current.
+func (h *History) Record(name, cid, uploader string) (Version, error) {
+	var version Version
+	err := h.store.Update(func(tx *store.Tx) error {
+		versions, err := listVersions(tx, name)
+		if err != nil {
+			return err
+		}
+
+		if len(versions) > 0 && versions[len(versions)-1].Cid == cid {
+			version = versions[len(versions)-1]
+		} else {
+			version = Version{Cid: cid, Uploader: uploader, CreatedAt: time.Now().UTC()}
+			if err := tx.Put(bucket, name, append(versions, version)); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(currentBucket, name)
+	})
+	if err != nil {
+		return Version{}, err
+	}
+	return version, nil
+}
+
+// RecordUpdate records that the pin called name moved from fromCid to toCid and returns the versions of name.
+// fromCid is recorded first if it is not the latest version, as happens for pins made before their name had a
+// history. toCid becomes the current version, without being recorded again if it already is the latest.
+func (h *History) RecordUpdate(name, fromCid, toCid string) ([]Version, error) {
+	var versions []Version
+	err := h.store.Update(func(tx *store.Tx) error {
+		var err error
+		if versions, err = listVersions(tx, name); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if len(versions) == 0 || versions[len(versions)-1].Cid != fromCid {
+			versions = append(versions, Version{Cid: fromCid, CreatedAt: now})
+		}
+		if versions[len(versions)-1].Cid != toCid {
+			versions = append(versions, Version{Cid: toCid, CreatedAt: now})
+		}
+		if err := tx.Put(bucket, name, versions); err != nil {
+			return err
+		}
+		return tx.Delete(currentBucket, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Rollback makes the version of name with the given CID current again. The versions recorded after it are
+// kept, so that a later rollback can return to them.
+func (h *History) Rollback(name, cid string) (Version, error) {
+	var version Version
+	err := h.store.Update(func(tx *store.Tx) error {
+		var err error
+		if version, err = findVersion(tx, name, cid); err != nil {
+			return err
+		}
+		return tx.Put(currentBucket, name, cid)
+	})
+	if err != nil {
+		return Version{}, err
+	}
+	return version, nil
+}
+
+// Find returns the version of name with the given CID.
+func (h *History) Find(name, cid string) (Version, error) {
+	var version Version
+	err := h.store.View(func(tx *store.Tx) error {
+		var err error
+		version, err = findVersion(tx, name, cid)
+		return err
+	})
+	return version, err
+}
+
+// listVersions returns the versions of name, oldest first.
+func listVersions(tx *store.Tx, name string) ([]Version, error) {
+	var versions []Version
+	err := tx.Get(bucket, name, &versions)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// findVersion returns the latest version of name with the given CID.
+func findVersion(tx *store.Tx, name, cid string) (Version, error) {
+	versions, err := listVersions(tx, name)
+	if err != nil {
+		return Version{}, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Cid == cid {
+			return versions[i], nil
+		}
+	}
+	return Version{}, fmt.Errorf("%w: %s has no version %s", ErrNotFound, name, cid)
+}
+
+// currentVersion returns the version of name a rollback points at, or its latest version.
+func currentVersion(tx *store.Tx, name string) (Version, error) {
+	var cid string
+	err := tx.Get(currentBucket, name, &cid)
+	if err == nil {
+		return findVersion(tx, name, cid)
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return Version{}, err
+	}
+
+	versions, err := listVersions(tx, name)
+	if err != nil {
+		return Version{}, err
+	}
+	if len(versions) == 0 {
+		return Version{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return versions[len(versions)-1], nil
+}
+
+// DiffDirs compares the listings of two versions of a directory by entry name. The entries of every group are
+// sorted by name.
+func DiffDirs(from, to []ipfs.DirFileDetail) Diff {
+	diff := Diff{Added: []Entry{}, Removed: []Entry{}, Modified: []Entry{}}
+
+	old := make(map[string]ipfs.DirFileDetail, len(from))
+	for _, entry := range from {
+		old[entry.Name] = entry
+	}
+	for _, entry := range to {
+		prev, ok := old[entry.Name]
+		delete(old, entry.Name)
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, Entry{Name: entry.Name, NewCid: entry.Cid.String(), Size: entry.Size})
+		case prev.Cid.Equals(entry.Cid):
+			diff.Unchanged++
+		default:
+			diff.Modified = append(diff.Modified, Entry{Name: entry.Name, OldCid: prev.Cid.String(), NewCid: entry.Cid.String(), Size: entry.Size})
+		}
+	}
+	for _, entry := range old {
+		diff.Removed = append(diff.Removed, Entry{Name: entry.Name, OldCid: entry.Cid.String(), Size: entry.Size})
+	}
+
+	for _, entries := range [][]Entry{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	}
+	return diff
+}
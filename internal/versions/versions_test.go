@@ -0,0 +1,130 @@
+package versions
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Hive/internal/ipfs"
+	"github.com/zde37/Hive/internal/store"
+)
+
+func newTestHistory(t *testing.T) *History {
+	st, err := store.Open(filepath.Join(t.TempDir(), "hive.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return NewHistory(st)
+}
+
+// cids returns the CIDs of the versions.
+func cids(versions []Version) []string {
+	cids := []string{}
+	for _, version := range versions {
+		cids = append(cids, version.Cid)
+	}
+	return cids
+}
+
+func TestRecordUpdate(t *testing.T) {
+	h := newTestHistory(t)
+
+	versions, err := h.List("dataset")
+	require.NoError(t, err)
+	require.Empty(t, versions)
+
+	versions, err = h.RecordUpdate("dataset", "bafy1", "bafy2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy1", "bafy2"}, cids(versions))
+
+	versions, err = h.RecordUpdate("dataset", "bafy2", "bafy3")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy1", "bafy2", "bafy3"}, cids(versions))
+	require.False(t, versions[2].CreatedAt.Before(versions[1].CreatedAt))
+
+	// an update from a CID that is not the latest version records it first
+	versions, err = h.RecordUpdate("dataset", "bafy9", "bafy10")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy1", "bafy2", "bafy3", "bafy9", "bafy10"}, cids(versions))
+
+	// updating to the latest version records nothing
+	versions, err = h.RecordUpdate("dataset", "bafy10", "bafy10")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy1", "bafy2", "bafy3", "bafy9", "bafy10"}, cids(versions))
+
+	versions, err = h.List("dataset")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy1", "bafy2", "bafy3", "bafy9", "bafy10"}, cids(versions))
+
+	versions, err = h.List("other")
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
+
+func TestRecordAndRollback(t *testing.T) {
+	h := newTestHistory(t)
+
+	_, err := h.Current("report.pdf")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	v1, err := h.Record("report.pdf", "bafy1", "alice")
+	require.NoError(t, err)
+	require.Equal(t, "alice", v1.Uploader)
+	_, err = h.Record("report.pdf", "bafy2", "bob")
+	require.NoError(t, err)
+
+	// uploading the latest version again records nothing
+	_, err = h.Record("report.pdf", "bafy2", "alice")
+	require.NoError(t, err)
+	versions, err := h.List("report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bafy1", "bafy2"}, cids(versions))
+	require.Equal(t, "bob", versions[1].Uploader)
+
+	current, err := h.Current("report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, "bafy2", current.Cid)
+
+	_, err = h.Rollback("report.pdf", "bafy9")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	current, err = h.Rollback("report.pdf", "bafy1")
+	require.NoError(t, err)
+	require.Equal(t, v1, current)
+	current, err = h.Current("report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, "bafy1", current.Cid)
+
+	// a new version is current again
+	_, err = h.Record("report.pdf", "bafy3", "")
+	require.NoError(t, err)
+	current, err = h.Current("report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, "bafy3", current.Cid)
+	require.Empty(t, current.Uploader)
+}
+
+func TestDiffDirs(t *testing.T) {
+	a := cid.MustParse("bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy")
+	b := cid.MustParse("bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi")
+
+	from := []ipfs.DirFileDetail{
+		{Name: "readme.md", Cid: a, Size: 10},
+		{Name: "old.txt", Cid: a, Size: 10},
+		{Name: "data", Cid: a, Size: 10},
+	}
+	to := []ipfs.DirFileDetail{
+		{Name: "readme.md", Cid: a, Size: 10},
+		{Name: "data", Cid: b, Size: 20},
+		{Name: "new.txt", Cid: b, Size: 20},
+	}
+
+	require.Equal(t, Diff{
+		Added:     []Entry{{Name: "new.txt", NewCid: b.String(), Size: 20}},
+		Removed:   []Entry{{Name: "old.txt", OldCid: a.String(), Size: 10}},
+		Modified:  []Entry{{Name: "data", OldCid: a.String(), NewCid: b.String(), Size: 20}},
+		Unchanged: 1,
+	}, DiffDirs(from, to))
+
+	require.Equal(t, Diff{Added: []Entry{}, Removed: []Entry{}, Modified: []Entry{}}, DiffDirs(nil, nil))
+}